@@ -19,9 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Chat_NewChat_FullMethodName     = "/chatpb.Chat/NewChat"
-	Chat_NewMessage_FullMethodName  = "/chatpb.Chat/NewMessage"
-	Chat_ChatHistory_FullMethodName = "/chatpb.Chat/ChatHistory"
+	Chat_NewChat_FullMethodName       = "/chatpb.Chat/NewChat"
+	Chat_NewMessage_FullMethodName    = "/chatpb.Chat/NewMessage"
+	Chat_ChatHistory_FullMethodName   = "/chatpb.Chat/ChatHistory"
+	Chat_Subscribe_FullMethodName     = "/chatpb.Chat/Subscribe"
+	Chat_InviteMember_FullMethodName  = "/chatpb.Chat/InviteMember"
+	Chat_RemoveMember_FullMethodName  = "/chatpb.Chat/RemoveMember"
+	Chat_ListMembers_FullMethodName   = "/chatpb.Chat/ListMembers"
+	Chat_EditMessage_FullMethodName   = "/chatpb.Chat/EditMessage"
+	Chat_DeleteMessage_FullMethodName = "/chatpb.Chat/DeleteMessage"
 )
 
 // ChatClient is the client API for Chat service.
@@ -31,6 +37,12 @@ type ChatClient interface {
 	NewChat(ctx context.Context, in *NewChatReq, opts ...grpc.CallOption) (*NewChatResp, error)
 	NewMessage(ctx context.Context, in *NewMessageReq, opts ...grpc.CallOption) (*NewMessageResp, error)
 	ChatHistory(ctx context.Context, in *ChatHistoryReq, opts ...grpc.CallOption) (*ChatHistoryResp, error)
+	Subscribe(ctx context.Context, in *SubscribeReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error)
+	InviteMember(ctx context.Context, in *InviteMemberReq, opts ...grpc.CallOption) (*InviteMemberResp, error)
+	RemoveMember(ctx context.Context, in *RemoveMemberReq, opts ...grpc.CallOption) (*RemoveMemberResp, error)
+	ListMembers(ctx context.Context, in *ListMembersReq, opts ...grpc.CallOption) (*ListMembersResp, error)
+	EditMessage(ctx context.Context, in *EditMessageReq, opts ...grpc.CallOption) (*EditMessageResp, error)
+	DeleteMessage(ctx context.Context, in *DeleteMessageReq, opts ...grpc.CallOption) (*DeleteMessageResp, error)
 }
 
 type chatClient struct {
@@ -71,6 +83,75 @@ func (c *chatClient) ChatHistory(ctx context.Context, in *ChatHistoryReq, opts .
 	return out, nil
 }
 
+func (c *chatClient) Subscribe(ctx context.Context, in *SubscribeReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Message], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Chat_ServiceDesc.Streams[0], Chat_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeReq, Message]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Chat_SubscribeClient = grpc.ServerStreamingClient[Message]
+
+func (c *chatClient) InviteMember(ctx context.Context, in *InviteMemberReq, opts ...grpc.CallOption) (*InviteMemberResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InviteMemberResp)
+	err := c.cc.Invoke(ctx, Chat_InviteMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) RemoveMember(ctx context.Context, in *RemoveMemberReq, opts ...grpc.CallOption) (*RemoveMemberResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMemberResp)
+	err := c.cc.Invoke(ctx, Chat_RemoveMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) ListMembers(ctx context.Context, in *ListMembersReq, opts ...grpc.CallOption) (*ListMembersResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMembersResp)
+	err := c.cc.Invoke(ctx, Chat_ListMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) EditMessage(ctx context.Context, in *EditMessageReq, opts ...grpc.CallOption) (*EditMessageResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditMessageResp)
+	err := c.cc.Invoke(ctx, Chat_EditMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) DeleteMessage(ctx context.Context, in *DeleteMessageReq, opts ...grpc.CallOption) (*DeleteMessageResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMessageResp)
+	err := c.cc.Invoke(ctx, Chat_DeleteMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ChatServer is the server API for Chat service.
 // All implementations must embed UnimplementedChatServer
 // for forward compatibility.
@@ -78,6 +159,12 @@ type ChatServer interface {
 	NewChat(context.Context, *NewChatReq) (*NewChatResp, error)
 	NewMessage(context.Context, *NewMessageReq) (*NewMessageResp, error)
 	ChatHistory(context.Context, *ChatHistoryReq) (*ChatHistoryResp, error)
+	Subscribe(*SubscribeReq, grpc.ServerStreamingServer[Message]) error
+	InviteMember(context.Context, *InviteMemberReq) (*InviteMemberResp, error)
+	RemoveMember(context.Context, *RemoveMemberReq) (*RemoveMemberResp, error)
+	ListMembers(context.Context, *ListMembersReq) (*ListMembersResp, error)
+	EditMessage(context.Context, *EditMessageReq) (*EditMessageResp, error)
+	DeleteMessage(context.Context, *DeleteMessageReq) (*DeleteMessageResp, error)
 	mustEmbedUnimplementedChatServer()
 }
 
@@ -97,6 +184,24 @@ func (UnimplementedChatServer) NewMessage(context.Context, *NewMessageReq) (*New
 func (UnimplementedChatServer) ChatHistory(context.Context, *ChatHistoryReq) (*ChatHistoryResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ChatHistory not implemented")
 }
+func (UnimplementedChatServer) Subscribe(*SubscribeReq, grpc.ServerStreamingServer[Message]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedChatServer) InviteMember(context.Context, *InviteMemberReq) (*InviteMemberResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InviteMember not implemented")
+}
+func (UnimplementedChatServer) RemoveMember(context.Context, *RemoveMemberReq) (*RemoveMemberResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedChatServer) ListMembers(context.Context, *ListMembersReq) (*ListMembersResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedChatServer) EditMessage(context.Context, *EditMessageReq) (*EditMessageResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMessage not implemented")
+}
+func (UnimplementedChatServer) DeleteMessage(context.Context, *DeleteMessageReq) (*DeleteMessageResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMessage not implemented")
+}
 func (UnimplementedChatServer) mustEmbedUnimplementedChatServer() {}
 func (UnimplementedChatServer) testEmbeddedByValue()              {}
 
@@ -172,6 +277,107 @@ func _Chat_ChatHistory_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Chat_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServer).Subscribe(m, &grpc.GenericServerStream[SubscribeReq, Message]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Chat_SubscribeServer = grpc.ServerStreamingServer[Message]
+
+func _Chat_InviteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InviteMemberReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).InviteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_InviteMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).InviteMember(ctx, req.(*InviteMemberReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_RemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).RemoveMember(ctx, req.(*RemoveMemberReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).ListMembers(ctx, req.(*ListMembersReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMessageReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_EditMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).EditMessage(ctx, req.(*EditMessageReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_DeleteMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMessageReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).DeleteMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Chat_DeleteMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).DeleteMessage(ctx, req.(*DeleteMessageReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Chat_ServiceDesc is the grpc.ServiceDesc for Chat service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,7 +397,33 @@ var Chat_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ChatHistory",
 			Handler:    _Chat_ChatHistory_Handler,
 		},
+		{
+			MethodName: "InviteMember",
+			Handler:    _Chat_InviteMember_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _Chat_RemoveMember_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _Chat_ListMembers_Handler,
+		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _Chat_EditMessage_Handler,
+		},
+		{
+			MethodName: "DeleteMessage",
+			Handler:    _Chat_DeleteMessage_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Chat_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "chat_service.proto",
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alexandernizov/grpcmessanger/internal/config"
+	"github.com/alexandernizov/grpcmessanger/internal/consumer"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
+)
+
+const (
+	envLocal = "local"
+	envProd  = "prod"
+)
+
+// The consumer writes the audit log of chats and messages from the outbox topics
+func main() {
+	//Config
+	cfg := config.MustLoadConsumer()
+
+	//Logger
+	log := setupLogger(cfg.Env)
+	log.Info("starting consumer", slog.String("env", cfg.Env), slog.String("group", cfg.Consumer.Group))
+
+	//Tracing, the traces of the messanger are continued from the message headers
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: "consumer",
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		File:        cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Error("can't setup tracing", sl.Err(err))
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("error with flushing traces", sl.Err(err))
+		}
+	}()
+
+	//Audit log
+	var out io.Writer = os.Stdout
+	if cfg.AuditLog != "" {
+		file, err := os.OpenFile(cfg.AuditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			log.Error("can't open audit log", sl.Err(err))
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	//Consumer
+	c, err := consumer.New(log,
+		[]string{cfg.Kafka.Host + ":" + cfg.Kafka.Port},
+		consumer.NewAuditLog(out),
+		consumer.NewMemoryProcessed(cfg.Consumer.ProcessedSize),
+		consumer.Options{
+			Group:    cfg.Consumer.Group,
+			Topics:   cfg.Consumer.Topics,
+			MinRetry: cfg.Consumer.MinRetry,
+			MaxRetry: cfg.Consumer.MaxRetry,
+		},
+	)
+	if err != nil {
+		log.Error("can't start consumer", sl.Err(err))
+		os.Exit(1)
+	}
+
+	//Stop consumer
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	err = c.Run(ctx)
+	if closeErr := c.Close(); closeErr != nil {
+		log.Error("error with closing consumer", sl.Err(closeErr))
+	}
+	if err != nil {
+		log.Error("consumer failed", sl.Err(err))
+		os.Exit(1)
+	}
+	log.Info("consumer stopped")
+}
+
+func setupLogger(env string) *slog.Logger {
+	var log *slog.Logger
+
+	switch env {
+	case envLocal:
+		log = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	case envProd:
+		log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	default:
+		panic("unknown enviroment")
+	}
+
+	return log
+}
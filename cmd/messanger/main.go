@@ -1,21 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/alexandernizov/grpcmessanger/internal/config"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
 	"github.com/alexandernizov/grpcmessanger/internal/grpc"
 	"github.com/alexandernizov/grpcmessanger/internal/http"
+	"github.com/alexandernizov/grpcmessanger/internal/leader"
 	"github.com/alexandernizov/grpcmessanger/internal/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
+	"github.com/alexandernizov/grpcmessanger/internal/services/admin"
 	"github.com/alexandernizov/grpcmessanger/internal/services/auth"
 	"github.com/alexandernizov/grpcmessanger/internal/services/chat"
 	"github.com/alexandernizov/grpcmessanger/internal/storage/inmemory"
+	"github.com/alexandernizov/grpcmessanger/internal/storage/instrumented"
 	"github.com/alexandernizov/grpcmessanger/internal/storage/postgres"
 	"github.com/alexandernizov/grpcmessanger/internal/storage/redis"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -29,37 +40,73 @@ func main() {
 
 	//Logger
 	log := setupLogger(cfg.Env)
+
+	//Migrations only
+	if cfg.Migrate != "" {
+		if err := migrate(log, cfg); err != nil {
+			log.Error("migration failed", slog.String("command", cfg.Migrate), sl.Err(err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Info("starting application", slog.String("env", cfg.Env))
 
+	//Tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions("messanger", cfg.Tracing))
+	if err != nil {
+		panic("can't setup tracing: " + err.Error())
+	}
+
 	//Storages
 	var authStorage auth.AuthStorage
 	var chatStorage chat.ChatStorage
 	var notifyStorage outbox.OutboxProvider
+	var backlogStorage outbox.BacklogProvider
+	var deadLetterStorage admin.DeadLetterStorage
+	var quotaStorage admin.QuotaStorage
+	// backend labels the storage metrics
+	var backend string
+	// The lock replicas compete for to run the outbox publisher and the reapers
+	leaderId := leader.NewId()
+	var leaderLock leader.Lock
 
 	//InmemoryStorage
 	if cfg.Storage.Inmemory > 0 {
 		storage := inmemory.New(log)
+		backend = "inmemory"
 		authStorage = storage
 		chatStorage = storage
 		notifyStorage = storage
+		backlogStorage = storage
+		deadLetterStorage = storage
+		quotaStorage = storage
+		leaderLock = leader.Local{}
 	}
 
 	//PostgresStorage
 	if cfg.Storage.Postgres > 0 {
-		pgOpt := postgres.ConnectOptions{
-			Host:     cfg.Postgres.Host,
-			Port:     cfg.Postgres.Port,
-			User:     cfg.Postgres.User,
-			Password: cfg.Postgres.Password,
-			DBname:   cfg.Postgres.DBname,
-		}
-		pgDB, err := postgres.NewWithOptions(log, pgOpt)
+		pgDB, err := postgres.NewWithOptions(log, postgresOptions(cfg))
 		if err != nil {
 			panic("can't connect to postgres")
 		}
+		if cfg.Postgres.MigrateOnStart {
+			migrator, err := pgDB.Migrator()
+			if err == nil {
+				_, err = migrator.Up(context.Background())
+			}
+			if err != nil {
+				panic("can't migrate postgres: " + err.Error())
+			}
+		}
+		backend = "postgres"
 		authStorage = pgDB
 		chatStorage = pgDB
 		notifyStorage = pgDB
+		backlogStorage = pgDB
+		deadLetterStorage = pgDB
+		quotaStorage = pgDB
+		leaderLock = pgDB.LeaderLock()
 	}
 
 	//RedisStorage
@@ -73,41 +120,83 @@ func main() {
 		if err != nil {
 			panic("can't connect to redis")
 		}
+		backend = "redis"
 		authStorage = redisDB
 		chatStorage = redisDB
 		notifyStorage = redisDB
+		backlogStorage = redisDB
+		deadLetterStorage = redisDB
+		quotaStorage = redisDB
+		leaderLock = redisDB.LeaderLock(leaderId, cfg.Leader.Lease)
 	}
 
+	//Storage metrics, the optional storages are found before the storages are wrapped
+	expiredChats, _ := chatStorage.(chat.ExpiredChatsStorage)
+	if expiredChats != nil {
+		expiredChats = instrumented.NewExpiredChats(backend, expiredChats)
+	}
+	sentOutbox, _ := notifyStorage.(outbox.SentOutboxStorage)
+	if sentOutbox != nil {
+		sentOutbox = instrumented.NewSentOutbox(backend, sentOutbox)
+	}
+	authStorage = instrumented.NewAuth(backend, authStorage)
+	chatStorage = instrumented.NewChat(backend, chatStorage)
+	notifyStorage = instrumented.NewOutbox(backend, notifyStorage)
+	backlogStorage = instrumented.NewBacklog(backend, backlogStorage)
+	deadLetterStorage = instrumented.NewDeadLetters(backend, deadLetterStorage)
+	quotaStorage = instrumented.NewQuotas(backend, quotaStorage)
+
 	//Auth Service
 	jwt := auth.JwtParams{AccessTtl: cfg.User.JwtAccessTTL, RefreshTtl: cfg.User.JwtRefreshTTL, Secret: []byte(cfg.User.JwtSecret)}
 	authService := auth.New(log, authStorage, jwt)
 
 	//Chat Service
 	chatOpt := chat.ChatOptions{
-		DefaultTtl:      cfg.Chat.ChatTTL,
-		MaximumCount:    cfg.Chat.MaxChatsCount,
-		MaximumMessages: cfg.Chat.MaxMessagesPerChat,
+		DefaultTtl:       cfg.Chat.ChatTTL,
+		MaximumMessages:  cfg.Chat.MaxMessagesPerChat,
+		SubscriberBuffer: cfg.Chat.SubscriberBuffer,
+		Quota: domain.Quota{
+			ChatsOwned:        cfg.Chat.Quota.ChatsOwned,
+			MessagesPerMinute: cfg.Chat.Quota.MessagesPerMinute,
+			MessageBytes:      cfg.Chat.Quota.MessageBytes,
+		},
 	}
 	chatService := chat.New(log, chatOpt, chatStorage)
 
-	//Notifier Service
-	brokers := []string{cfg.Kafka.Host + ":" + cfg.Kafka.Port}
-	publisher, err := outbox.New(log, notifyStorage, brokers)
-	if err != nil {
-		fmt.Println("can't start publisher")
-		os.Exit(1)
+	//Admin Service
+	admins := make([]uuid.UUID, 0, len(cfg.Admin.Users))
+	for _, v := range cfg.Admin.Users {
+		adminUuid, err := uuid.Parse(v)
+		if err != nil {
+			panic("admin user uuid is incorrect: " + v)
+		}
+		admins = append(admins, adminUuid)
 	}
-	publisher.Start()
+	adminService := admin.New(log, deadLetterStorage, quotaStorage, admins)
+
+	//Leader election, only the leader publishes the outbox and deletes expired data
+	leaderOpt := leader.Options{
+		Id:            leaderId,
+		RenewInterval: cfg.Leader.RenewInterval,
+		RetryInterval: cfg.Leader.RetryInterval,
+	}
+	elector := leader.New(log, leaderLock, leaderOpt)
+	elector.Start(func(ctx context.Context) {
+		lead(ctx, log, cfg, notifyStorage, expiredChats, sentOutbox)
+	})
+	prometheus.MustRegister(outbox.NewBacklogCollector(log, backlogStorage))
 
 	//Start Grpc Server
 	server := grpc.NewServer(log)
 	gOpt := grpc.ServerOptions{
 		Address:        cfg.Grpc.Address + ":" + cfg.Grpc.Port,
 		RequestTimeout: cfg.Grpc.RequestTimeout,
+		MethodTimeouts: cfg.Grpc.MethodTimeouts,
 		JwtSecret:      []byte(cfg.User.JwtSecret),
 
-		AuthProvider: authService,
-		ChatProvider: chatService,
+		AuthProvider:  authService,
+		ChatProvider:  chatService,
+		AdminProvider: adminService,
 	}
 	server.Start(gOpt)
 
@@ -128,10 +217,160 @@ func main() {
 	log.Info("stopping application")
 	httpServer.Stop()
 	server.Stop()
-	publisher.Stop()
+	elector.Stop()
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Error("error with flushing traces", sl.Err(err))
+	}
 	log.Info("application stopped")
 }
 
+func tracingOptions(serviceName string, cfg config.TracingConfig) tracing.Options {
+	return tracing.Options{
+		Exporter:    cfg.Exporter,
+		ServiceName: serviceName,
+		Endpoint:    cfg.Endpoint,
+		Insecure:    cfg.Insecure,
+		File:        cfg.File,
+		SampleRatio: cfg.SampleRatio,
+	}
+}
+
+func postgresOptions(cfg *config.Config) postgres.ConnectOptions {
+	return postgres.ConnectOptions{
+		Host:     cfg.Postgres.Host,
+		Port:     cfg.Postgres.Port,
+		User:     cfg.Postgres.User,
+		Password: cfg.Postgres.Password,
+		DBname:   cfg.Postgres.DBname,
+	}
+}
+
+// lead runs the work of the leader replica until the leadership is lost.
+// expiredChats and sentOutbox are nil if the storage doesn't have them.
+func lead(ctx context.Context, log *slog.Logger, cfg *config.Config, outboxStorage outbox.OutboxProvider, expiredChats chat.ExpiredChatsStorage, sentOutbox outbox.SentOutboxStorage) {
+	//Expired chats reaper, redis expires chats by itself
+	if expiredChats != nil {
+		reaper := chat.NewReaper(log, expiredChats, cfg.Chat.ReaperInterval)
+		reaper.Start()
+		defer reaper.Stop()
+	}
+
+	//Sent outboxes retention, the other storages delete outboxes once they are sent
+	if sentOutbox != nil {
+		retentionOpt := outbox.RetentionOptions{
+			MaxAge:    cfg.Outbox.Retention.MaxAge,
+			Interval:  cfg.Outbox.Retention.Interval,
+			BatchSize: cfg.Outbox.Retention.BatchSize,
+		}
+		retention := outbox.NewRetention(log, sentOutbox, retentionOpt)
+		retention.Start()
+		defer retention.Stop()
+	}
+
+	//Notifier Service, the leadership is kept while the sink is unavailable
+	sink := connectSink(ctx, log, cfg)
+	if sink == nil {
+		return
+	}
+	outboxOpt := outbox.Options{
+		BatchSize:   cfg.Outbox.BatchSize,
+		MinInterval: cfg.Outbox.MinInterval,
+		MaxInterval: cfg.Outbox.MaxInterval,
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		ClaimLease:  cfg.Outbox.ClaimLease,
+		Workers:     cfg.Outbox.Workers,
+	}
+	publisher := outbox.New(log, outboxStorage, sink, outboxOpt)
+	publisher.Start()
+	defer publisher.Stop()
+
+	<-ctx.Done()
+}
+
+const (
+	minSinkRetry = time.Second
+	maxSinkRetry = time.Minute
+)
+
+// connectSink makes the sink, retrying with a doubling delay until it succeeds.
+// It returns nil when ctx is done first.
+func connectSink(ctx context.Context, log *slog.Logger, cfg *config.Config) outbox.Sink {
+	retry := minSinkRetry
+	for {
+		sink, err := newSink(log, cfg)
+		if err == nil {
+			return sink
+		}
+		log.Error("can't start publisher, retrying", sl.Err(err), slog.Duration("retry", retry))
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		retry = min(retry*2, maxSinkRetry)
+	}
+}
+
+// newSink makes the sink the outbox is published to
+func newSink(log *slog.Logger, cfg *config.Config) (outbox.Sink, error) {
+	switch cfg.Outbox.Sink {
+	case "", "kafka":
+		return outbox.NewKafkaSink([]string{cfg.Kafka.Host + ":" + cfg.Kafka.Port})
+	case "webhook":
+		if cfg.Outbox.Webhook.Url == "" {
+			return nil, errors.New("webhook sink requires an url")
+		}
+		return outbox.NewWebhookSink(cfg.Outbox.Webhook.Url, cfg.Outbox.Webhook.Timeout), nil
+	case "file":
+		return outbox.NewFileSink(cfg.Outbox.File.Path)
+	case "noop":
+		return outbox.NewNoopSink(log), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q, use kafka, webhook, file or noop", cfg.Outbox.Sink)
+	}
+}
+
+// migrate runs the -migrate command against postgres
+func migrate(log *slog.Logger, cfg *config.Config) error {
+	pgDB, err := postgres.NewWithOptions(log, postgresOptions(cfg))
+	if err != nil {
+		return err
+	}
+	defer pgDB.Close()
+
+	migrator, err := pgDB.Migrator()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch cfg.Migrate {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("migrations are applied", slog.Int("applied", applied))
+	case "down":
+		if err := migrator.Down(ctx); err != nil {
+			return err
+		}
+		log.Info("last migration is rolled back")
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("migration status", slog.Int64("version", status.Version), slog.Bool("dirty", status.Dirty), slog.Any("pending", status.Pending))
+	default:
+		return fmt.Errorf("unknown migrate command %q, use up, down or status", cfg.Migrate)
+	}
+	return nil
+}
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
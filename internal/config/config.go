@@ -16,15 +16,26 @@ type Config struct {
 	User  UserConfig  `yaml:"user"`
 	Kafka KafkaConfig `yaml:"kafka"`
 
+	Outbox OutboxConfig `yaml:"outbox"`
+	Admin  AdminConfig  `yaml:"admin"`
+	Leader LeaderConfig `yaml:"leader"`
+
+	Tracing TracingConfig `yaml:"tracing"`
+
 	Storage  StorageConfig  `yaml:"storage"`
 	Postgres PostgresConfig `yaml:"postgres"`
 	Redis    RedisConfig    `yaml:"redis"`
+
+	// Migrate is the -migrate flag, the application only migrates postgres and exits when it's set
+	Migrate string `yaml:"-"`
 }
 
 type GrpcConfig struct {
 	Address        string        `yaml:"address"`
 	Port           string        `yaml:"port"`
 	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// MethodTimeouts overrides request_timeout by full method name, e.g. /chatpb.Chat/ChatHistory
+	MethodTimeouts map[string]time.Duration `yaml:"method_timeouts"`
 }
 
 type HttpConfig struct {
@@ -33,9 +44,19 @@ type HttpConfig struct {
 }
 
 type ChatConfig struct {
-	MaxChatsCount      int           `yaml:"maximum_chats_count"`
 	MaxMessagesPerChat int           `yaml:"messages_per_chat"`
 	ChatTTL            time.Duration `yaml:"chat_ttl"`
+	SubscriberBuffer   int           `yaml:"subscriber_buffer"`
+	ReaperInterval     time.Duration `yaml:"reaper_interval"`
+	Quota              QuotaConfig   `yaml:"quota"`
+}
+
+// QuotaConfig is the default quota of every user, zero disables a limit.
+// Overrides for single users are kept in storage.
+type QuotaConfig struct {
+	ChatsOwned        int   `yaml:"chats_owned"`
+	MessagesPerMinute int   `yaml:"messages_per_minute"`
+	MessageBytes      int64 `yaml:"message_bytes"`
 }
 
 type UserConfig struct {
@@ -49,6 +70,64 @@ type KafkaConfig struct {
 	Port string `yaml:"port"`
 }
 
+// OutboxConfig tunes the publisher of the outbox
+type OutboxConfig struct {
+	BatchSize   int           `yaml:"batch_size"`
+	MinInterval time.Duration `yaml:"min_interval"`
+	MaxInterval time.Duration `yaml:"max_interval"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	// ClaimLease is how long a batch claimed by one replica is hidden from the others
+	ClaimLease time.Duration `yaml:"claim_lease"`
+	// Workers send a batch concurrently, events of a chat are always sent in order by one of them
+	Workers int `yaml:"workers"`
+	// Sink is where the outbox is published: kafka, webhook, file or noop
+	Sink    string            `yaml:"sink"`
+	Webhook WebhookSinkConfig `yaml:"webhook"`
+	File    FileSinkConfig    `yaml:"file"`
+	// Retention deletes sent outboxes after they get old
+	Retention RetentionConfig `yaml:"retention"`
+}
+
+type RetentionConfig struct {
+	MaxAge    time.Duration `yaml:"max_age"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+type WebhookSinkConfig struct {
+	Url     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type FileSinkConfig struct {
+	Path string `yaml:"path"`
+}
+
+// LeaderConfig tunes the election of the replica which publishes the outbox and deletes expired data
+type LeaderConfig struct {
+	RenewInterval time.Duration `yaml:"renew_interval"`
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	// Lease is how long the redis lock outlives a leader which stopped renewing it
+	Lease time.Duration `yaml:"lease"`
+}
+
+// TracingConfig sets where the spans are exported
+type TracingConfig struct {
+	// Exporter is otlp, stdout, file or none
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the otlp grpc collector
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	File     string `yaml:"file"`
+	// SampleRatio is the share of the requests which are traced, all of them when it's zero
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// AdminConfig lists uuids of the users allowed to use the admin api
+type AdminConfig struct {
+	Users []string `yaml:"users"`
+}
+
 type StorageConfig struct {
 	Inmemory int `yaml:"inmemory"`
 	Postgres int `yaml:"postgres"`
@@ -61,6 +140,8 @@ type PostgresConfig struct {
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
 	DBname   string `yaml:"dbname"`
+	// MigrateOnStart applies pending schema migrations before the application starts
+	MigrateOnStart bool `yaml:"migrate_on_start"`
 }
 
 type RedisConfig struct {
@@ -71,7 +152,7 @@ type RedisConfig struct {
 }
 
 func MustLoad() *Config {
-	path := fetchFlags()
+	path, migrate := fetchFlags()
 	if path == "" {
 		path = "configs/local.yaml"
 	}
@@ -84,13 +165,14 @@ func MustLoad() *Config {
 	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
 		panic("failed to read config: " + err.Error())
 	}
+	cfg.Migrate = migrate
 	return &cfg
 }
 
-func fetchFlags() string {
-	var configPath string
+func fetchFlags() (configPath string, migrate string) {
 	flag.StringVar(&configPath, "config", "", "path to config file")
+	flag.StringVar(&migrate, "migrate", "", "migrate postgres and exit: up, down or status")
 	flag.Parse()
 
-	return configPath
+	return configPath, migrate
 }
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// ConsumerConfig is the config of the consumer command
+type ConsumerConfig struct {
+	Env      string              `yaml:"env"`
+	Kafka    KafkaConfig         `yaml:"kafka"`
+	Consumer ConsumerGroupConfig `yaml:"consumer"`
+	// AuditLog is the file events are appended to, they are written to stdout when it's empty
+	AuditLog string        `yaml:"audit_log"`
+	Tracing  TracingConfig `yaml:"tracing"`
+}
+
+type ConsumerGroupConfig struct {
+	Group  string   `yaml:"group"`
+	Topics []string `yaml:"topics"`
+	// ProcessedSize is how many handled event uuids are remembered to skip redeliveries
+	ProcessedSize int           `yaml:"processed_size"`
+	MinRetry      time.Duration `yaml:"min_retry"`
+	MaxRetry      time.Duration `yaml:"max_retry"`
+}
+
+func MustLoadConsumer() *ConsumerConfig {
+	var path string
+	flag.StringVar(&path, "config", "", "path to config file")
+	flag.Parse()
+	if path == "" {
+		path = "configs/consumer.yaml"
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		panic("config file does not exists: " + path)
+	}
+
+	var cfg ConsumerConfig
+	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+		panic("failed to read config: " + err.Error())
+	}
+	return &cfg
+}
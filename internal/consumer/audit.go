@@ -0,0 +1,59 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+)
+
+// AuditLog is a handler which writes every event as a json line
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// AuditRecord is a line of the audit log
+type AuditRecord struct {
+	Uuid      string           `json:"uuid"`
+	Type      domain.EventType `json:"type"`
+	ChatUuid  string           `json:"chat_uuid"`
+	Seq       int64            `json:"seq"`
+	Occurred  time.Time        `json:"occurred"`
+	ActorUuid string           `json:"actor_uuid,omitempty"`
+	MessageId int              `json:"message_id,omitempty"`
+}
+
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+func (a *AuditLog) Handle(ctx context.Context, event *domain.Event) error {
+	record := AuditRecord{
+		Uuid:     event.Uuid.String(),
+		Type:     event.Type,
+		ChatUuid: event.ChatUuid.String(),
+		Seq:      event.Seq,
+		Occurred: event.Occurred,
+	}
+	if event.ActorUuid != uuid.Nil {
+		record.ActorUuid = event.ActorUuid.String()
+	}
+	if event.Message != nil {
+		record.MessageId = event.Message.Id
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.w.Write(append(line, '\n'))
+	return err
+}
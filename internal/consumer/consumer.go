@@ -0,0 +1,182 @@
+// Package consumer reads outbox events from kafka.
+// Events are delivered at least once: an offset is marked only after the handler has succeeded,
+// and events which were already handled are skipped by their uuid.
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+)
+
+// Handler processes events, an error leaves the event unmarked and it's handled again
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Handler
+type Handler interface {
+	Handle(ctx context.Context, event *domain.Event) error
+}
+
+// Processed remembers the handled events, so redelivered ones are skipped
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Processed
+type Processed interface {
+	IsProcessed(ctx context.Context, eventUuid uuid.UUID) (bool, error)
+	MarkProcessed(ctx context.Context, eventUuid uuid.UUID) error
+}
+
+type Consumer struct {
+	log       *slog.Logger
+	group     sarama.ConsumerGroup
+	handler   Handler
+	processed Processed
+	options   Options
+}
+
+type Options struct {
+	Group  string
+	Topics []string
+	// A failed event is handled again after MinRetry, the pause doubles up to MaxRetry
+	MinRetry time.Duration
+	MaxRetry time.Duration
+}
+
+const (
+	defaultMinRetry = 100 * time.Millisecond
+	defaultMaxRetry = 10 * time.Second
+)
+
+var ErrNoConnection = errors.New("can't establish connection to kafka")
+
+func New(log *slog.Logger, brokers []string, handler Handler, processed Processed, options Options) (*Consumer, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Offsets.AutoCommit.Enable = true
+
+	group, err := sarama.NewConsumerGroup(brokers, options.Group, config)
+	if err != nil {
+		return nil, fmt.Errorf("can't start sarama consumer group: %w", ErrNoConnection)
+	}
+	return NewWithGroup(log, group, handler, processed, options), nil
+}
+
+func NewWithGroup(log *slog.Logger, group sarama.ConsumerGroup, handler Handler, processed Processed, options Options) *Consumer {
+	if options.MinRetry <= 0 {
+		options.MinRetry = defaultMinRetry
+	}
+	if options.MaxRetry < options.MinRetry {
+		options.MaxRetry = max(defaultMaxRetry, options.MinRetry)
+	}
+	return &Consumer{log: log, group: group, handler: handler, processed: processed, options: options}
+}
+
+// Run consumes the topics until the context is done or the consumer is closed
+func (c *Consumer) Run(ctx context.Context) error {
+	const op = "consumer.Run"
+	log := c.log.With(slog.String("op", op))
+
+	for {
+		// Consume returns on every rebalance, the session is joined again
+		err := c.group.Consume(ctx, c.options.Topics, c)
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			log.Error("error with consuming", sl.Err(err))
+			return err
+		}
+	}
+}
+
+func (c *Consumer) Close() error {
+	return c.group.Close()
+}
+
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim handles the messages of a partition one by one, so events of a chat are handled in order
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := session.Context()
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if err := c.process(ctx, msg); err != nil {
+				// The session is over, the message is consumed again by the next owner of the partition
+				return nil
+			}
+			session.MarkMessage(msg, "")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// process handles the message until it succeeds or the context is done
+func (c *Consumer) process(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	const op = "consumer.process"
+	log := c.log.With(slog.String("op", op), slog.String("topic", msg.Topic), slog.Int64("offset", msg.Offset))
+
+	retry := c.options.MinRetry
+	for {
+		err := c.handle(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		log.Warn("error with handling event", slog.Duration("retry", retry), sl.Err(err))
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		retry = min(retry*2, c.options.MaxRetry)
+	}
+}
+
+// handle passes the message to the handler unless its event was already processed.
+// Messages which can't be decoded never will be, they are logged and skipped.
+func (c *Consumer) handle(ctx context.Context, msg *sarama.ConsumerMessage) (err error) {
+	const op = "consumer.handle"
+	log := c.log.With(slog.String("op", op), slog.String("topic", msg.Topic), slog.Int64("offset", msg.Offset))
+
+	ctx, span := startSpan(ctx, msg)
+	defer func() { endSpan(span, err) }()
+
+	event, err := Decode(msg)
+	if err != nil {
+		log.Error("event is skipped", sl.Err(err))
+		return nil
+	}
+
+	processed, err := c.processed.IsProcessed(ctx, event.Uuid)
+	if err != nil {
+		return err
+	}
+	if processed {
+		log.Debug("event is already processed", slog.String("uuid", event.Uuid.String()))
+		return nil
+	}
+
+	if err := c.handler.Handle(ctx, event); err != nil {
+		return err
+	}
+	return c.processed.MarkProcessed(ctx, event.Uuid)
+}
@@ -0,0 +1,242 @@
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outboxpb "github.com/alexandernizov/grpcmessanger/api/gen/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/consumer/mocks"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+)
+
+var (
+	chatUuidForTests  = uuid.MustParse("01426c84-7593-46b1-8a10-a9bbe4a8bd4c")
+	userUuidForTests  = uuid.MustParse("7f1c2a3e-5b6d-4e8f-9a0b-1c2d3e4f5a6b")
+	eventUuidForTests = uuid.MustParse("c3d4e5f6-a7b8-4c9d-8e0f-1a2b3c4d5e6f")
+)
+
+// kafkaMessage is the message the kafka sink produces for the event
+func kafkaMessage(t *testing.T, value []byte, schemaVersion string) *sarama.ConsumerMessage {
+	t.Helper()
+	return &sarama.ConsumerMessage{
+		Topic: domain.MessageTopic,
+		Value: value,
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(outbox.SeqHeader), Value: []byte("7")},
+			{Key: []byte(outbox.SchemaVersionHeader), Value: []byte(schemaVersion)},
+		},
+	}
+}
+
+func postedMessage(t *testing.T) *sarama.ConsumerMessage {
+	t.Helper()
+	message := &domain.Message{Id: 3, AuthorUuid: userUuidForTests, Body: "hello", Published: time.Unix(1700000000, 0).UTC()}
+	value, err := storage.MarshalMessageEvent(eventUuidForTests, outboxpb.EventType_MESSAGE_POSTED, chatUuidForTests, userUuidForTests, message)
+	require.NoError(t, err)
+	return kafkaMessage(t, value, "1")
+}
+
+func TestDecode(t *testing.T) {
+	chat := &domain.Chat{Uuid: chatUuidForTests, Owner: domain.User{Uuid: userUuidForTests}, Deadline: time.Unix(1700003600, 0).UTC()}
+	chatValue, err := storage.MarshalChatEvent(eventUuidForTests, outboxpb.EventType_CHAT_EXPIRED, chat, time.Unix(1700003600, 0))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		msg     *sarama.ConsumerMessage
+		want    *domain.Event
+		wantErr error
+	}{
+		{
+			name: "message_posted",
+			msg:  postedMessage(t),
+			want: &domain.Event{
+				Uuid:      eventUuidForTests,
+				Type:      domain.EventMessagePosted,
+				ChatUuid:  chatUuidForTests,
+				Seq:       7,
+				Occurred:  time.Unix(1700000000, 0).UTC(),
+				ActorUuid: userUuidForTests,
+				Message:   &domain.Message{Id: 3, AuthorUuid: userUuidForTests, Body: "hello", Published: time.Unix(1700000000, 0).UTC()},
+			},
+		},
+		{
+			name: "chat_expired",
+			msg:  kafkaMessage(t, chatValue, "1"),
+			want: &domain.Event{
+				Uuid:     eventUuidForTests,
+				Type:     domain.EventChatExpired,
+				ChatUuid: chatUuidForTests,
+				Seq:      7,
+				Occurred: time.Unix(1700003600, 0).UTC(),
+				Chat:     chat,
+			},
+		},
+		{
+			name:    "unversioned",
+			msg:     kafkaMessage(t, chatValue, ""),
+			wantErr: ErrUnsupportedSchema,
+		},
+		{
+			name:    "newer_version",
+			msg:     kafkaMessage(t, chatValue, "2"),
+			wantErr: ErrUnsupportedSchema,
+		},
+		{
+			name:    "malformed",
+			msg:     kafkaMessage(t, []byte("not a protobuf"), "1"),
+			wantErr: ErrMalformedEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConsumer_handle(t *testing.T) {
+	someErr := errors.New("some error")
+
+	tests := []struct {
+		name       string
+		msg        *sarama.ConsumerMessage
+		processed  []any
+		handled    []any
+		markCalled bool
+		wantErr    bool
+	}{
+		{
+			name:       "handled",
+			msg:        postedMessage(t),
+			processed:  []any{false, nil},
+			handled:    []any{nil},
+			markCalled: true,
+		},
+		{
+			name:      "duplicate",
+			msg:       postedMessage(t),
+			processed: []any{true, nil},
+		},
+		{
+			name:      "handler_error",
+			msg:       postedMessage(t),
+			processed: []any{false, nil},
+			handled:   []any{someErr},
+			wantErr:   true,
+		},
+		{
+			name:      "processed_error",
+			msg:       postedMessage(t),
+			processed: []any{false, someErr},
+			wantErr:   true,
+		},
+		{
+			// Messages which can't be decoded are skipped
+			name: "undecodable",
+			msg:  kafkaMessage(t, []byte("chat"), "2"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := mocks.NewHandler(t)
+			processed := mocks.NewProcessed(t)
+			if tt.processed != nil {
+				processed.On("IsProcessed", mock.Anything, eventUuidForTests).Return(tt.processed...).Once()
+			}
+			if tt.handled != nil {
+				handler.On("Handle", mock.Anything, mock.MatchedBy(func(event *domain.Event) bool {
+					return event.Uuid == eventUuidForTests
+				})).Return(tt.handled...).Once()
+			}
+			if tt.markCalled {
+				processed.On("MarkProcessed", mock.Anything, eventUuidForTests).Return(nil).Once()
+			}
+
+			c := NewWithGroup(slog.Default(), nil, handler, processed, Options{})
+			err := c.handle(context.Background(), tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Consumer.handle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConsumer_processRetries(t *testing.T) {
+	handler := mocks.NewHandler(t)
+	processed := NewMemoryProcessed(10)
+	handler.On("Handle", mock.Anything, mock.Anything).Return(errors.New("some error")).Once()
+	handler.On("Handle", mock.Anything, mock.Anything).Return(nil).Once()
+
+	c := NewWithGroup(slog.Default(), nil, handler, processed, Options{MinRetry: time.Millisecond})
+	require.NoError(t, c.process(context.Background(), postedMessage(t)))
+
+	// The redelivered event is skipped
+	require.NoError(t, c.process(context.Background(), postedMessage(t)))
+
+	// The retries stop with the session
+	handler.On("Handle", mock.Anything, mock.Anything).Return(errors.New("some error"))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	another := postedMessage(t)
+	value, err := storage.MarshalMessageEvent(uuid.New(), outboxpb.EventType_MESSAGE_POSTED, chatUuidForTests, userUuidForTests, &domain.Message{Id: 4})
+	require.NoError(t, err)
+	another.Value = value
+	assert.ErrorIs(t, c.process(ctx, another), context.DeadlineExceeded)
+}
+
+func TestMemoryProcessed(t *testing.T) {
+	ctx := context.Background()
+	processed := NewMemoryProcessed(2)
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	for _, v := range []uuid.UUID{first, second, second, third} {
+		require.NoError(t, processed.MarkProcessed(ctx, v))
+	}
+
+	// The oldest event is forgotten
+	for v, want := range map[uuid.UUID]bool{first: false, second: true, third: true} {
+		got, err := processed.IsProcessed(ctx, v)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	var out bytes.Buffer
+	event, err := Decode(postedMessage(t))
+	require.NoError(t, err)
+
+	require.NoError(t, NewAuditLog(&out).Handle(context.Background(), event))
+
+	var record AuditRecord
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, AuditRecord{
+		Uuid:      eventUuidForTests.String(),
+		Type:      domain.EventMessagePosted,
+		ChatUuid:  chatUuidForTests.String(),
+		Seq:       7,
+		Occurred:  event.Occurred,
+		ActorUuid: userUuidForTests.String(),
+		MessageId: 3,
+	}, record)
+}
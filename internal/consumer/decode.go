@@ -0,0 +1,131 @@
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	outboxpb "github.com/alexandernizov/grpcmessanger/api/gen/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/outbox"
+)
+
+var (
+	ErrUnsupportedSchema = errors.New("unsupported event schema version")
+	ErrMalformedEvent    = errors.New("malformed event")
+)
+
+var eventTypes = map[outboxpb.EventType]domain.EventType{
+	outboxpb.EventType_CHAT_CREATED:    domain.EventChatCreated,
+	outboxpb.EventType_CHAT_EXPIRED:    domain.EventChatExpired,
+	outboxpb.EventType_MESSAGE_POSTED:  domain.EventMessagePosted,
+	outboxpb.EventType_MESSAGE_EDITED:  domain.EventMessageEdited,
+	outboxpb.EventType_MESSAGE_DELETED: domain.EventMessageDeleted,
+}
+
+// Decode turns a message of the chats or messages topic into an event.
+// Only the schema versions this package knows are decoded, the rest fail with ErrUnsupportedSchema.
+func Decode(msg *sarama.ConsumerMessage) (*domain.Event, error) {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, v := range msg.Headers {
+		headers[string(v.Key)] = string(v.Value)
+	}
+	if headers[outbox.SchemaVersionHeader] != strconv.Itoa(int(outboxpb.SchemaVersion_SCHEMA_VERSION_1)) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedSchema, headers[outbox.SchemaVersionHeader])
+	}
+
+	var event outboxpb.Event
+	if err := proto.Unmarshal(msg.Value, &event); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+	}
+
+	eventType, ok := eventTypes[event.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type %s", ErrMalformedEvent, event.Type)
+	}
+	eventUuid, err := uuid.Parse(event.Id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+	}
+	chatUuid, err := uuid.Parse(event.ChatUuid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+	}
+	res := &domain.Event{
+		Uuid:      eventUuid,
+		Type:      eventType,
+		ChatUuid:  chatUuid,
+		Occurred:  timeOf(event.OccurredAt),
+		ActorUuid: optionalUuid(event.ActorUuid),
+	}
+	// The sequence number is only for checks, events without it are still valid
+	res.Seq, _ = strconv.ParseInt(headers[outbox.SeqHeader], 10, 64)
+
+	switch payload := event.Payload.(type) {
+	case *outboxpb.Event_ChatCreated:
+		res.Chat, err = chatOf(payload.ChatCreated.GetChat())
+	case *outboxpb.Event_ChatExpired:
+		res.Chat, err = chatOf(payload.ChatExpired.GetChat())
+	case *outboxpb.Event_MessagePosted:
+		res.Message, err = messageOf(payload.MessagePosted.GetMessage())
+	case *outboxpb.Event_MessageEdited:
+		res.Message, err = messageOf(payload.MessageEdited.GetMessage())
+	case *outboxpb.Event_MessageDeleted:
+		res.Message, err = messageOf(payload.MessageDeleted.GetMessage())
+	default:
+		err = fmt.Errorf("%w: no payload", ErrMalformedEvent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func chatOf(chat *outboxpb.Chat) (*domain.Chat, error) {
+	chatUuid, err := uuid.Parse(chat.GetUuid())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+	}
+	return &domain.Chat{
+		Uuid:     chatUuid,
+		Owner:    domain.User{Uuid: optionalUuid(chat.GetOwnerUuid())},
+		Readonly: chat.GetReadonly(),
+		Deadline: timeOf(chat.GetDeadline()),
+	}, nil
+}
+
+func messageOf(message *outboxpb.Message) (*domain.Message, error) {
+	if message == nil {
+		return nil, fmt.Errorf("%w: no message", ErrMalformedEvent)
+	}
+	return &domain.Message{
+		Id:         int(message.Id),
+		AuthorUuid: optionalUuid(message.AuthorUuid),
+		Body:       message.Body,
+		Published:  timeOf(message.Published),
+		DeletedAt:  timeOf(message.Deleted),
+	}, nil
+}
+
+// timeOf returns the zero time for unset timestamps
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// optionalUuid returns uuid.Nil for empty or malformed uuids
+func optionalUuid(s string) uuid.UUID {
+	res, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil
+	}
+	return res
+}
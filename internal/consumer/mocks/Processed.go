@@ -0,0 +1,68 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Processed is an autogenerated mock type for the Processed type
+type Processed struct {
+	mock.Mock
+}
+
+// IsProcessed provides a mock function with given fields: ctx, eventUuid
+func (_m *Processed) IsProcessed(ctx context.Context, eventUuid uuid.UUID) (bool, error) {
+	ret := _m.Called(ctx, eventUuid)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (bool, error)); ok {
+		return rf(ctx, eventUuid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) bool); ok {
+		r0 = rf(ctx, eventUuid)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, eventUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkProcessed provides a mock function with given fields: ctx, eventUuid
+func (_m *Processed) MarkProcessed(ctx context.Context, eventUuid uuid.UUID) error {
+	ret := _m.Called(ctx, eventUuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, eventUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewProcessed interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewProcessed creates a new instance of Processed. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewProcessed(t mockConstructorTestingTNewProcessed) *Processed {
+	mock := &Processed{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,52 @@
+package consumer
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const defaultProcessedSize = 100_000
+
+// MemoryProcessed remembers the latest processed events in memory.
+// Redeliveries come soon after the original, so older events are forgotten when the size is reached.
+// It doesn't survive restarts, handlers which can't see an event twice need a persistent Processed.
+type MemoryProcessed struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	uuids map[uuid.UUID]*list.Element
+}
+
+func NewMemoryProcessed(size int) *MemoryProcessed {
+	if size <= 0 {
+		size = defaultProcessedSize
+	}
+	return &MemoryProcessed{size: size, order: list.New(), uuids: make(map[uuid.UUID]*list.Element)}
+}
+
+func (m *MemoryProcessed) IsProcessed(ctx context.Context, eventUuid uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.uuids[eventUuid]
+	return ok, nil
+}
+
+func (m *MemoryProcessed) MarkProcessed(ctx context.Context, eventUuid uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.uuids[eventUuid]; ok {
+		return nil
+	}
+	m.uuids[eventUuid] = m.order.PushBack(eventUuid)
+	if m.order.Len() > m.size {
+		oldest := m.order.Front()
+		m.order.Remove(oldest)
+		delete(m.uuids, oldest.Value.(uuid.UUID))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
+)
+
+var tracer = otel.Tracer("github.com/alexandernizov/grpcmessanger/internal/consumer")
+
+// startSpan continues the trace passed in the headers of the message by the outbox publisher
+func startSpan(ctx context.Context, msg *sarama.ConsumerMessage) (context.Context, trace.Span) {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, v := range msg.Headers {
+		headers[string(v.Key)] = string(v.Value)
+	}
+	return tracer.Start(tracing.Extract(ctx, headers), msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.kafka.destination.partition", int(msg.Partition)),
+			attribute.Int64("messaging.kafka.message.offset", msg.Offset),
+		))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
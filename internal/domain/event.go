@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventChatCreated    EventType = "chat.created"
+	EventChatExpired    EventType = "chat.expired"
+	EventMessagePosted  EventType = "message.posted"
+	EventMessageEdited  EventType = "message.edited"
+	EventMessageDeleted EventType = "message.deleted"
+)
+
+// Event is an outbox event as consumers see it
+type Event struct {
+	Uuid     uuid.UUID
+	Type     EventType
+	ChatUuid uuid.UUID
+	// Seq is the number of the event within its chat
+	Seq      int64
+	Occurred time.Time
+	// ActorUuid is the user who caused the event, uuid.Nil for expired chats
+	ActorUuid uuid.UUID
+	// Chat is set for chat events, Message for message events
+	Chat    *Chat
+	Message *Message
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleWriter Role = "writer"
+	RoleReader Role = "reader"
+)
+
+var roleLevels = map[Role]int{
+	RoleReader: 1,
+	RoleWriter: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// AtLeast reports whether r grants everything the other role does.
+// Unknown roles, including the empty one of a non-member, grant nothing.
+func (r Role) AtLeast(other Role) bool {
+	return r.Valid() && roleLevels[r] >= roleLevels[other]
+}
+
+// Above reports whether r is strictly higher than the other role.
+func (r Role) Above(other Role) bool {
+	return r.Valid() && roleLevels[r] > roleLevels[other]
+}
+
+type Member struct {
+	ChatUuid  uuid.UUID
+	UserUuid  uuid.UUID
+	Role      Role
+	InvitedBy uuid.UUID
+	Joined    time.Time
+}
@@ -12,4 +12,30 @@ type Message struct {
 	AuthorUuid uuid.UUID
 	Body       string
 	Published  time.Time
+	// Edits keeps previous versions of the body, oldest first
+	Edits []MessageEdit
+	// DeletedAt is the tombstone of a deleted message, its body and edits are cleared
+	DeletedAt time.Time
+}
+
+func (m *Message) Deleted() bool {
+	return !m.DeletedAt.IsZero()
+}
+
+type MessageEdit struct {
+	Body   string
+	Edited time.Time
+}
+
+// HistoryQuery selects a page of chat history, cursors are message ids.
+// With AfterId the oldest messages after it are selected, otherwise the newest ones before BeforeId.
+type HistoryQuery struct {
+	Limit    int
+	BeforeId int
+	AfterId  int
+}
+
+type HistoryPage struct {
+	Messages   []*Message
+	NextCursor int
 }
@@ -12,8 +12,45 @@ const (
 )
 
 type Outbox struct {
-	Uuid    uuid.UUID
-	Topic   string
-	Message []byte
-	Sent_at time.Time
+	Uuid  uuid.UUID
+	Topic string
+	// Events of a chat are numbered from 1 in the order they happened
+	ChatUuid uuid.UUID
+	Seq      int64
+	Message  []byte
+	// SchemaVersion is the version of the marshalled event, 0 is for events older than the versioned envelope
+	SchemaVersion int
+	// TraceContext is the propagated trace of the change which added the outbox, it's sent along with the message
+	TraceContext map[string]string
+	Sent_at      time.Time
+	// Attempts is how many times publishing has failed, LastError is the reason of the latest failure
+	Attempts  int
+	LastError string
+}
+
+// DeadLetter is an outbox record moved aside after it failed to publish too many times
+type DeadLetter struct {
+	Uuid          uuid.UUID
+	Topic         string
+	ChatUuid      uuid.UUID
+	Seq           int64
+	Message       []byte
+	SchemaVersion int
+	TraceContext  map[string]string
+	Attempts      int
+	LastError     string
+	Failed        time.Time
+}
+
+// DeadLetterPage is a page of dead letters, NextOffset is zero on the last page
+type DeadLetterPage struct {
+	DeadLetters []*DeadLetter
+	NextOffset  int
+}
+
+// OutboxBacklog describes the outbox records waiting to be published
+type OutboxBacklog struct {
+	Unsent int
+	// OldestAge is how long the oldest unsent record has been waiting, zero when there are none
+	OldestAge time.Duration
 }
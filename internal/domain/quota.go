@@ -0,0 +1,31 @@
+package domain
+
+// Quota limits what a single user can do.
+// Limits of zero or less are not enforced.
+type Quota struct {
+	ChatsOwned        int
+	MessagesPerMinute int
+	MessageBytes      int64
+}
+
+// Override returns the quota with the non-zero limits of the override applied.
+// Overrides use negative limits to lift a default limit for the user.
+func (q Quota) Override(override Quota) Quota {
+	if override.ChatsOwned != 0 {
+		q.ChatsOwned = override.ChatsOwned
+	}
+	if override.MessagesPerMinute != 0 {
+		q.MessagesPerMinute = override.MessagesPerMinute
+	}
+	if override.MessageBytes != 0 {
+		q.MessageBytes = override.MessageBytes
+	}
+	return q
+}
+
+// QuotaUsage is what the user has already spent of their quota
+type QuotaUsage struct {
+	ChatsOwned         int
+	MessagesLastMinute int
+	MessageBytes       int64
+}
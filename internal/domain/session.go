@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a login of the user on one device.
+// Its refresh token is rotated on every refresh, only the latest one is accepted.
+type Session struct {
+	Uuid       uuid.UUID
+	UserUuid   uuid.UUID
+	RefreshJti uuid.UUID
+	DeviceName string
+	UserAgent  string
+	Created    time.Time
+	LastUsed   time.Time
+	Expires    time.Time
+}
+
+type Device struct {
+	Name      string
+	UserAgent string
+}
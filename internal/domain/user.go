@@ -1,6 +1,10 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	Uuid         uuid.UUID
@@ -11,7 +15,17 @@ type User struct {
 type Tokens struct {
 	AccessToken  string
 	RefreshToken string
+	// RefreshJti identifies the refresh token within its session
+	RefreshJti uuid.UUID
+}
+
+// AccessClaims identify the caller by the access token the auth interceptor has validated
+type AccessClaims struct {
+	UserUuid    uuid.UUID
+	SessionUuid uuid.UUID
+	Jti         uuid.UUID
+	Expired     time.Time
 }
 
-type UserUuidCtxKey struct {
+type AccessClaimsCtxKey struct {
 }
@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alexandernizov/grpcmessanger/api/gen/adminpb"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	adminServ "github.com/alexandernizov/grpcmessanger/internal/services/admin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AdminProvider
+type AdminProvider interface {
+	ListDeadLetters(ctx context.Context, actorUuid uuid.UUID, limit int, offset int) (*domain.DeadLetterPage, error)
+	GetDeadLetter(ctx context.Context, actorUuid uuid.UUID, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, actorUuid uuid.UUID, deadLetterUuid uuid.UUID) error
+	PurgeDeadLetters(ctx context.Context, actorUuid uuid.UUID, deadLetterUuid uuid.UUID) (int, error)
+	SetUserQuota(ctx context.Context, actorUuid uuid.UUID, userUuid uuid.UUID, quota domain.Quota) error
+}
+
+type AdminServer struct {
+	adminpb.UnimplementedAdminServer
+	Provider AdminProvider
+}
+
+func (a *AdminServer) ListDeadLetters(ctx context.Context, req *adminpb.ListDeadLettersReq) (*adminpb.ListDeadLettersResp, error) {
+	if req.PageSize < 0 || req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Page size and offset should be positive")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := a.Provider.ListDeadLetters(ctx, actorUuid, int(req.PageSize), int(req.Offset))
+	if err != nil {
+		return nil, adminStatusError(err)
+	}
+
+	res := &adminpb.ListDeadLettersResp{NextOffset: int32(page.NextOffset)}
+	for _, v := range page.DeadLetters {
+		res.DeadLetters = append(res.DeadLetters, toAdminpbDeadLetter(v))
+	}
+	return res, nil
+}
+
+func (a *AdminServer) GetDeadLetter(ctx context.Context, req *adminpb.GetDeadLetterReq) (*adminpb.GetDeadLetterResp, error) {
+	deadLetterUuid, err := uuid.Parse(req.Uuid)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Dead letter Uuid is incorrect")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetter, err := a.Provider.GetDeadLetter(ctx, actorUuid, deadLetterUuid)
+	if err != nil {
+		return nil, adminStatusError(err)
+	}
+	return &adminpb.GetDeadLetterResp{DeadLetter: toAdminpbDeadLetter(deadLetter)}, nil
+}
+
+func (a *AdminServer) ReplayDeadLetter(ctx context.Context, req *adminpb.ReplayDeadLetterReq) (*adminpb.ReplayDeadLetterResp, error) {
+	deadLetterUuid, err := uuid.Parse(req.Uuid)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Dead letter Uuid is incorrect")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Provider.ReplayDeadLetter(ctx, actorUuid, deadLetterUuid); err != nil {
+		return nil, adminStatusError(err)
+	}
+	return &adminpb.ReplayDeadLetterResp{Replayed: true}, nil
+}
+
+func (a *AdminServer) PurgeDeadLetters(ctx context.Context, req *adminpb.PurgeDeadLettersReq) (*adminpb.PurgeDeadLettersResp, error) {
+	// Without uuid all dead letters are purged
+	deadLetterUuid := uuid.Nil
+	if req.Uuid != "" {
+		var err error
+		deadLetterUuid, err = uuid.Parse(req.Uuid)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "Dead letter Uuid is incorrect")
+		}
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	purged, err := a.Provider.PurgeDeadLetters(ctx, actorUuid, deadLetterUuid)
+	if err != nil {
+		return nil, adminStatusError(err)
+	}
+	return &adminpb.PurgeDeadLettersResp{Purged: int64(purged)}, nil
+}
+
+func (a *AdminServer) SetUserQuota(ctx context.Context, req *adminpb.SetUserQuotaReq) (*adminpb.SetUserQuotaResp, error) {
+	userUuid, err := uuid.Parse(req.UserUuid)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "User Uuid is incorrect")
+	}
+	if req.Quota == nil {
+		return nil, status.Error(codes.InvalidArgument, "Quota is required")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := domain.Quota{ChatsOwned: int(req.Quota.ChatsOwned), MessagesPerMinute: int(req.Quota.MessagesPerMinute), MessageBytes: req.Quota.MessageBytes}
+	if err := a.Provider.SetUserQuota(ctx, actorUuid, userUuid, quota); err != nil {
+		return nil, adminStatusError(err)
+	}
+	return &adminpb.SetUserQuotaResp{Quota: req.Quota}, nil
+}
+
+func adminStatusError(err error) error {
+	switch {
+	case errors.Is(err, adminServ.ErrDeadLetterNotFound), errors.Is(err, adminServ.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, adminServ.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toAdminpbDeadLetter(deadLetter *domain.DeadLetter) *adminpb.DeadLetter {
+	return &adminpb.DeadLetter{
+		Uuid:      deadLetter.Uuid.String(),
+		Topic:     deadLetter.Topic,
+		Message:   deadLetter.Message,
+		Attempts:  int32(deadLetter.Attempts),
+		LastError: deadLetter.LastError,
+		Failed:    deadLetter.Failed.Unix(),
+		ChatUuid:  deadLetter.ChatUuid.String(),
+		Seq:       deadLetter.Seq,
+	}
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/api/gen/adminpb"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/grpc/mocks"
+	adminServ "github.com/alexandernizov/grpcmessanger/internal/services/admin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	deadLetterUuidForTests = uuid.MustParse("9d5f3c2a-1b4e-4f6a-8c7d-2e1f0a9b8c01")
+	quotaUserUuidForTests  = uuid.MustParse("9d5f3c2a-1b4e-4f6a-8c7d-2e1f0a9b8c02")
+)
+
+func TestAdminServer_ListDeadLetters(t *testing.T) {
+	failed := time.Now()
+	deadLetter := &domain.DeadLetter{Uuid: deadLetterUuidForTests, Topic: domain.ChatTopic, ChatUuid: chatUuidForTests, Seq: 2, Message: []byte("chat"), Attempts: 5, LastError: "kafka is down", Failed: failed}
+	pbDeadLetter := &adminpb.DeadLetter{Uuid: deadLetterUuidForTests.String(), Topic: domain.ChatTopic, ChatUuid: chatUuidForTests.String(), Seq: 2, Message: []byte("chat"), Attempts: 5, LastError: "kafka is down", Failed: failed.Unix()}
+
+	tests := []struct {
+		name      string
+		req       *adminpb.ListDeadLettersReq
+		returning []any
+		want      *adminpb.ListDeadLettersResp
+		wantCode  codes.Code
+	}{
+		{
+			name:      "full_page",
+			req:       &adminpb.ListDeadLettersReq{PageSize: 1, Offset: 2},
+			returning: []any{&domain.DeadLetterPage{DeadLetters: []*domain.DeadLetter{deadLetter}, NextOffset: 3}, nil},
+			want:      &adminpb.ListDeadLettersResp{DeadLetters: []*adminpb.DeadLetter{pbDeadLetter}, NextOffset: 3},
+		},
+		{
+			name:      "default_page_size",
+			req:       &adminpb.ListDeadLettersReq{},
+			returning: []any{&domain.DeadLetterPage{DeadLetters: []*domain.DeadLetter{deadLetter}, NextOffset: 50}, nil},
+			want:      &adminpb.ListDeadLettersResp{DeadLetters: []*adminpb.DeadLetter{pbDeadLetter}, NextOffset: 50},
+		},
+		{
+			name:      "last_page",
+			req:       &adminpb.ListDeadLettersReq{PageSize: 2},
+			returning: []any{&domain.DeadLetterPage{DeadLetters: []*domain.DeadLetter{deadLetter}}, nil},
+			want:      &adminpb.ListDeadLettersResp{DeadLetters: []*adminpb.DeadLetter{pbDeadLetter}},
+		},
+		{
+			name:     "negative_offset",
+			req:      &adminpb.ListDeadLettersReq{Offset: -1},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:      "not_admin",
+			req:       &adminpb.ListDeadLettersReq{},
+			returning: []any{nil, adminServ.ErrPermissionDenied},
+			wantCode:  codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := mocks.NewAdminProvider(t)
+			if tt.returning != nil {
+				provider.On("ListDeadLetters", mock.Anything, userUuidForTests, int(tt.req.PageSize), int(tt.req.Offset)).Return(tt.returning...).Once()
+			}
+			a := &AdminServer{Provider: provider}
+
+			got, err := a.ListDeadLetters(authContext(context.Background(), tokensForTests.AccessToken), tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAdminServer_PurgeDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *adminpb.PurgeDeadLettersReq
+		deadLetter uuid.UUID
+		returning  []any
+		want       *adminpb.PurgeDeadLettersResp
+		wantCode   codes.Code
+	}{
+		{
+			name:       "one",
+			req:        &adminpb.PurgeDeadLettersReq{Uuid: deadLetterUuidForTests.String()},
+			deadLetter: deadLetterUuidForTests,
+			returning:  []any{1, nil},
+			want:       &adminpb.PurgeDeadLettersResp{Purged: 1},
+		},
+		{
+			name:       "all",
+			req:        &adminpb.PurgeDeadLettersReq{},
+			deadLetter: uuid.Nil,
+			returning:  []any{3, nil},
+			want:       &adminpb.PurgeDeadLettersResp{Purged: 3},
+		},
+		{
+			name:       "not_found",
+			req:        &adminpb.PurgeDeadLettersReq{Uuid: deadLetterUuidForTests.String()},
+			deadLetter: deadLetterUuidForTests,
+			returning:  []any{0, adminServ.ErrDeadLetterNotFound},
+			wantCode:   codes.NotFound,
+		},
+		{
+			name:     "invalid_uuid",
+			req:      &adminpb.PurgeDeadLettersReq{Uuid: "invalid"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := mocks.NewAdminProvider(t)
+			if tt.returning != nil {
+				provider.On("PurgeDeadLetters", mock.Anything, userUuidForTests, tt.deadLetter).Return(tt.returning...).Once()
+			}
+			a := &AdminServer{Provider: provider}
+
+			got, err := a.PurgeDeadLetters(authContext(context.Background(), tokensForTests.AccessToken), tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAdminServer_SetUserQuota(t *testing.T) {
+	pbQuota := &adminpb.Quota{ChatsOwned: 10, MessagesPerMinute: -1, MessageBytes: 1024}
+	quota := domain.Quota{ChatsOwned: 10, MessagesPerMinute: -1, MessageBytes: 1024}
+
+	tests := []struct {
+		name      string
+		req       *adminpb.SetUserQuotaReq
+		returning []any
+		want      *adminpb.SetUserQuotaResp
+		wantCode  codes.Code
+	}{
+		{
+			name:      "set",
+			req:       &adminpb.SetUserQuotaReq{UserUuid: quotaUserUuidForTests.String(), Quota: pbQuota},
+			returning: []any{nil},
+			want:      &adminpb.SetUserQuotaResp{Quota: pbQuota},
+		},
+		{
+			name:      "user_not_found",
+			req:       &adminpb.SetUserQuotaReq{UserUuid: quotaUserUuidForTests.String(), Quota: pbQuota},
+			returning: []any{adminServ.ErrUserNotFound},
+			wantCode:  codes.NotFound,
+		},
+		{
+			name:      "not_admin",
+			req:       &adminpb.SetUserQuotaReq{UserUuid: quotaUserUuidForTests.String(), Quota: pbQuota},
+			returning: []any{adminServ.ErrPermissionDenied},
+			wantCode:  codes.PermissionDenied,
+		},
+		{
+			name:     "invalid_uuid",
+			req:      &adminpb.SetUserQuotaReq{UserUuid: "invalid", Quota: pbQuota},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no_quota",
+			req:      &adminpb.SetUserQuotaReq{UserUuid: quotaUserUuidForTests.String()},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := mocks.NewAdminProvider(t)
+			if tt.returning != nil {
+				provider.On("SetUserQuota", mock.Anything, userUuidForTests, quotaUserUuidForTests, quota).Return(tt.returning...).Once()
+			}
+			a := &AdminServer{Provider: provider}
+
+			got, err := a.SetUserQuota(authContext(context.Background(), tokensForTests.AccessToken), tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
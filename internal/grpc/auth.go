@@ -11,14 +11,21 @@ import (
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AuthProvider
 type AuthProvider interface {
 	Register(ctx context.Context, login, password string) (*domain.User, error)
-	Login(ctx context.Context, login, password string) (*domain.Tokens, error)
+	Login(ctx context.Context, login, password string, device domain.Device) (*domain.Tokens, error)
 	Refresh(ctx context.Context, refreshToken string) (*domain.Tokens, error)
+	Logout(ctx context.Context, claims domain.AccessClaims) error
+	LogoutAll(ctx context.Context, claims domain.AccessClaims) error
+	IsRevoked(ctx context.Context, claims domain.AccessClaims) (bool, error)
+
+	ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error)
+	RevokeSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error
 }
 
 type AuthServer struct {
@@ -52,7 +59,8 @@ func (a *AuthServer) Login(ctx context.Context, req *authpb.LoginReq) (*authpb.L
 		return nil, status.Error(codes.InvalidArgument, "login and password is required")
 	}
 	//Get result
-	tokens, err := a.Provider.Login(ctx, req.Login, req.Password)
+	device := domain.Device{Name: req.DeviceName, UserAgent: userAgent(ctx)}
+	tokens, err := a.Provider.Login(ctx, req.Login, req.Password, device)
 	if err != nil {
 		if errors.Is(err, authServ.ErrInvalidCredentials) {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -78,3 +86,103 @@ func (a *AuthServer) Refresh(ctx context.Context, req *authpb.RefreshReq) (*auth
 	}
 	return &authpb.RefreshResp{AccessToken: newTokens.AccessToken, RefreshToken: newTokens.RefreshToken}, nil
 }
+
+func (a *AuthServer) Logout(ctx context.Context, req *authpb.LogoutReq) (*authpb.LogoutResp, error) {
+	//Validate
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	//Get result
+	err = a.Provider.Logout(ctx, claims)
+	if err != nil {
+		if errors.Is(err, authServ.ErrInvalidCredentials) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &authpb.LogoutResp{LoggedOut: true}, nil
+}
+
+func (a *AuthServer) LogoutAll(ctx context.Context, req *authpb.LogoutAllReq) (*authpb.LogoutAllResp, error) {
+	//Validate
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	//Get result
+	err = a.Provider.LogoutAll(ctx, claims)
+	if err != nil {
+		if errors.Is(err, authServ.ErrInvalidCredentials) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &authpb.LogoutAllResp{LoggedOut: true}, nil
+}
+
+func (a *AuthServer) ListSessions(ctx context.Context, req *authpb.ListSessionsReq) (*authpb.ListSessionsResp, error) {
+	//Validate
+	userUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	//Get result
+	sessions, err := a.Provider.ListSessions(ctx, userUuid)
+	if err != nil {
+		if errors.Is(err, authServ.ErrInvalidCredentials) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	//Send response
+	resp := &authpb.ListSessionsResp{}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &authpb.Session{
+			Uuid:       session.Uuid.String(),
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			Created:    session.Created.Unix(),
+			LastUsed:   session.LastUsed.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+func (a *AuthServer) RevokeSession(ctx context.Context, req *authpb.RevokeSessionReq) (*authpb.RevokeSessionResp, error) {
+	//Validate
+	userUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessionUuid, err := uuid.Parse(req.SessionUuid)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "session uuid is incorrect")
+	}
+	//Get result
+	err = a.Provider.RevokeSession(ctx, userUuid, sessionUuid)
+	if err != nil {
+		if errors.Is(err, authServ.ErrInvalidCredentials) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, authServ.ErrSessionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &authpb.RevokeSessionResp{Revoked: true}, nil
+}
+
+// userAgent of the client, the gateway passes the one of the http client under its own key
+func userAgent(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"grpcgateway-user-agent", "user-agent"} {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/alexandernizov/grpcmessanger/api/gen/authpb"
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
@@ -115,7 +116,7 @@ func TestAuthServer_Login(t *testing.T) {
 				ctx: context.Background(),
 				req: &authpb.LoginReq{Login: "Test", Password: "Test"},
 			},
-			mockArgs: mockArgs{methodName: "Login", arguments: []any{mock.Anything, "Test", "Test"}, returning: []any{&domain.Tokens{AccessToken: "test", RefreshToken: "test"}, nil}},
+			mockArgs: mockArgs{methodName: "Login", arguments: []any{mock.Anything, "Test", "Test", mock.Anything}, returning: []any{&domain.Tokens{AccessToken: "test", RefreshToken: "test"}, nil}},
 			want:     &authpb.LoginResp{AccessToken: "test", RefreshToken: "test"},
 			wantErr:  false,
 		},
@@ -189,3 +190,61 @@ func TestAuthServer_Refresh(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthServer_Logout(t *testing.T) {
+	type mockArgs struct {
+		methodName string
+		arguments  []any
+		returning  []any
+	}
+	claims := domain.AccessClaims{UserUuid: userUuidForTests, SessionUuid: sessionForTests, Jti: uuid.New(), Expired: time.Now().Add(time.Minute)}
+	authenticated := context.WithValue(context.Background(), domain.AccessClaimsCtxKey{}, claims)
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		mockArgs mockArgs
+		want     *authpb.LogoutResp
+		wantErr  bool
+	}{
+		{
+			name:     "success",
+			ctx:      authenticated,
+			mockArgs: mockArgs{methodName: "Logout", arguments: []any{mock.Anything, claims}, returning: []any{nil}},
+			want:     &authpb.LogoutResp{LoggedOut: true},
+			wantErr:  false,
+		},
+		{
+			name:     "invalid_token",
+			ctx:      authenticated,
+			mockArgs: mockArgs{methodName: "Logout", arguments: []any{mock.Anything, claims}, returning: []any{auth.ErrInvalidCredentials}},
+			want:     nil,
+			wantErr:  true,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			mockArgs: mockArgs{},
+			want:     nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := mocks.NewAuthProvider(t)
+			if tt.mockArgs.methodName > "" {
+				authProvider.On(tt.mockArgs.methodName, tt.mockArgs.arguments...).Return(tt.mockArgs.returning...).Once()
+			}
+			a := &AuthServer{
+				Provider: authProvider,
+			}
+			got, err := a.Logout(tt.ctx, &authpb.LogoutReq{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AuthServer.Logout() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AuthServer.Logout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
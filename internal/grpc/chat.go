@@ -3,13 +3,14 @@ package grpc
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	chatServ "github.com/alexandernizov/grpcmessanger/internal/services/chat"
 
 	"github.com/alexandernizov/grpcmessanger/api/gen/chatpb"
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
-	"github.com/alexandernizov/grpcmessanger/internal/pkg/jwt"
 	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -18,13 +19,35 @@ import (
 type ChatProvider interface {
 	NewChat(ctx context.Context, ownerUuid uuid.UUID, readonly bool, ttl int) (*domain.Chat, error)
 	NewMessage(ctx context.Context, chatUuid uuid.UUID, authorUuid uuid.UUID, message string) (*domain.Message, error)
-	ChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error)
+	ChatHistory(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID, query domain.HistoryQuery) (*domain.HistoryPage, error)
+	Subscribe(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID, lastSeenId int) (<-chan *domain.Message, error)
+
+	InviteMember(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, userUuid uuid.UUID, role domain.Role) (*domain.Member, error)
+	RemoveMember(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, userUuid uuid.UUID) error
+	ListMembers(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID) ([]*domain.Member, error)
+
+	EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string) (*domain.Message, error)
+	DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int) error
 }
 
+var (
+	rolesToPb = map[domain.Role]chatpb.Role{
+		domain.RoleReader: chatpb.Role_ROLE_READER,
+		domain.RoleWriter: chatpb.Role_ROLE_WRITER,
+		domain.RoleAdmin:  chatpb.Role_ROLE_ADMIN,
+		domain.RoleOwner:  chatpb.Role_ROLE_OWNER,
+	}
+	rolesFromPb = map[chatpb.Role]domain.Role{
+		chatpb.Role_ROLE_READER: domain.RoleReader,
+		chatpb.Role_ROLE_WRITER: domain.RoleWriter,
+		chatpb.Role_ROLE_ADMIN:  domain.RoleAdmin,
+		chatpb.Role_ROLE_OWNER:  domain.RoleOwner,
+	}
+)
+
 type ChatServer struct {
 	chatpb.UnimplementedChatServer
 	Provider ChatProvider
-	Secret   string
 }
 
 func (c *ChatServer) NewChat(ctx context.Context, req *chatpb.NewChatReq) (*chatpb.NewChatResp, error) {
@@ -32,17 +55,18 @@ func (c *ChatServer) NewChat(ctx context.Context, req *chatpb.NewChatReq) (*chat
 		return nil, status.Error(codes.InvalidArgument, "ttl should be more than 0")
 	}
 
-	ownerUuid, err := jwt.GetUserUuidFromToken(req.Token, []byte(c.Secret))
+	ownerUuid, err := userUuidFromContext(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "token is incorrect")
+		return nil, err
 	}
 
 	chat, err := c.Provider.NewChat(ctx, ownerUuid, req.Readonly, int(req.TtlSecs))
 	if err != nil {
-		if errors.Is(err, chatServ.ErrNotificationNotCreated) {
+		// The chat is created even if its notification isn't, the provider may not return it though
+		if errors.Is(err, chatServ.ErrNotificationNotCreated) && chat != nil {
 			return &chatpb.NewChatResp{Uuid: chat.Uuid.String()}, nil
 		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, chatStatusError(err)
 	}
 	//Send response
 	return &chatpb.NewChatResp{Uuid: chat.Uuid.String()}, nil
@@ -57,9 +81,9 @@ func (c *ChatServer) NewMessage(ctx context.Context, req *chatpb.NewMessageReq)
 		return nil, status.Error(codes.InvalidArgument, "Message is required")
 	}
 
-	authorUuid, err := jwt.GetUserUuidFromToken(req.Token, []byte(c.Secret))
+	authorUuid, err := userUuidFromContext(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "Token is incorrect")
+		return nil, err
 	}
 
 	chatUuid, err := uuid.Parse(req.ChatUuid)
@@ -78,7 +102,7 @@ func (c *ChatServer) NewMessage(ctx context.Context, req *chatpb.NewMessageReq)
 		if errors.Is(err, chatServ.ErrNotificationNotCreated) {
 			return &chatpb.NewMessageResp{Published: true}, nil
 		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, chatStatusError(err)
 	}
 
 	return &chatpb.NewMessageResp{Published: true}, nil
@@ -89,28 +113,288 @@ func (c *ChatServer) ChatHistory(ctx context.Context, req *chatpb.ChatHistoryReq
 		return nil, status.Error(codes.InvalidArgument, "Chat UUID is required")
 	}
 
+	if req.PageSize < 0 || req.BeforeId < 0 || req.AfterId < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Page size and cursors should be positive")
+	}
+
+	if req.BeforeId > 0 && req.AfterId > 0 {
+		return nil, status.Error(codes.InvalidArgument, "Only one of before_id and after_id can be set")
+	}
+
 	chatUuid, err := uuid.Parse(req.Uuid)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "Chat Uuid is incorrect")
 	}
 
-	res, err := c.Provider.ChatHistory(ctx, chatUuid)
+	userUuid, err := userUuidFromContext(ctx)
 	if err != nil {
-		if errors.Is(err, chatServ.ErrChatNotFound) {
-			return nil, status.Error(codes.NotFound, err.Error())
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
+	}
+
+	query := domain.HistoryQuery{Limit: int(req.PageSize), BeforeId: int(req.BeforeId), AfterId: int(req.AfterId)}
+	res, err := c.Provider.ChatHistory(ctx, chatUuid, userUuid, query)
+	if err != nil {
+		return nil, chatStatusError(err)
 	}
 
 	var messagesResponse []*chatpb.Message
 
-	for _, message := range res {
-		var mes chatpb.Message
-		mes.Author = message.AuthorUuid.String()
-		mes.Message = message.Body
-		mes.Published = message.Published.Unix()
-		messagesResponse = append(messagesResponse, &mes)
+	for _, message := range res.Messages {
+		messagesResponse = append(messagesResponse, toChatpbMessage(message))
+	}
+
+	return &chatpb.ChatHistoryResp{Messages: messagesResponse, NextCursor: int64(res.NextCursor)}, nil
+}
+
+func (c *ChatServer) Subscribe(req *chatpb.SubscribeReq, stream chatpb.Chat_SubscribeServer) error {
+	if req.Uuid == "" {
+		return status.Error(codes.InvalidArgument, "Chat UUID is required")
+	}
+
+	if req.LastSeenId < 0 {
+		return status.Error(codes.InvalidArgument, "Last seen id should be positive")
+	}
+
+	chatUuid, err := uuid.Parse(req.Uuid)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Chat Uuid is incorrect")
+	}
+
+	ctx := stream.Context()
+	userUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	messages, err := c.Provider.Subscribe(ctx, chatUuid, userUuid, int(req.LastSeenId))
+	if err != nil {
+		return chatStatusError(err)
+	}
+
+	for message := range messages {
+		if err := stream.Send(toChatpbMessage(message)); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	// Channel was closed by the hub, the client didn't keep up with the chat
+	return status.Error(codes.ResourceExhausted, "subscriber is too slow")
+}
+
+func (c *ChatServer) InviteMember(ctx context.Context, req *chatpb.InviteMemberReq) (*chatpb.InviteMemberResp, error) {
+	chatUuid, userUuid, err := parseMemberUuids(req.ChatUuid, req.UserUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	role, ok := rolesFromPb[req.Role]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Role is required")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := c.Provider.InviteMember(ctx, chatUuid, actorUuid, userUuid, role)
+	if err != nil {
+		return nil, chatStatusError(err)
+	}
+
+	return &chatpb.InviteMemberResp{Member: toChatpbMember(member)}, nil
+}
+
+func (c *ChatServer) RemoveMember(ctx context.Context, req *chatpb.RemoveMemberReq) (*chatpb.RemoveMemberResp, error) {
+	chatUuid, userUuid, err := parseMemberUuids(req.ChatUuid, req.UserUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Provider.RemoveMember(ctx, chatUuid, actorUuid, userUuid)
+	if err != nil {
+		return nil, chatStatusError(err)
+	}
+
+	return &chatpb.RemoveMemberResp{Removed: true}, nil
+}
+
+func (c *ChatServer) ListMembers(ctx context.Context, req *chatpb.ListMembersReq) (*chatpb.ListMembersResp, error) {
+	if req.ChatUuid == "" {
+		return nil, status.Error(codes.InvalidArgument, "Chat UUID is required")
+	}
+
+	chatUuid, err := uuid.Parse(req.ChatUuid)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Chat Uuid is incorrect")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := c.Provider.ListMembers(ctx, chatUuid, actorUuid)
+	if err != nil {
+		return nil, chatStatusError(err)
+	}
+
+	var membersResponse []*chatpb.Member
+	for _, member := range members {
+		membersResponse = append(membersResponse, toChatpbMember(member))
+	}
+
+	return &chatpb.ListMembersResp{Members: membersResponse}, nil
+}
+
+func (c *ChatServer) EditMessage(ctx context.Context, req *chatpb.EditMessageReq) (*chatpb.EditMessageResp, error) {
+	chatUuid, err := parseMessageRef(req.ChatUuid, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Message == "" {
+		return nil, status.Error(codes.InvalidArgument, "Message is required")
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := c.Provider.EditMessage(ctx, chatUuid, actorUuid, int(req.Id), req.Message)
+	if err != nil {
+		return nil, chatStatusError(err)
+	}
+
+	return &chatpb.EditMessageResp{Message: toChatpbMessage(message)}, nil
+}
+
+func (c *ChatServer) DeleteMessage(ctx context.Context, req *chatpb.DeleteMessageReq) (*chatpb.DeleteMessageResp, error) {
+	chatUuid, err := parseMessageRef(req.ChatUuid, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	actorUuid, err := userUuidFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Provider.DeleteMessage(ctx, chatUuid, actorUuid, int(req.Id))
+	if err != nil {
+		return nil, chatStatusError(err)
+	}
+
+	return &chatpb.DeleteMessageResp{Deleted: true}, nil
+}
+
+func parseMessageRef(chat string, id int64) (uuid.UUID, error) {
+	if chat == "" {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "Chat UUID is required")
+	}
+
+	if id <= 0 {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "Message id should be positive")
+	}
+
+	chatUuid, err := uuid.Parse(chat)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "Chat Uuid is incorrect")
+	}
+
+	return chatUuid, nil
+}
+
+func parseMemberUuids(chat, user string) (uuid.UUID, uuid.UUID, error) {
+	if chat == "" || user == "" {
+		return uuid.Nil, uuid.Nil, status.Error(codes.InvalidArgument, "Chat UUID and User UUID are required")
 	}
 
-	return &chatpb.ChatHistoryResp{Messages: messagesResponse}, nil
+	chatUuid, err := uuid.Parse(chat)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Error(codes.InvalidArgument, "Chat Uuid is incorrect")
+	}
+
+	userUuid, err := uuid.Parse(user)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Error(codes.InvalidArgument, "User Uuid is incorrect")
+	}
+
+	return chatUuid, userUuid, nil
+}
+
+func chatStatusError(err error) error {
+	var quotaErr *chatServ.QuotaError
+	switch {
+	case errors.As(err, &quotaErr):
+		return quotaStatusError(quotaErr)
+	case errors.Is(err, chatServ.ErrChatNotFound), errors.Is(err, chatServ.ErrMemberNotFound), errors.Is(err, chatServ.ErrUserNotFound),
+		errors.Is(err, chatServ.ErrMessageNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, chatServ.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, chatServ.ErrInvalidCursor), errors.Is(err, chatServ.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// quotaStatusError tells clients which quota is exhausted and how much of it is used
+func quotaStatusError(err *chatServ.QuotaError) error {
+	st, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(
+		&errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{Subject: err.Quota, Description: err.Error()}},
+		},
+		&errdetails.ErrorInfo{
+			Reason: "QUOTA_EXCEEDED",
+			Domain: "grpcmessanger",
+			Metadata: map[string]string{
+				"quota": err.Quota,
+				"limit": strconv.FormatInt(err.Limit, 10),
+				"used":  strconv.FormatInt(err.Used, 10),
+			},
+		},
+	)
+	if detailsErr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
+
+func toChatpbMember(member *domain.Member) *chatpb.Member {
+	res := &chatpb.Member{
+		UserUuid: member.UserUuid.String(),
+		Role:     rolesToPb[member.Role],
+		Joined:   member.Joined.Unix(),
+	}
+	if member.InvitedBy != uuid.Nil {
+		res.InvitedBy = member.InvitedBy.String()
+	}
+	return res
+}
+
+func toChatpbMessage(message *domain.Message) *chatpb.Message {
+	res := &chatpb.Message{
+		Id:        int64(message.Id),
+		Author:    message.AuthorUuid.String(),
+		Message:   message.Body,
+		Published: message.Published.Unix(),
+	}
+	for _, edit := range message.Edits {
+		res.Edits = append(res.Edits, &chatpb.MessageEdit{Message: edit.Body, Edited: edit.Edited.Unix()})
+	}
+	if message.Deleted() {
+		res.Deleted = message.DeletedAt.Unix()
+	}
+	return res
 }
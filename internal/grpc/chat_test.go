@@ -11,8 +11,13 @@ import (
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
 	"github.com/alexandernizov/grpcmessanger/internal/grpc/mocks"
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/jwt"
+	chatServ "github.com/alexandernizov/grpcmessanger/internal/services/chat"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -20,10 +25,21 @@ var (
 	userUuidForTests  = uuid.MustParse("4c92f03d-cdbe-40c5-8edd-3a938aa69e50")
 	userForTests      = domain.User{Uuid: userUuidForTests, Login: "Test", PasswordHash: []byte("Test")}
 	secretTests       = []byte("Test")
-	tokensForTests, _ = jwt.NewTokens(userForTests, 10000, 10000, secretTests)
+	sessionForTests   = uuid.MustParse("b3c1a2de-41c5-4b8e-9a55-0c2f1f6e7d10")
+	tokensForTests, _ = jwt.NewTokens(userForTests, sessionForTests, 10000, 10000, secretTests)
 	publishedForTest  = time.Now()
 )
 
+// authContext imitates the auth interceptor, which puts the claims of a valid token into the context
+func authContext(ctx context.Context, token string) context.Context {
+	claims, err := jwt.ParseToken(token, secretTests)
+	if err != nil {
+		return ctx
+	}
+	accessClaims := domain.AccessClaims{UserUuid: claims.UserUuid, SessionUuid: claims.SessionUuid, Jti: claims.Jti, Expired: claims.Expired}
+	return context.WithValue(ctx, domain.AccessClaimsCtxKey{}, accessClaims)
+}
+
 func TestChatServer_NewChat(t *testing.T) {
 	type mockArgs struct {
 		methodName string
@@ -97,6 +113,34 @@ func TestChatServer_NewChat(t *testing.T) {
 			want:     nil,
 			wantErr:  true,
 		},
+		{
+			name: "notification_not_created",
+			funcArgs: funcArgs{
+				ctx: context.Background(),
+				req: &chatpb.NewChatReq{
+					Token:    tokensForTests.AccessToken,
+					Readonly: false,
+					TtlSecs:  1,
+				},
+			},
+			mockArgs: mockArgs{methodName: "NewChat", arguments: []any{mock.Anything, userUuidForTests, false, 1}, returning: []any{&domain.Chat{Uuid: chatUuidForTests}, chatServ.ErrNotificationNotCreated}},
+			want:     &chatpb.NewChatResp{Uuid: chatUuidForTests.String()},
+			wantErr:  false,
+		},
+		{
+			name: "notification_not_created_without_chat",
+			funcArgs: funcArgs{
+				ctx: context.Background(),
+				req: &chatpb.NewChatReq{
+					Token:    tokensForTests.AccessToken,
+					Readonly: false,
+					TtlSecs:  1,
+				},
+			},
+			mockArgs: mockArgs{methodName: "NewChat", arguments: []any{mock.Anything, userUuidForTests, false, 1}, returning: []any{nil, chatServ.ErrNotificationNotCreated}},
+			want:     nil,
+			wantErr:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -106,9 +150,8 @@ func TestChatServer_NewChat(t *testing.T) {
 			}
 			c := &ChatServer{
 				Provider: chatProvider,
-				Secret:   string(secretTests),
 			}
-			got, err := c.NewChat(tt.funcArgs.ctx, tt.funcArgs.req)
+			got, err := c.NewChat(authContext(tt.funcArgs.ctx, tt.funcArgs.req.Token), tt.funcArgs.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ChatServer.NewChat() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -201,9 +244,8 @@ func TestChatServer_NewMessage(t *testing.T) {
 			}
 			c := &ChatServer{
 				Provider: chatProvider,
-				Secret:   string(secretTests),
 			}
-			got, err := c.NewMessage(tt.funcArgs.ctx, tt.funcArgs.req)
+			got, err := c.NewMessage(authContext(tt.funcArgs.ctx, tt.funcArgs.req.Token), tt.funcArgs.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ChatServer.NewMessage() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -237,18 +279,52 @@ func TestChatServer_ChatHistory(t *testing.T) {
 			funcArgs: funcArgs{
 				ctx: context.Background(),
 				req: &chatpb.ChatHistoryReq{
-					Token: userUuidForTests.String(),
+					Token: tokensForTests.AccessToken,
 					Uuid:  chatUuidForTests.String(),
 				},
 			},
-			mockArgs: mockArgs{methodName: "ChatHistory", arguments: []any{mock.Anything, chatUuidForTests}, returning: []any{[]*domain.Message{
+			mockArgs: mockArgs{methodName: "ChatHistory", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, domain.HistoryQuery{}}, returning: []any{&domain.HistoryPage{Messages: []*domain.Message{
 				{Id: 1, AuthorUuid: userUuidForTests, Body: "test", Published: publishedForTest},
-			}, nil}},
+			}}, nil}},
 			want: &chatpb.ChatHistoryResp{Messages: []*chatpb.Message{
-				{Author: userUuidForTests.String(), Published: publishedForTest.Unix(), Message: "test"},
+				{Id: 1, Author: userUuidForTests.String(), Published: publishedForTest.Unix(), Message: "test"},
 			}},
 			wantErr: false,
 		},
+		{
+			name: "paginated",
+			funcArgs: funcArgs{
+				ctx: context.Background(),
+				req: &chatpb.ChatHistoryReq{
+					Token:    tokensForTests.AccessToken,
+					Uuid:     chatUuidForTests.String(),
+					PageSize: 1,
+					BeforeId: 3,
+				},
+			},
+			mockArgs: mockArgs{methodName: "ChatHistory", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, domain.HistoryQuery{Limit: 1, BeforeId: 3}}, returning: []any{&domain.HistoryPage{Messages: []*domain.Message{
+				{Id: 2, AuthorUuid: userUuidForTests, Body: "test", Published: publishedForTest},
+			}, NextCursor: 2}, nil}},
+			want: &chatpb.ChatHistoryResp{Messages: []*chatpb.Message{
+				{Id: 2, Author: userUuidForTests.String(), Published: publishedForTest.Unix(), Message: "test"},
+			}, NextCursor: 2},
+			wantErr: false,
+		},
+		{
+			name: "both_cursors",
+			funcArgs: funcArgs{
+				ctx: context.Background(),
+				req: &chatpb.ChatHistoryReq{
+					Token:    tokensForTests.AccessToken,
+					Uuid:     chatUuidForTests.String(),
+					BeforeId: 3,
+					AfterId:  1,
+				},
+			},
+			mockArgs: mockArgs{},
+			want:     nil,
+			wantErr:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -258,9 +334,8 @@ func TestChatServer_ChatHistory(t *testing.T) {
 			}
 			c := &ChatServer{
 				Provider: chatProvider,
-				Secret:   string(secretTests),
 			}
-			got, err := c.ChatHistory(tt.funcArgs.ctx, tt.funcArgs.req)
+			got, err := c.ChatHistory(authContext(tt.funcArgs.ctx, tt.funcArgs.req.Token), tt.funcArgs.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ChatServer.ChatHistory() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -271,3 +346,247 @@ func TestChatServer_ChatHistory(t *testing.T) {
 		})
 	}
 }
+
+type subscribeStreamForTests struct {
+	chatpb.Chat_SubscribeServer
+	ctx  context.Context
+	sent []*chatpb.Message
+}
+
+func (s *subscribeStreamForTests) Context() context.Context {
+	return s.ctx
+}
+
+func (s *subscribeStreamForTests) Send(m *chatpb.Message) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestChatServer_Subscribe(t *testing.T) {
+	messagesForTests := func(messages ...*domain.Message) <-chan *domain.Message {
+		ch := make(chan *domain.Message, len(messages))
+		for _, m := range messages {
+			ch <- m
+		}
+		close(ch)
+		return ch
+	}
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type mockArgs struct {
+		methodName string
+		arguments  []any
+		returning  []any
+	}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		req      *chatpb.SubscribeReq
+		mockArgs mockArgs
+		want     []*chatpb.Message
+		wantCode codes.Code
+	}{
+		{
+			name: "evicted",
+			ctx:  context.Background(),
+			req:  &chatpb.SubscribeReq{Token: tokensForTests.AccessToken, Uuid: chatUuidForTests.String(), LastSeenId: 1},
+			mockArgs: mockArgs{methodName: "Subscribe", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, 1}, returning: []any{messagesForTests(
+				&domain.Message{Id: 2, AuthorUuid: userUuidForTests, Body: "test", Published: publishedForTest},
+			), nil}},
+			want:     []*chatpb.Message{{Id: 2, Author: userUuidForTests.String(), Published: publishedForTest.Unix(), Message: "test"}},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "canceled",
+			ctx:      canceledCtx,
+			req:      &chatpb.SubscribeReq{Token: tokensForTests.AccessToken, Uuid: chatUuidForTests.String()},
+			mockArgs: mockArgs{methodName: "Subscribe", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, 0}, returning: []any{messagesForTests(), nil}},
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "chat_not_found",
+			ctx:      context.Background(),
+			req:      &chatpb.SubscribeReq{Token: tokensForTests.AccessToken, Uuid: chatUuidForTests.String()},
+			mockArgs: mockArgs{methodName: "Subscribe", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, 0}, returning: []any{nil, chatServ.ErrChatNotFound}},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "incorrect_token",
+			ctx:      context.Background(),
+			req:      &chatpb.SubscribeReq{Token: "incorrect token", Uuid: chatUuidForTests.String()},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "empty_chat_uuid",
+			ctx:      context.Background(),
+			req:      &chatpb.SubscribeReq{Token: tokensForTests.AccessToken},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatProvider := mocks.NewChatProvider(t)
+			if tt.mockArgs.methodName > "" {
+				chatProvider.On(tt.mockArgs.methodName, tt.mockArgs.arguments...).Return(tt.mockArgs.returning...).Once()
+			}
+			c := &ChatServer{
+				Provider: chatProvider,
+			}
+			stream := &subscribeStreamForTests{ctx: authContext(tt.ctx, tt.req.Token)}
+			err := c.Subscribe(tt.req, stream)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("ChatServer.Subscribe() error = %v, wantCode %v", err, tt.wantCode)
+				return
+			}
+			if !reflect.DeepEqual(stream.sent, tt.want) {
+				t.Errorf("ChatServer.Subscribe() sent = %v, want %v", stream.sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatServer_EditMessage(t *testing.T) {
+	type mockArgs struct {
+		methodName string
+		arguments  []any
+		returning  []any
+	}
+	published := time.Unix(1700000000, 0)
+	edited := &domain.Message{Id: 7, AuthorUuid: userUuidForTests, Body: "new", Published: published,
+		Edits: []domain.MessageEdit{{Body: "old", Edited: published}}}
+	tests := []struct {
+		name     string
+		req      *chatpb.EditMessageReq
+		mockArgs mockArgs
+		want     *chatpb.EditMessageResp
+		wantCode codes.Code
+	}{
+		{
+			name:     "success",
+			req:      &chatpb.EditMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Id: 7, Message: "new"},
+			mockArgs: mockArgs{methodName: "EditMessage", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, 7, "new"}, returning: []any{edited, nil}},
+			want: &chatpb.EditMessageResp{Message: &chatpb.Message{Id: 7, Author: userUuidForTests.String(), Message: "new", Published: published.Unix(),
+				Edits: []*chatpb.MessageEdit{{Message: "old", Edited: published.Unix()}}}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "not_an_author",
+			req:      &chatpb.EditMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Id: 7, Message: "new"},
+			mockArgs: mockArgs{methodName: "EditMessage", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, 7, "new"}, returning: []any{nil, chatServ.ErrPermissionDenied}},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "message_not_found",
+			req:      &chatpb.EditMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Id: 7, Message: "new"},
+			mockArgs: mockArgs{methodName: "EditMessage", arguments: []any{mock.Anything, chatUuidForTests, userUuidForTests, 7, "new"}, returning: []any{nil, chatServ.ErrMessageNotFound}},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "empty_message",
+			req:      &chatpb.EditMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Id: 7},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "incorrect_id",
+			req:      &chatpb.EditMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Message: "new"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatProvider := mocks.NewChatProvider(t)
+			if tt.mockArgs.methodName > "" {
+				chatProvider.On(tt.mockArgs.methodName, tt.mockArgs.arguments...).Return(tt.mockArgs.returning...).Once()
+			}
+			c := &ChatServer{
+				Provider: chatProvider,
+			}
+			got, err := c.EditMessage(authContext(context.Background(), tt.req.Token), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("ChatServer.EditMessage() error = %v, wantCode %v", err, tt.wantCode)
+				return
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("ChatServer.EditMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatServer_DeleteMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *chatpb.DeleteMessageReq
+		providerFn func(p *mocks.ChatProvider)
+		wantCode   codes.Code
+	}{
+		{
+			name: "success",
+			req:  &chatpb.DeleteMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Id: 7},
+			providerFn: func(p *mocks.ChatProvider) {
+				p.On("DeleteMessage", mock.Anything, chatUuidForTests, userUuidForTests, 7).Return(nil).Once()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "chat_not_found",
+			req:  &chatpb.DeleteMessageReq{Token: tokensForTests.AccessToken, ChatUuid: chatUuidForTests.String(), Id: 7},
+			providerFn: func(p *mocks.ChatProvider) {
+				p.On("DeleteMessage", mock.Anything, chatUuidForTests, userUuidForTests, 7).Return(chatServ.ErrChatNotFound).Once()
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "incorrect_token",
+			req:      &chatpb.DeleteMessageReq{Token: "incorrect token", ChatUuid: chatUuidForTests.String(), Id: 7},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatProvider := mocks.NewChatProvider(t)
+			if tt.providerFn != nil {
+				tt.providerFn(chatProvider)
+			}
+			c := &ChatServer{
+				Provider: chatProvider,
+			}
+			_, err := c.DeleteMessage(authContext(context.Background(), tt.req.Token), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("ChatServer.DeleteMessage() error = %v, wantCode %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestChatServer_NewChatQuota(t *testing.T) {
+	chatProvider := mocks.NewChatProvider(t)
+	quotaErr := &chatServ.QuotaError{Quota: chatServ.QuotaChatsOwned, Limit: 5, Used: 5}
+	chatProvider.On("NewChat", mock.Anything, userUuidForTests, false, 0).Return(nil, quotaErr).Once()
+
+	c := &ChatServer{Provider: chatProvider}
+	req := &chatpb.NewChatReq{Token: tokensForTests.AccessToken}
+	_, err := c.NewChat(authContext(context.Background(), req.Token), req)
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("ChatServer.NewChat() error = %v, wantCode %v", err, codes.ResourceExhausted)
+	}
+	var violation *errdetails.QuotaFailure_Violation
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.QuotaFailure:
+			violation = d.Violations[0]
+		case *errdetails.ErrorInfo:
+			info = d
+		}
+	}
+	if violation == nil || violation.Subject != chatServ.QuotaChatsOwned {
+		t.Errorf("ChatServer.NewChat() quota failure = %v, want subject %s", violation, chatServ.QuotaChatsOwned)
+	}
+	if info == nil || info.Metadata["limit"] != "5" || info.Metadata["used"] != "5" {
+		t.Errorf("ChatServer.NewChat() error info = %v", info)
+	}
+}
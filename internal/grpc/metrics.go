@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	handledRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Requests handled by the grpc server by method and status code.",
+	}, []string{"method", "code"})
+	handlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency of the grpc requests, streams are measured until they are closed.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+	activeStreams = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_server_active_streams",
+		Help: "Streams which are open now.",
+	}, []string{"method"})
+	panics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_panics_total",
+		Help: "Requests which panicked and were recovered.",
+	}, []string{"method"})
+)
+
+// unaryMetricsInterceptor goes first, so requests rejected by other interceptors are counted too
+func unaryMetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRequest(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func streamMetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		activeStreams.WithLabelValues(info.FullMethod).Inc()
+		defer activeStreams.WithLabelValues(info.FullMethod).Dec()
+
+		err := handler(srv, ss)
+		observeRequest(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func observeRequest(method string, start time.Time, err error) {
+	handlingSeconds.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	handledRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+}
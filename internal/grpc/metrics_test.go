@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryMetricsInterceptor(t *testing.T) {
+	const method = "/chatpb.Chat/MetricsTest"
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "ok", code: codes.OK},
+		{name: "not_found", err: status.Error(codes.NotFound, "chat is not found"), code: codes.NotFound},
+		{name: "not_status", err: context.Canceled, code: codes.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := handledRequests.WithLabelValues(method, tt.code.String())
+			before := testutil.ToFloat64(handled)
+
+			handler := func(ctx context.Context, req any) (any, error) {
+				return nil, tt.err
+			}
+			_, err := unaryMetricsInterceptor()(context.Background(), nil, info, handler)
+			if err != tt.err {
+				t.Errorf("unaryMetricsInterceptor() error = %v, want %v", err, tt.err)
+			}
+			if got := testutil.ToFloat64(handled) - before; got != 1 {
+				t.Errorf("handled requests with code %v = %v, want 1", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestStreamMetricsInterceptor(t *testing.T) {
+	const method = "/chatpb.Chat/StreamMetricsTest"
+	info := &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}
+	active := activeStreams.WithLabelValues(method)
+
+	handler := func(srv any, stream grpc.ServerStream) error {
+		if got := testutil.ToFloat64(active); got != 1 {
+			t.Errorf("active streams while streaming = %v, want 1", got)
+		}
+		return status.Error(codes.Unavailable, "server is stopping")
+	}
+	err := streamMetricsInterceptor()(nil, &authStreamForTests{ctx: context.Background()}, info, handler)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("streamMetricsInterceptor() error = %v, want %v", err, codes.Unavailable)
+	}
+	if got := testutil.ToFloat64(active); got != 0 {
+		t.Errorf("active streams after the stream = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(handledRequests.WithLabelValues(method, codes.Unavailable.String())); got != 1 {
+		t.Errorf("handled streams = %v, want 1", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	domain "github.com/alexandernizov/grpcmessanger/internal/domain"
 
+	uuid "github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -15,25 +16,75 @@ type AuthProvider struct {
 	mock.Mock
 }
 
-// Login provides a mock function with given fields: ctx, login, password
-func (_m *AuthProvider) Login(ctx context.Context, login string, password string) (*domain.Tokens, error) {
-	ret := _m.Called(ctx, login, password)
+// IsRevoked provides a mock function with given fields: ctx, token
+func (_m *AuthProvider) IsRevoked(ctx context.Context, claims domain.AccessClaims) (bool, error) {
+	ret := _m.Called(ctx, claims)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AccessClaims) (bool, error)); ok {
+		return rf(ctx, claims)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AccessClaims) bool); ok {
+		r0 = rf(ctx, claims)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AccessClaims) error); ok {
+		r1 = rf(ctx, claims)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSessions provides a mock function with given fields: ctx, accessToken
+func (_m *AuthProvider) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	ret := _m.Called(ctx, userUuid)
+
+	var r0 []*domain.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*domain.Session, error)); ok {
+		return rf(ctx, userUuid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*domain.Session); ok {
+		r0 = rf(ctx, userUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Login provides a mock function with given fields: ctx, login, password, device
+func (_m *AuthProvider) Login(ctx context.Context, login string, password string, device domain.Device) (*domain.Tokens, error) {
+	ret := _m.Called(ctx, login, password, device)
 
 	var r0 *domain.Tokens
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.Tokens, error)); ok {
-		return rf(ctx, login, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.Device) (*domain.Tokens, error)); ok {
+		return rf(ctx, login, password, device)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.Tokens); ok {
-		r0 = rf(ctx, login, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.Device) *domain.Tokens); ok {
+		r0 = rf(ctx, login, password, device)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Tokens)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, login, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, domain.Device) error); ok {
+		r1 = rf(ctx, login, password, device)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,6 +92,34 @@ func (_m *AuthProvider) Login(ctx context.Context, login string, password string
 	return r0, r1
 }
 
+// Logout provides a mock function with given fields: ctx, accessToken
+func (_m *AuthProvider) Logout(ctx context.Context, claims domain.AccessClaims) error {
+	ret := _m.Called(ctx, claims)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AccessClaims) error); ok {
+		r0 = rf(ctx, claims)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LogoutAll provides a mock function with given fields: ctx, accessToken
+func (_m *AuthProvider) LogoutAll(ctx context.Context, claims domain.AccessClaims) error {
+	ret := _m.Called(ctx, claims)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AccessClaims) error); ok {
+		r0 = rf(ctx, claims)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Refresh provides a mock function with given fields: ctx, refreshToken
 func (_m *AuthProvider) Refresh(ctx context.Context, refreshToken string) (*domain.Tokens, error) {
 	ret := _m.Called(ctx, refreshToken)
@@ -93,6 +172,20 @@ func (_m *AuthProvider) Register(ctx context.Context, login string, password str
 	return r0, r1
 }
 
+// RevokeSession provides a mock function with given fields: ctx, accessToken, sessionUuid
+func (_m *AuthProvider) RevokeSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	ret := _m.Called(ctx, userUuid, sessionUuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUuid, sessionUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewAuthProvider interface {
 	mock.TestingT
 	Cleanup(func())
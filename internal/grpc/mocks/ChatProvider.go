@@ -7,9 +7,8 @@ import (
 
 	domain "github.com/alexandernizov/grpcmessanger/internal/domain"
 
-	mock "github.com/stretchr/testify/mock"
-
 	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // ChatProvider is an autogenerated mock type for the ChatProvider type
@@ -17,25 +16,117 @@ type ChatProvider struct {
 	mock.Mock
 }
 
-// ChatHistory provides a mock function with given fields: ctx, chatUuid
-func (_m *ChatProvider) ChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error) {
-	ret := _m.Called(ctx, chatUuid)
+// ChatHistory provides a mock function with given fields: ctx, chatUuid, userUuid, query
+func (_m *ChatProvider) ChatHistory(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID, query domain.HistoryQuery) (*domain.HistoryPage, error) {
+	ret := _m.Called(ctx, chatUuid, userUuid, query)
 
-	var r0 []*domain.Message
+	var r0 *domain.HistoryPage
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*domain.Message, error)); ok {
-		return rf(ctx, chatUuid)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, domain.HistoryQuery) (*domain.HistoryPage, error)); ok {
+		return rf(ctx, chatUuid, userUuid, query)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*domain.Message); ok {
-		r0 = rf(ctx, chatUuid)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, domain.HistoryQuery) *domain.HistoryPage); ok {
+		r0 = rf(ctx, chatUuid, userUuid, query)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*domain.Message)
+			r0 = ret.Get(0).(*domain.HistoryPage)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
-		r1 = rf(ctx, chatUuid)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, domain.HistoryQuery) error); ok {
+		r1 = rf(ctx, chatUuid, userUuid, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteMessage provides a mock function with given fields: ctx, chatUuid, actorUuid, messageId
+func (_m *ChatProvider) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int) error {
+	ret := _m.Called(ctx, chatUuid, actorUuid, messageId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int) error); ok {
+		r0 = rf(ctx, chatUuid, actorUuid, messageId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditMessage provides a mock function with given fields: ctx, chatUuid, actorUuid, messageId, body
+func (_m *ChatProvider) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string) (*domain.Message, error) {
+	ret := _m.Called(ctx, chatUuid, actorUuid, messageId, body)
+
+	var r0 *domain.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int, string) (*domain.Message, error)); ok {
+		return rf(ctx, chatUuid, actorUuid, messageId, body)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int, string) *domain.Message); ok {
+		r0 = rf(ctx, chatUuid, actorUuid, messageId, body)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, int, string) error); ok {
+		r1 = rf(ctx, chatUuid, actorUuid, messageId, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InviteMember provides a mock function with given fields: ctx, chatUuid, actorUuid, userUuid, role
+func (_m *ChatProvider) InviteMember(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, userUuid uuid.UUID, role domain.Role) (*domain.Member, error) {
+	ret := _m.Called(ctx, chatUuid, actorUuid, userUuid, role)
+
+	var r0 *domain.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, domain.Role) (*domain.Member, error)); ok {
+		return rf(ctx, chatUuid, actorUuid, userUuid, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, domain.Role) *domain.Member); ok {
+		r0 = rf(ctx, chatUuid, actorUuid, userUuid, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, domain.Role) error); ok {
+		r1 = rf(ctx, chatUuid, actorUuid, userUuid, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMembers provides a mock function with given fields: ctx, chatUuid, actorUuid
+func (_m *ChatProvider) ListMembers(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID) ([]*domain.Member, error) {
+	ret := _m.Called(ctx, chatUuid, actorUuid)
+
+	var r0 []*domain.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) ([]*domain.Member, error)); ok {
+		return rf(ctx, chatUuid, actorUuid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []*domain.Member); ok {
+		r0 = rf(ctx, chatUuid, actorUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, chatUuid, actorUuid)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -95,6 +186,46 @@ func (_m *ChatProvider) NewMessage(ctx context.Context, chatUuid uuid.UUID, auth
 	return r0, r1
 }
 
+// RemoveMember provides a mock function with given fields: ctx, chatUuid, actorUuid, userUuid
+func (_m *ChatProvider) RemoveMember(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, userUuid uuid.UUID) error {
+	ret := _m.Called(ctx, chatUuid, actorUuid, userUuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, chatUuid, actorUuid, userUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: ctx, chatUuid, userUuid, lastSeenId
+func (_m *ChatProvider) Subscribe(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID, lastSeenId int) (<-chan *domain.Message, error) {
+	ret := _m.Called(ctx, chatUuid, userUuid, lastSeenId)
+
+	var r0 <-chan *domain.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int) (<-chan *domain.Message, error)); ok {
+		return rf(ctx, chatUuid, userUuid, lastSeenId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int) <-chan *domain.Message); ok {
+		r0 = rf(ctx, chatUuid, userUuid, lastSeenId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *domain.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, int) error); ok {
+		r1 = rf(ctx, chatUuid, userUuid, lastSeenId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewChatProvider interface {
 	mock.TestingT
 	Cleanup(func())
@@ -7,15 +7,21 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
-	"reflect"
+	"runtime/debug"
+	"strings"
 	"time"
 
+	"github.com/alexandernizov/grpcmessanger/api/gen/adminpb"
 	"github.com/alexandernizov/grpcmessanger/api/gen/authpb"
 	"github.com/alexandernizov/grpcmessanger/api/gen/chatpb"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/jwt"
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
@@ -38,12 +44,16 @@ func NewServer(log *slog.Logger) *Server {
 }
 
 type ServerOptions struct {
-	Address        string
+	Address string
+	// RequestTimeout is the deadline of unary requests, MethodTimeouts overrides it by full method name.
+	// Zero means no deadline, streams have none.
 	RequestTimeout time.Duration
+	MethodTimeouts map[string]time.Duration
 	JwtSecret      []byte
 
 	AuthProvider
 	ChatProvider
+	AdminProvider
 }
 
 func (s *Server) Start(opt ServerOptions) {
@@ -60,12 +70,25 @@ func (s *Server) Start(opt ServerOptions) {
 		log.Error("can't make listener", sl.Err(err))
 	}
 
-	s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(
-		unaryLoggingInterceptor(s.log),
-		unaryAuthInterceptor(s.log, opt.JwtSecret),
-	))
+	s.server = grpc.NewServer(
+		// The trace of the caller, e.g. the http gateway, is continued
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			unaryMetricsInterceptor(),
+			unaryRecoveryInterceptor(s.log),
+			unaryLoggingInterceptor(s.log),
+			unaryTimeoutInterceptor(opt.RequestTimeout, opt.MethodTimeouts),
+			unaryAuthInterceptor(s.log, opt.JwtSecret, opt.AuthProvider),
+		),
+		grpc.ChainStreamInterceptor(
+			streamMetricsInterceptor(),
+			streamRecoveryInterceptor(s.log),
+			streamAuthInterceptor(s.log, opt.JwtSecret, opt.AuthProvider),
+		),
+	)
 	authpb.RegisterAuthServer(s.server, &AuthServer{Provider: opt.AuthProvider})
-	chatpb.RegisterChatServer(s.server, &ChatServer{Provider: opt.ChatProvider, Secret: string(opt.JwtSecret)})
+	chatpb.RegisterChatServer(s.server, &ChatServer{Provider: opt.ChatProvider})
+	adminpb.RegisterAdminServer(s.server, &AdminServer{Provider: opt.AdminProvider})
 	reflection.Register(s.server)
 
 	log.Info("grpc server is running")
@@ -107,41 +130,173 @@ func unaryLoggingInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
 	}
 }
 
-func unaryAuthInterceptor(log *slog.Logger, jwtSecret []byte) grpc.UnaryServerInterceptor {
+// unaryTimeoutInterceptor sets the deadline of the method unless the caller's one is sooner
+func unaryTimeoutInterceptor(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		timeout, ok := methodTimeouts[info.FullMethod]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// unaryRecoveryInterceptor turns a panic of the request into codes.Internal, so the server keeps running
+func unaryRecoveryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(log, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func streamRecoveryInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(log, info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
 
-		skip := make(map[string]bool)
-		skip["/authpb.Auth/Register"] = true
-		skip["/authpb.Auth/Login"] = true
-		skip["/authpb.Auth/Refresh"] = true
+// recovered logs the panic with its stack and counts it
+func recovered(log *slog.Logger, method string, p any) error {
+	const op = "grpc.recovered"
+	log = log.With(slog.String("op", op))
 
-		if _, ok := skip[info.FullMethod]; ok {
+	panics.WithLabelValues(method).Inc()
+	log.Error("panic while handling request", slog.String("method", method), slog.Any("panic", p), slog.String("stack", string(debug.Stack())))
+	return status.Error(codes.Internal, "internal error")
+}
+
+// TokenDenylist reports whether a token was revoked before its expiration
+type TokenDenylist interface {
+	IsRevoked(ctx context.Context, claims domain.AccessClaims) (bool, error)
+}
+
+// publicMethods don't require an access token
+var publicMethods = map[string]bool{
+	"/authpb.Auth/Register": true,
+	"/authpb.Auth/Login":    true,
+	"/authpb.Auth/Refresh":  true,
+}
+
+func unaryAuthInterceptor(log *slog.Logger, jwtSecret []byte, denylist TokenDenylist) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
 			return handler(ctx, req)
 		}
 
-		// Используем рефлексию для получения поля "token"
-		v := reflect.ValueOf(req)
-		if v.Kind() == reflect.Ptr {
-			v = v.Elem()
+		ctx, err := authenticate(ctx, log, jwtSecret, denylist)
+		if err != nil {
+			return nil, err
 		}
 
-		// Проверяем, есть ли поле "token" в сообщении
-		tokenField := v.FieldByName("Token")
-		if !tokenField.IsValid() {
-			return nil, status.Errorf(codes.Unauthenticated, "token field is missing in request")
+		return handler(ctx, req)
+	}
+}
+
+func streamAuthInterceptor(log *slog.Logger, jwtSecret []byte, denylist TokenDenylist) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if publicMethods[info.FullMethod] {
+			return handler(srv, ss)
 		}
 
-		token, ok := tokenField.Interface().(string)
-		if !ok || token == "" {
-			return nil, status.Errorf(codes.Unauthenticated, "token is invalid or missing")
+		ctx, err := authenticate(ss.Context(), log, jwtSecret, denylist)
+		if err != nil {
+			return err
 		}
 
-		ok, err := jwt.ValidateToken(token, jwtSecret)
-		if !ok || err != nil {
-			log.Warn("someone trying to get access with invalid token", slog.String("token", token))
-			return nil, status.Errorf(codes.Unauthenticated, "token is invalid")
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authStream passes the context with the authenticated claims to the handler
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate validates the access token of the metadata once and puts its claims into the context
+func authenticate(ctx context.Context, log *slog.Logger, jwtSecret []byte, denylist TokenDenylist) (context.Context, error) {
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "token is invalid or missing")
+	}
+
+	claims, err := jwt.ParseToken(token, jwtSecret)
+	if err != nil {
+		log.Warn("someone trying to get access with invalid token", slog.String("token", token))
+		return nil, status.Errorf(codes.Unauthenticated, "token is invalid")
+	}
+	if claims.Type != jwt.TypeAccess {
+		log.Warn("someone trying to get access with not an access token", slog.String("typ", claims.Type))
+		return nil, status.Errorf(codes.Unauthenticated, "token is invalid")
+	}
+
+	accessClaims := domain.AccessClaims{
+		UserUuid:    claims.UserUuid,
+		SessionUuid: claims.SessionUuid,
+		Jti:         claims.Jti,
+		Expired:     claims.Expired,
+	}
+	revoked, err := denylist.IsRevoked(ctx, accessClaims)
+	if err != nil {
+		log.Error("can't check token in denylist", sl.Err(err))
+		return nil, status.Errorf(codes.Unauthenticated, "token is invalid")
+	}
+	if revoked {
+		return nil, status.Errorf(codes.Unauthenticated, "token is revoked")
+	}
+
+	return context.WithValue(ctx, domain.AccessClaimsCtxKey{}, accessClaims), nil
+}
+
+// bearerToken returns the token from the authorization metadata.
+// The http gateway passes the Authorization header there.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		scheme, credentials, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(credentials)
 		}
+	}
+	return ""
+}
 
-		return handler(ctx, req)
+// claimsFromContext returns the claims of the access token authenticated by the auth interceptors
+func claimsFromContext(ctx context.Context) (domain.AccessClaims, error) {
+	claims, ok := ctx.Value(domain.AccessClaimsCtxKey{}).(domain.AccessClaims)
+	if !ok || claims.UserUuid == uuid.Nil {
+		return domain.AccessClaims{}, status.Error(codes.Unauthenticated, "user is not authenticated")
+	}
+	return claims, nil
+}
+
+// userUuidFromContext returns the user authenticated by the auth interceptors
+func userUuidFromContext(ctx context.Context) (uuid.UUID, error) {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, err
 	}
+	return claims.UserUuid, nil
 }
@@ -0,0 +1,231 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/api/gen/chatpb"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/grpc/mocks"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// bearerForTests is the authorization metadata of the access token
+func bearerForTests(token string) metadata.MD {
+	return metadata.Pairs("authorization", "Bearer "+token)
+}
+
+// isAccessClaimsForTests matches the claims of tokensForTests.AccessToken passed to the denylist
+var isAccessClaimsForTests = mock.MatchedBy(func(claims domain.AccessClaims) bool {
+	return claims.UserUuid == userUuidForTests && claims.SessionUuid == sessionForTests && claims.Jti != uuid.Nil
+})
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return claimsFromContext(ctx)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/chatpb.Chat/NewMessage"}
+
+	tests := []struct {
+		name     string
+		req      any
+		md       metadata.MD
+		checked  bool
+		revoked  bool
+		wantCode codes.Code
+	}{
+		{
+			name:     "bearer_header",
+			req:      &chatpb.NewMessageReq{},
+			md:       bearerForTests(tokensForTests.AccessToken),
+			checked:  true,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "revoked",
+			req:      &chatpb.NewMessageReq{},
+			md:       bearerForTests(tokensForTests.AccessToken),
+			checked:  true,
+			revoked:  true,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "invalid",
+			req:      &chatpb.NewMessageReq{},
+			md:       bearerForTests("invalid token"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "refresh_token",
+			req:      &chatpb.NewMessageReq{},
+			md:       bearerForTests(tokensForTests.RefreshToken),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "not_bearer_header",
+			req:      &chatpb.NewMessageReq{},
+			md:       metadata.Pairs("authorization", "Basic "+tokensForTests.AccessToken),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "deprecated_token_field",
+			req:      &chatpb.NewMessageReq{Token: tokensForTests.AccessToken},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "missing",
+			req:      &chatpb.NewMessageReq{},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := mocks.NewAuthProvider(t)
+			if tt.checked {
+				authProvider.On("IsRevoked", mock.Anything, isAccessClaimsForTests).Return(tt.revoked, nil).Once()
+			}
+			interceptor := unaryAuthInterceptor(slog.Default(), secretTests, authProvider)
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			got, err := interceptor(ctx, tt.req, info, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("unaryAuthInterceptor() error = %v, wantCode %v", err, tt.wantCode)
+				return
+			}
+			if claims, ok := got.(domain.AccessClaims); err == nil && (!ok || claims.UserUuid != userUuidForTests || claims.SessionUuid != sessionForTests) {
+				t.Errorf("unaryAuthInterceptor() claims = %v, want user %v of session %v", got, userUuidForTests, sessionForTests)
+			}
+		})
+	}
+}
+
+type authStreamForTests struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStreamForTests) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamAuthInterceptor(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/chatpb.Chat/Subscribe", IsServerStream: true}
+	handler := func(srv any, stream grpc.ServerStream) error {
+		_, err := userUuidFromContext(stream.Context())
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		checked  bool
+		revoked  bool
+		wantCode codes.Code
+	}{
+		{
+			name:     "bearer_header",
+			md:       bearerForTests(tokensForTests.AccessToken),
+			checked:  true,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "revoked",
+			md:       bearerForTests(tokensForTests.AccessToken),
+			checked:  true,
+			revoked:  true,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "refresh_token",
+			md:       bearerForTests(tokensForTests.RefreshToken),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "missing",
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authProvider := mocks.NewAuthProvider(t)
+			if tt.checked {
+				authProvider.On("IsRevoked", mock.Anything, isAccessClaimsForTests).Return(tt.revoked, nil).Once()
+			}
+			interceptor := streamAuthInterceptor(slog.Default(), secretTests, authProvider)
+			stream := &authStreamForTests{ctx: metadata.NewIncomingContext(context.Background(), tt.md)}
+			err := interceptor(nil, stream, info, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("streamAuthInterceptor() error = %v, wantCode %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestUnaryTimeoutInterceptor(t *testing.T) {
+	methodTimeouts := map[string]time.Duration{
+		"/chatpb.Chat/ChatHistory": time.Minute,
+		"/adminpb.Admin/Purge":     0,
+	}
+	interceptor := unaryTimeoutInterceptor(time.Second, methodTimeouts)
+	handler := func(ctx context.Context, req any) (any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return time.Duration(0), nil
+		}
+		return time.Until(deadline), nil
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		want   time.Duration
+	}{
+		{name: "default", method: "/chatpb.Chat/NewMessage", want: time.Second},
+		{name: "method", method: "/chatpb.Chat/ChatHistory", want: time.Minute},
+		{name: "disabled", method: "/adminpb.Admin/Purge", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if err != nil {
+				t.Fatalf("unaryTimeoutInterceptor() error = %v", err)
+			}
+			if left := got.(time.Duration); left > tt.want || left < tt.want-time.Second/2 {
+				t.Errorf("unaryTimeoutInterceptor() deadline in %v, want %v", left, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoveryInterceptors(t *testing.T) {
+	const method = "/chatpb.Chat/RecoveryTest"
+	before := testutil.ToFloat64(panics.WithLabelValues(method))
+
+	var chat *domain.Chat
+	unary := func(ctx context.Context, req any) (any, error) {
+		return chat.Uuid, nil
+	}
+	_, err := unaryRecoveryInterceptor(slog.Default())(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, unary)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("unaryRecoveryInterceptor() error = %v, wantCode %v", err, codes.Internal)
+	}
+
+	stream := func(srv any, stream grpc.ServerStream) error {
+		panic("stream is broken")
+	}
+	err = streamRecoveryInterceptor(slog.Default())(nil, &authStreamForTests{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: method}, stream)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("streamRecoveryInterceptor() error = %v, wantCode %v", err, codes.Internal)
+	}
+
+	if got := testutil.ToFloat64(panics.WithLabelValues(method)) - before; got != 2 {
+		t.Errorf("panics = %v, want 2", got)
+	}
+}
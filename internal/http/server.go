@@ -7,10 +7,14 @@ import (
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/alexandernizov/grpcmessanger/api/gen/adminpb"
 	"github.com/alexandernizov/grpcmessanger/api/gen/authpb"
+	"github.com/alexandernizov/grpcmessanger/api/gen/chatpb"
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
 )
 
@@ -69,22 +73,31 @@ func (s *Server) Start() {
 	conn, err := grpc.NewClient(
 		s.grpcAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	)
 	if err != nil {
 		log.Error("cant connect to grpc server", sl.Err(err))
 	}
 
 	gwmux := runtime.NewServeMux()
-	// Register Greeter
+	// The Authorization header is passed to grpc as the authorization metadata
 	err = authpb.RegisterAuthHandler(context.Background(), gwmux, conn)
-	// err = helloworldpb.RegisterGreeterHandler(context.Background(), gwmux, conn)
 	if err != nil {
-		log.Error("cant register gateway", sl.Err(err))
+		log.Error("cant register auth gateway", sl.Err(err))
+	}
+	err = chatpb.RegisterChatHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Error("cant register chat gateway", sl.Err(err))
+	}
+	err = adminpb.RegisterAdminHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Error("cant register admin gateway", sl.Err(err))
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
-	mux.Handle("/", gwmux)
+	// Requests get a span, the trace goes on to grpc
+	mux.Handle("/", otelhttp.NewHandler(gwmux, "gateway"))
 
 	s.server = &http.Server{
 		Addr:    s.httpAddr,
@@ -0,0 +1,198 @@
+// Package leader elects one replica to run the work which must not run on several replicas at once,
+// like the outbox publisher and the chat reaper.
+// Replicas compete for a lock kept in the shared storage, the holder is the leader until it loses the lock.
+package leader
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+)
+
+// Lock is held by one replica at a time
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Lock
+type Lock interface {
+	// Acquire takes the lock if it's free and reports whether it's held
+	Acquire(ctx context.Context) (bool, error)
+	// Renew reports whether the lock is still held and extends it if it expires
+	Renew(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// Local is the lock of a storage which isn't shared between replicas, it's always held
+type Local struct{}
+
+func (Local) Acquire(ctx context.Context) (bool, error) { return true, nil }
+func (Local) Renew(ctx context.Context) (bool, error)   { return true, nil }
+func (Local) Release(ctx context.Context) error         { return nil }
+
+var isLeader = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "leader_is_leader",
+	Help: "1 while the replica is the leader, 0 otherwise.",
+}, []string{"id"})
+
+type Elector struct {
+	log     *slog.Logger
+	lock    Lock
+	options Options
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type Options struct {
+	// Id names the replica in logs and metrics
+	Id string
+	// The leader renews the lock every RenewInterval, the others try to take it every RetryInterval
+	RenewInterval time.Duration
+	RetryInterval time.Duration
+}
+
+const (
+	defaultRenewInterval = 5 * time.Second
+	defaultRetryInterval = 5 * time.Second
+	// lockTimeout bounds every call to the lock
+	lockTimeout = 5 * time.Second
+)
+
+// NewId makes an id of the replica unique even for replicas on one host
+func NewId() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
+func New(log *slog.Logger, lock Lock, options Options) *Elector {
+	if options.Id == "" {
+		options.Id = NewId()
+	}
+	if options.RenewInterval <= 0 {
+		options.RenewInterval = defaultRenewInterval
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = defaultRetryInterval
+	}
+	isLeader.WithLabelValues(options.Id).Set(0)
+	return &Elector{log: log.With(slog.String("leader_id", options.Id)), lock: lock, options: options, stop: make(chan struct{})}
+}
+
+// Start competes for the leadership. While the replica is the leader lead runs,
+// its context is cancelled when the leadership is lost and the leadership is given up when lead returns.
+func (e *Elector) Start(lead func(ctx context.Context)) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			if e.acquire() {
+				e.hold(lead)
+			}
+			if !e.wait(e.options.RetryInterval) {
+				return
+			}
+		}
+	}()
+}
+
+// Stop waits for lead to return and releases the lock, so another replica takes over without waiting
+func (e *Elector) Stop() {
+	close(e.stop)
+	e.wg.Wait()
+}
+
+func (e *Elector) acquire() bool {
+	const op = "leader.acquire"
+	log := e.log.With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	acquired, err := e.lock.Acquire(ctx)
+	if err != nil {
+		log.Error("can't acquire leader lock", sl.Err(err))
+		return false
+	}
+	return acquired
+}
+
+// hold runs lead and renews the lock until the leadership is lost, lead returns or the elector is stopped
+func (e *Elector) hold(lead func(ctx context.Context)) {
+	const op = "leader.hold"
+	log := e.log.With(slog.String("op", op))
+
+	log.Info("replica is elected leader")
+	isLeader.WithLabelValues(e.options.Id).Set(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(ctx)
+	}()
+
+	ticker := time.NewTicker(e.options.RenewInterval)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-e.stop:
+			log.Info("leader is stopping")
+			break loop
+		case <-done:
+			log.Warn("leader work has stopped, leadership is given up")
+			break loop
+		case <-ticker.C:
+			if !e.renew() {
+				log.Warn("leadership is lost")
+				break loop
+			}
+		}
+	}
+
+	cancel()
+	<-done
+	isLeader.WithLabelValues(e.options.Id).Set(0)
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer releaseCancel()
+	if err := e.lock.Release(releaseCtx); err != nil {
+		log.Error("can't release leader lock", sl.Err(err))
+	}
+}
+
+// renew reports false when the lock is lost or can't be checked, the replica can't be sure it's still the leader then
+func (e *Elector) renew() bool {
+	const op = "leader.renew"
+	log := e.log.With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	held, err := e.lock.Renew(ctx)
+	if err != nil {
+		log.Error("can't renew leader lock", sl.Err(err))
+		return false
+	}
+	return held
+}
+
+// wait sleeps for the delay and reports false if the elector was stopped meanwhile
+func (e *Elector) wait(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-e.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
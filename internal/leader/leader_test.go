@@ -0,0 +1,119 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/alexandernizov/grpcmessanger/internal/leader/mocks"
+)
+
+// waitFor fails the test if nothing is received from ch in a second
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("%s didn't happen", what)
+	}
+}
+
+func TestElector(t *testing.T) {
+	tests := []struct {
+		name string
+		// renewed is what Renew returns to the leader
+		renewed []any
+	}{
+		{
+			name:    "lease_lost",
+			renewed: []any{false, nil},
+		},
+		{
+			name:    "renew_error",
+			renewed: []any{false, errors.New("some error")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := mocks.NewLock(t)
+			lock.On("Acquire", mock.Anything).Return(true, nil).Once()
+			lock.On("Renew", mock.Anything).Return(true, nil).Once()
+			lock.On("Renew", mock.Anything).Return(tt.renewed...).Once()
+			lock.On("Release", mock.Anything).Return(nil).Once()
+			// Another replica is the leader afterwards
+			lock.On("Acquire", mock.Anything).Return(false, nil)
+
+			e := New(slog.Default(), lock, Options{Id: t.Name(), RenewInterval: time.Millisecond, RetryInterval: time.Millisecond})
+			elected, demoted := make(chan struct{}), make(chan struct{})
+			e.Start(func(ctx context.Context) {
+				close(elected)
+				assert.Equal(t, float64(1), testutil.ToFloat64(isLeader.WithLabelValues(t.Name())))
+				<-ctx.Done()
+				close(demoted)
+			})
+
+			waitFor(t, elected, "election")
+			waitFor(t, demoted, "demotion")
+			e.Stop()
+			assert.Equal(t, float64(0), testutil.ToFloat64(isLeader.WithLabelValues(t.Name())))
+		})
+	}
+}
+
+func TestElector_Follower(t *testing.T) {
+	lock := mocks.NewLock(t)
+	lock.On("Acquire", mock.Anything).Return(false, nil).Once()
+	lock.On("Acquire", mock.Anything).Return(false, errors.New("some error"))
+
+	e := New(slog.Default(), lock, Options{RetryInterval: time.Millisecond})
+	e.Start(func(ctx context.Context) {
+		t.Error("follower runs the leader work")
+	})
+	time.Sleep(20 * time.Millisecond)
+	e.Stop()
+}
+
+func TestElector_Stop(t *testing.T) {
+	lock := mocks.NewLock(t)
+	lock.On("Acquire", mock.Anything).Return(true, nil).Once()
+	lock.On("Renew", mock.Anything).Return(true, nil).Maybe()
+	lock.On("Release", mock.Anything).Return(nil).Once()
+
+	e := New(slog.Default(), lock, Options{RenewInterval: time.Millisecond})
+	elected, stopped := make(chan struct{}), make(chan struct{})
+	e.Start(func(ctx context.Context) {
+		close(elected)
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	// The lock is released after the leader work has stopped
+	waitFor(t, elected, "election")
+	e.Stop()
+	waitFor(t, stopped, "stop of the leader work")
+}
+
+func TestElector_LeadReturns(t *testing.T) {
+	lock := mocks.NewLock(t)
+	lock.On("Acquire", mock.Anything).Return(true, nil).Twice()
+	lock.On("Renew", mock.Anything).Return(true, nil).Maybe()
+	lock.On("Release", mock.Anything).Return(nil).Twice()
+	lock.On("Acquire", mock.Anything).Return(false, nil)
+
+	// The leadership is given up when the work fails and taken again later
+	e := New(slog.Default(), lock, Options{RenewInterval: time.Hour, RetryInterval: time.Millisecond})
+	runs := make(chan struct{}, 2)
+	e.Start(func(ctx context.Context) {
+		runs <- struct{}{}
+	})
+	waitFor(t, runs, "first run")
+	waitFor(t, runs, "second run")
+	time.Sleep(10 * time.Millisecond)
+	e.Stop()
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+)
+
+// FileSink appends outbox messages to a local file, one json object per line
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// FileRecord is a line of the file sink, the message is base64 encoded
+type FileRecord struct {
+	Uuid          string    `json:"uuid"`
+	Topic         string    `json:"topic"`
+	ChatUuid      string    `json:"chat_uuid"`
+	Seq           int64     `json:"seq"`
+	SchemaVersion int       `json:"schema_version"`
+	Message       []byte    `json:"message"`
+	Sent          time.Time `json:"sent"`
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (f *FileSink) Send(ctx context.Context, msg *domain.Outbox) error {
+	line, err := json.Marshal(FileRecord{
+		Uuid:          msg.Uuid.String(),
+		Topic:         msg.Topic,
+		ChatUuid:      msg.ChatUuid.String(),
+		Seq:           msg.Seq,
+		SchemaVersion: msg.SchemaVersion,
+		Message:       msg.Message,
+		Sent:          time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.Write(append(line, '\n'))
+	return err
+}
+
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
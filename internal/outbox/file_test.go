@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	batch := outboxBatch(2)
+
+	// Records are appended to the existing file
+	for _, msg := range batch {
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Send(context.Background(), msg))
+		require.NoError(t, sink.Close())
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []FileRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record FileRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, records, 2)
+	for i, record := range records {
+		assert.Equal(t, batch[i].Uuid.String(), record.Uuid)
+		assert.Equal(t, domain.MessageTopic, record.Topic)
+		assert.Equal(t, batch[i].ChatUuid.String(), record.ChatUuid)
+		assert.Equal(t, int64(i+1), record.Seq)
+		assert.Equal(t, batch[i].Message, record.Message)
+	}
+}
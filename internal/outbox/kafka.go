@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/IBM/sarama"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
+)
+
+// Headers of kafka messages
+const (
+	UuidHeader     = "outbox-uuid"
+	ChatUuidHeader = "chat-uuid"
+	// SeqHeader is the sequence number of the event within its chat. Events of a chat are sent in order
+	// unless one is dead-lettered, a replayed one comes after the later events, so order by seq rather than by offset.
+	SeqHeader = "seq"
+	// SchemaVersionHeader carries the version of the event envelope, see SchemaVersion in outbox.proto
+	SchemaVersionHeader = "schema-version"
+)
+
+// KafkaSink produces outbox messages into the kafka topics named after their outbox topic.
+// Messages are keyed by their chat, so events of a chat land in one partition in order.
+type KafkaSink struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaSink(brokers []string) (*KafkaSink, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("can't start sarama producer: %w", ErrNoConnection)
+	}
+	return NewKafkaSinkWithProducer(producer), nil
+}
+
+func NewKafkaSinkWithProducer(producer sarama.SyncProducer) *KafkaSink {
+	return &KafkaSink{producer: producer}
+}
+
+// Send adds the trace context of ctx to the headers, e.g. traceparent
+func (k *KafkaSink) Send(ctx context.Context, msg *domain.Outbox) error {
+	headers := []sarama.RecordHeader{
+		{Key: []byte(UuidHeader), Value: []byte(msg.Uuid.String())},
+		{Key: []byte(ChatUuidHeader), Value: []byte(msg.ChatUuid.String())},
+		{Key: []byte(SeqHeader), Value: []byte(strconv.FormatInt(msg.Seq, 10))},
+		{Key: []byte(SchemaVersionHeader), Value: []byte(strconv.Itoa(msg.SchemaVersion))},
+	}
+	for key, value := range tracing.Inject(ctx) {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	_, _, err := k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   msg.Topic,
+		Key:     sarama.StringEncoder(orderKey(msg).String()),
+		Value:   sarama.ByteEncoder(msg.Message),
+		Headers: headers,
+	})
+	return err
+}
+
+func (k *KafkaSink) Close() error {
+	return k.producer.Close()
+}
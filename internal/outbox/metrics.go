@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name BacklogProvider
+type BacklogProvider interface {
+	OutboxBacklog(ctx context.Context) (*domain.OutboxBacklog, error)
+}
+
+var (
+	prunedOutboxes = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_pruned_total",
+		Help: "Sent outbox records deleted by the retention.",
+	})
+	publishedOutboxes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_published_total",
+		Help: "Outbox messages sent and confirmed.",
+	}, []string{"topic"})
+	failedOutboxes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_publish_failures_total",
+		Help: "Attempts to send an outbox message which failed.",
+	}, []string{"topic"})
+	deadLetteredOutboxes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_dead_lettered_total",
+		Help: "Outbox messages moved to the dead letters.",
+	}, []string{"topic"})
+)
+
+var (
+	backlogSizeDesc = prometheus.NewDesc("outbox_backlog_size",
+		"Outbox records waiting to be published.", nil, nil)
+	oldestUnsentAgeDesc = prometheus.NewDesc("outbox_oldest_unsent_age_seconds",
+		"How long the oldest unsent outbox record has been waiting.", nil, nil)
+)
+
+// backlogTimeout bounds the storage query made on every scrape
+const backlogTimeout = 5 * time.Second
+
+// BacklogCollector reports the outbox backlog read from storage when metrics are scraped
+type BacklogCollector struct {
+	log     *slog.Logger
+	backlog BacklogProvider
+}
+
+func NewBacklogCollector(log *slog.Logger, backlog BacklogProvider) *BacklogCollector {
+	return &BacklogCollector{log: log, backlog: backlog}
+}
+
+func (c *BacklogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- backlogSizeDesc
+	ch <- oldestUnsentAgeDesc
+}
+
+// Collect reports nothing if the storage can't be read, so the gauges don't drop to zero
+func (c *BacklogCollector) Collect(ch chan<- prometheus.Metric) {
+	const op = "outbox.Collect"
+	log := c.log.With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(context.Background(), backlogTimeout)
+	defer cancel()
+	backlog, err := c.backlog.OutboxBacklog(ctx)
+	if err != nil {
+		log.Error("can't read outbox backlog", sl.Err(err))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(backlogSizeDesc, prometheus.GaugeValue, float64(backlog.Unsent))
+	ch <- prometheus.MustNewConstMetric(oldestUnsentAgeDesc, prometheus.GaugeValue, backlog.OldestAge.Seconds())
+}
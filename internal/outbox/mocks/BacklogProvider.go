@@ -0,0 +1,56 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/alexandernizov/grpcmessanger/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BacklogProvider is an autogenerated mock type for the BacklogProvider type
+type BacklogProvider struct {
+	mock.Mock
+}
+
+// OutboxBacklog provides a mock function with given fields: ctx
+func (_m *BacklogProvider) OutboxBacklog(ctx context.Context) (*domain.OutboxBacklog, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *domain.OutboxBacklog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.OutboxBacklog, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.OutboxBacklog); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OutboxBacklog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewBacklogProvider interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewBacklogProvider creates a new instance of BacklogProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewBacklogProvider(t mockConstructorTestingTNewBacklogProvider) *BacklogProvider {
+	mock := &BacklogProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,112 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/alexandernizov/grpcmessanger/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// OutboxProvider is an autogenerated mock type for the OutboxProvider type
+type OutboxProvider struct {
+	mock.Mock
+}
+
+// ClaimOutboxBatch provides a mock function with given fields: ctx, limit, lease
+func (_m *OutboxProvider) ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.Outbox, error) {
+	ret := _m.Called(ctx, limit, lease)
+
+	var r0 []*domain.Outbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]*domain.Outbox, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []*domain.Outbox); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Outbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmOutboxSended provides a mock function with given fields: ctx, outboxUuid
+func (_m *OutboxProvider) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
+	ret := _m.Called(ctx, outboxUuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, outboxUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FailOutbox provides a mock function with given fields: ctx, outboxUuid, lastError, maxAttempts
+func (_m *OutboxProvider) FailOutbox(ctx context.Context, outboxUuid uuid.UUID, lastError string, maxAttempts int) (bool, error) {
+	ret := _m.Called(ctx, outboxUuid, lastError, maxAttempts)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int) (bool, error)); ok {
+		return rf(ctx, outboxUuid, lastError, maxAttempts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int) bool); ok {
+		r0 = rf(ctx, outboxUuid, lastError, maxAttempts)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, int) error); ok {
+		r1 = rf(ctx, outboxUuid, lastError, maxAttempts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseOutbox provides a mock function with given fields: ctx, outboxUuids
+func (_m *OutboxProvider) ReleaseOutbox(ctx context.Context, outboxUuids []uuid.UUID) error {
+	ret := _m.Called(ctx, outboxUuids)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) error); ok {
+		r0 = rf(ctx, outboxUuids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewOutboxProvider interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOutboxProvider creates a new instance of OutboxProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOutboxProvider(t mockConstructorTestingTNewOutboxProvider) *OutboxProvider {
+	mock := &OutboxProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SentOutboxStorage is an autogenerated mock type for the SentOutboxStorage type
+type SentOutboxStorage struct {
+	mock.Mock
+}
+
+// DeleteSentOutbox provides a mock function with given fields: ctx, olderThan, limit
+func (_m *SentOutboxStorage) DeleteSentOutbox(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	ret := _m.Called(ctx, olderThan, limit)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, int) (int, error)); ok {
+		return rf(ctx, olderThan, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, int) int); ok {
+		r0 = rf(ctx, olderThan, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration, int) error); ok {
+		r1 = rf(ctx, olderThan, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSentOutboxStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSentOutboxStorage creates a new instance of SentOutboxStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSentOutboxStorage(t mockConstructorTestingTNewSentOutboxStorage) *SentOutboxStorage {
+	mock := &SentOutboxStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
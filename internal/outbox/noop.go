@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+)
+
+// NoopSink drops outbox messages, it's for running without any consumers
+type NoopSink struct {
+	log *slog.Logger
+}
+
+func NewNoopSink(log *slog.Logger) *NoopSink {
+	return &NoopSink{log: log}
+}
+
+func (n *NoopSink) Send(ctx context.Context, msg *domain.Outbox) error {
+	n.log.Debug("outbox message is dropped", slog.String("uuid", msg.Uuid.String()), slog.String("topic", msg.Topic))
+	return nil
+}
+
+func (n *NoopSink) Close() error {
+	return nil
+}
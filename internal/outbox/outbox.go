@@ -3,87 +3,286 @@ package outbox
 import (
 	"context"
 	"errors"
-	"fmt"
+	"hash/fnv"
 	"log/slog"
+	"sync"
+	"time"
 
-	"github.com/IBM/sarama"
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
-	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/google/uuid"
 )
 
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name OutboxProvider
 type OutboxProvider interface {
-	GetNextOutbox(ctx context.Context) (*domain.Outbox, error)
+	// ClaimOutboxBatch returns up to limit oldest unsent outboxes, other publishers don't get them
+	// until they are confirmed, failed, released or the lease is over
+	ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.Outbox, error)
+	ReleaseOutbox(ctx context.Context, outboxUuids []uuid.UUID) error
 	ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error
+	// FailOutbox records a failed attempt, after maxAttempts the outbox is moved to the dead letters
+	FailOutbox(ctx context.Context, outboxUuid uuid.UUID, lastError string, maxAttempts int) (bool, error)
+}
+
+// Sink delivers outbox messages to their consumers
+type Sink interface {
+	Send(ctx context.Context, msg *domain.Outbox) error
+	Close() error
 }
 
 type Publisher struct {
-	log      *slog.Logger
-	producer sarama.SyncProducer
-	outbox   OutboxProvider
-	stopChan chan struct{}
+	log     *slog.Logger
+	sink    Sink
+	outbox  OutboxProvider
+	options Options
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type Options struct {
+	BatchSize int
+	// The outbox is polled every MinInterval, while it's empty or the sink fails the interval doubles up to MaxInterval
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// MaxAttempts is how many times a message is sent before it's moved to the dead letters.
+	// Attempts are kept in storage, so they are counted across polls and restarts.
+	MaxAttempts int
+	// ClaimLease is how long a claimed batch is hidden from other publishers,
+	// it should be longer than the batch takes to be sent
+	ClaimLease time.Duration
+	// Workers send a batch concurrently, all messages of a chat are sent by the same worker
+	Workers int
 }
 
+const (
+	defaultBatchSize   = 100
+	defaultMinInterval = 100 * time.Millisecond
+	defaultMaxInterval = 5 * time.Second
+	defaultMaxAttempts = 5
+	defaultClaimLease  = 30 * time.Second
+	defaultWorkers     = 4
+)
+
 var (
-	ErrNoConnection = errors.New("can't establish connection to kafka")
+	ErrNoConnection = errors.New("can't establish connection to sink")
 	ErrInternal     = errors.New("internal error")
+	ErrStopped      = errors.New("publisher is stopped")
 )
 
-func New(log *slog.Logger, outboxProvider OutboxProvider, brokers []string) (*Publisher, error) {
-	config := sarama.NewConfig()
-	config.Producer.RequiredAcks = sarama.WaitForAll
-	config.Producer.Retry.Max = 5
-	config.Producer.Return.Successes = true
-
-	producer, err := sarama.NewSyncProducer(brokers, config)
-	if err != nil {
-		return nil, fmt.Errorf("can't start sarama producer: %w", ErrNoConnection)
+func New(log *slog.Logger, outboxProvider OutboxProvider, sink Sink, options Options) *Publisher {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.MinInterval <= 0 {
+		options.MinInterval = defaultMinInterval
+	}
+	if options.MaxInterval < options.MinInterval {
+		options.MaxInterval = max(defaultMaxInterval, options.MinInterval)
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
 	}
-	return &Publisher{log: log, producer: producer, outbox: outboxProvider}, nil
+	if options.ClaimLease <= 0 {
+		options.ClaimLease = defaultClaimLease
+	}
+	if options.Workers <= 0 {
+		options.Workers = defaultWorkers
+	}
+	return &Publisher{log: log, sink: sink, outbox: outboxProvider, options: options, stop: make(chan struct{})}
 }
 
 func (p *Publisher) Start() {
-	const op = "publisher.ProduceMessage"
-	log := p.log.With(slog.String("op", op))
-
-	ctx := context.Background()
+	p.wg.Add(1)
 	go func() {
+		defer p.wg.Done()
+
+		ctx := context.Background()
+		interval := p.options.MinInterval
 		for {
-			select {
-			case <-p.stopChan:
-				p.producer.Close()
+			sent, err := p.Publish(ctx)
+			if errors.Is(err, ErrStopped) {
 				return
+			}
+
+			var delay time.Duration
+			switch {
+			case sent == p.options.BatchSize && err == nil:
+				// A full batch, there are probably more waiting
+				interval = p.options.MinInterval
+			case sent > 0 && err == nil:
+				interval = p.options.MinInterval
+				delay = interval
 			default:
-				nextOutbox, err := p.outbox.GetNextOutbox(ctx)
-				if err != nil {
-					if errors.Is(err, storage.ErrNoOutbox) {
-						continue
-					}
-					log.Error("error with getting next outbox message", sl.Err(err))
-					//TODO: write it to deadqueue
-				}
-				if nextOutbox != nil {
-					msg := &sarama.ProducerMessage{
-						Topic: nextOutbox.Topic,
-						Key:   sarama.StringEncoder(nextOutbox.Uuid.String()),
-						Value: sarama.ByteEncoder(nextOutbox.Message),
-					}
-					_, _, err := p.producer.SendMessage(msg)
-					if err != nil {
-						//TODO: write it to deadqueue
-						log.Error("error with producing outbox message", sl.Err(err))
-					}
-					err = p.outbox.ConfirmOutboxSended(ctx, nextOutbox.Uuid)
-					if err != nil {
-						log.Error("error to confirm message", sl.Err(err))
-					}
-				}
+				delay = interval
+				interval = min(interval*2, p.options.MaxInterval)
+			}
+			if !p.wait(delay) {
+				return
 			}
 		}
 	}()
 }
 
+// Stop waits for the message being sent and closes the sink
 func (p *Publisher) Stop() {
-	p.stopChan <- struct{}{}
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		p.wg.Wait()
+		if err := p.sink.Close(); err != nil {
+			p.log.Error("error with closing sink", sl.Err(err))
+		}
+	})
+}
+
+// Publish claims one batch of the outbox, sends it and returns how many messages were sent.
+// The batch is split between workers by chat. Messages of a chat are sent in order, so a chat stops
+// at the first message which can't be sent unless the message has run out of attempts
+// and is moved to the dead letters, other chats go on.
+// The unsent rest of a chat is released for the next poll.
+// A chat isn't blocked by its dead letters: later events of the chat are published after one is dead-lettered,
+// and a replayed dead letter is published after them, so consumers which need the order reorder events by seq.
+func (p *Publisher) Publish(ctx context.Context) (int, error) {
+	const op = "publisher.Publish"
+	log := p.log.With(slog.String("op", op))
+
+	batch, err := p.outbox.ClaimOutboxBatch(ctx, p.options.BatchSize, p.options.ClaimLease)
+	if err != nil {
+		log.Error("error with claiming outbox batch", sl.Err(err))
+		return 0, err
+	}
+
+	parts := make([][]*domain.Outbox, p.options.Workers)
+	for _, next := range batch {
+		i := worker(next, p.options.Workers)
+		parts[i] = append(parts[i], next)
+	}
+
+	sent := make([]int, len(parts))
+	errs := make([]error, len(parts))
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sent[i], errs[i] = p.publishChats(ctx, log, part)
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, v := range sent {
+		total += v
+	}
+	return total, errors.Join(errs...)
+}
+
+// publishChats sends messages one by one, a chat is skipped after its first message which isn't sent
+func (p *Publisher) publishChats(ctx context.Context, log *slog.Logger, messages []*domain.Outbox) (int, error) {
+	sent := 0
+	var errs []error
+	var rest []*domain.Outbox
+	stopped := make(map[uuid.UUID]bool)
+	for i, next := range messages {
+		select {
+		case <-p.stop:
+			p.release(ctx, append(rest, messages[i:]...))
+			return sent, errors.Join(append(errs, ErrStopped)...)
+		default:
+		}
+
+		key := orderKey(next)
+		if stopped[key] {
+			rest = append(rest, next)
+			continue
+		}
+
+		if err := p.send(ctx, next); err != nil {
+			failedOutboxes.WithLabelValues(next.Topic).Inc()
+			deadLettered, failErr := p.outbox.FailOutbox(ctx, next.Uuid, err.Error(), p.options.MaxAttempts)
+			if failErr != nil {
+				log.Error("error to record failed attempt", slog.String("uuid", next.Uuid.String()), sl.Err(failErr))
+				stopped[key] = true
+				rest = append(rest, next)
+				errs = append(errs, failErr)
+				continue
+			}
+			if !deadLettered {
+				log.Warn("error with producing outbox message", slog.String("uuid", next.Uuid.String()), slog.Int("attempt", next.Attempts+1), sl.Err(err))
+				stopped[key] = true
+				errs = append(errs, err)
+				continue
+			}
+			deadLetteredOutboxes.WithLabelValues(next.Topic).Inc()
+			log.Error("outbox message is moved to dead letters", slog.String("uuid", next.Uuid.String()), sl.Err(err))
+			continue
+		}
+		// Unconfirmed messages are sent again, consumers see them at least once
+		if err := p.outbox.ConfirmOutboxSended(ctx, next.Uuid); err != nil {
+			log.Error("error to confirm message", slog.String("uuid", next.Uuid.String()), sl.Err(err))
+			stopped[key] = true
+			rest = append(rest, next)
+			errs = append(errs, err)
+			continue
+		}
+		publishedOutboxes.WithLabelValues(next.Topic).Inc()
+		sent++
+	}
+	p.release(ctx, rest)
+	return sent, errors.Join(errs...)
+}
+
+// orderKey is what messages are ordered by, outboxes added before they had chats are ordered by themselves
+func orderKey(msg *domain.Outbox) uuid.UUID {
+	if msg.ChatUuid == uuid.Nil {
+		return msg.Uuid
+	}
+	return msg.ChatUuid
+}
+
+// worker picks the worker for the message by its order key
+func worker(msg *domain.Outbox, workers int) int {
+	hash := fnv.New32a()
+	key := orderKey(msg)
+	hash.Write(key[:])
+	return int(hash.Sum32() % uint32(workers))
+}
+
+// release returns the unsent rest of the batch, otherwise it waits for the end of the lease
+func (p *Publisher) release(ctx context.Context, rest []*domain.Outbox) {
+	if len(rest) == 0 {
+		return
+	}
+	outboxUuids := make([]uuid.UUID, len(rest))
+	for i, v := range rest {
+		outboxUuids[i] = v.Uuid
+	}
+	if err := p.outbox.ReleaseOutbox(ctx, outboxUuids); err != nil {
+		p.log.Error("error to release outbox", sl.Err(err))
+	}
+}
+
+// wait sleeps for the delay and reports false if the publisher was stopped meanwhile
+func (p *Publisher) wait(delay time.Duration) bool {
+	if delay <= 0 {
+		select {
+		case <-p.stop:
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-p.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
 }
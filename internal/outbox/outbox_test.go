@@ -0,0 +1,287 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/outbox/mocks"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// outboxBatch returns n events of one chat
+func outboxBatch(n int) []*domain.Outbox {
+	chatUuid := uuid.New()
+	res := make([]*domain.Outbox, n)
+	for i := range res {
+		res[i] = &domain.Outbox{Uuid: uuid.New(), ChatUuid: chatUuid, Seq: int64(i + 1), Topic: domain.MessageTopic, Message: []byte("message")}
+	}
+	return res
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	errKafka := errors.New("kafka is down")
+
+	tests := []struct {
+		name     string
+		batch    []*domain.Outbox
+		batchErr error
+		sends    []error
+		// failed are positions of the messages which failed, the value is whether they are dead lettered
+		failed    map[int]bool
+		confirmed []int
+		released  []int
+		wantSent  int
+		wantErr   bool
+	}{
+		{
+			name:  "empty",
+			batch: nil,
+		},
+		{
+			name:      "all_sent",
+			batch:     outboxBatch(3),
+			sends:     []error{nil, nil, nil},
+			confirmed: []int{0, 1, 2},
+			wantSent:  3,
+		},
+		{
+			name:      "failed",
+			batch:     outboxBatch(3),
+			sends:     []error{nil, errKafka},
+			failed:    map[int]bool{1: false},
+			confirmed: []int{0},
+			released:  []int{2},
+			wantSent:  1,
+			wantErr:   true,
+		},
+		{
+			name:      "dead_lettered",
+			batch:     outboxBatch(3),
+			sends:     []error{errKafka, nil, nil},
+			failed:    map[int]bool{0: true},
+			confirmed: []int{1, 2},
+			wantSent:  2,
+		},
+		{
+			name:     "storage_error",
+			batchErr: errors.New("some error"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewOutboxProvider(t)
+			storage.On("ClaimOutboxBatch", mock.Anything, 10, defaultClaimLease).Return(tt.batch, tt.batchErr).Once()
+			for _, i := range tt.confirmed {
+				storage.On("ConfirmOutboxSended", mock.Anything, tt.batch[i].Uuid).Return(nil).Once()
+			}
+			for i, deadLettered := range tt.failed {
+				storage.On("FailOutbox", mock.Anything, tt.batch[i].Uuid, errKafka.Error(), 3).Return(deadLettered, nil).Once()
+			}
+			if len(tt.released) > 0 {
+				var released []uuid.UUID
+				for _, i := range tt.released {
+					released = append(released, tt.batch[i].Uuid)
+				}
+				storage.On("ReleaseOutbox", mock.Anything, released).Return(nil).Once()
+			}
+
+			producer := saramamocks.NewSyncProducer(t, nil)
+			for _, err := range tt.sends {
+				if err != nil {
+					producer.ExpectSendMessageAndFail(err)
+				} else {
+					producer.ExpectSendMessageAndSucceed()
+				}
+			}
+
+			p := New(slog.Default(), storage, NewKafkaSinkWithProducer(producer), Options{BatchSize: 10, MinInterval: time.Millisecond, MaxAttempts: 3})
+			defer p.Stop()
+
+			published := testutil.ToFloat64(publishedOutboxes.WithLabelValues(domain.MessageTopic))
+			failures := testutil.ToFloat64(failedOutboxes.WithLabelValues(domain.MessageTopic))
+			deadLettered := testutil.ToFloat64(deadLetteredOutboxes.WithLabelValues(domain.MessageTopic))
+
+			sent, err := p.Publish(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Publisher.Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantSent, sent)
+
+			wantDeadLettered := 0
+			for _, dead := range tt.failed {
+				if dead {
+					wantDeadLettered++
+				}
+			}
+			assert.Equal(t, float64(tt.wantSent), testutil.ToFloat64(publishedOutboxes.WithLabelValues(domain.MessageTopic))-published)
+			assert.Equal(t, float64(len(tt.failed)), testutil.ToFloat64(failedOutboxes.WithLabelValues(domain.MessageTopic))-failures)
+			assert.Equal(t, float64(wantDeadLettered), testutil.ToFloat64(deadLetteredOutboxes.WithLabelValues(domain.MessageTopic))-deadLettered)
+		})
+	}
+}
+
+func TestPublisher_FailError(t *testing.T) {
+	batch := outboxBatch(2)
+	storage := mocks.NewOutboxProvider(t)
+	storage.On("ClaimOutboxBatch", mock.Anything, 10, defaultClaimLease).Return(batch, nil).Once()
+	storage.On("FailOutbox", mock.Anything, batch[0].Uuid, mock.Anything, defaultMaxAttempts).Return(false, errors.New("some error")).Once()
+	storage.On("ReleaseOutbox", mock.Anything, []uuid.UUID{batch[0].Uuid, batch[1].Uuid}).Return(nil).Once()
+
+	// The rest of the batch waits until the attempt is recorded
+	producer := saramamocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndFail(errors.New("kafka is down"))
+
+	p := New(slog.Default(), storage, NewKafkaSinkWithProducer(producer), Options{BatchSize: 10})
+	defer p.Stop()
+
+	sent, err := p.Publish(context.Background())
+	assert.Error(t, err)
+	assert.Zero(t, sent)
+}
+
+func TestPublisher_ConfirmError(t *testing.T) {
+	batch := outboxBatch(2)
+	storage := mocks.NewOutboxProvider(t)
+	storage.On("ClaimOutboxBatch", mock.Anything, 10, defaultClaimLease).Return(batch, nil).Once()
+	storage.On("ConfirmOutboxSended", mock.Anything, batch[0].Uuid).Return(errors.New("some error")).Once()
+	storage.On("ReleaseOutbox", mock.Anything, []uuid.UUID{batch[0].Uuid, batch[1].Uuid}).Return(nil).Once()
+
+	// The message is left in the outbox and will be sent again
+	producer := saramamocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, domain.MessageTopic, msg.Topic)
+		// Events of a chat go to one partition
+		key, _ := msg.Key.Encode()
+		assert.Equal(t, batch[0].ChatUuid.String(), string(key))
+		return nil
+	})
+
+	p := New(slog.Default(), storage, NewKafkaSinkWithProducer(producer), Options{BatchSize: 10})
+	defer p.Stop()
+
+	sent, err := p.Publish(context.Background())
+	assert.Error(t, err)
+	assert.Zero(t, sent)
+}
+
+func TestPublisher_TraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	batch := outboxBatch(1)
+	batch[0].TraceContext = map[string]string{"traceparent": traceparent}
+	storage := mocks.NewOutboxProvider(t)
+	storage.On("ClaimOutboxBatch", mock.Anything, 10, defaultClaimLease).Return(batch, nil).Once()
+	storage.On("ConfirmOutboxSended", mock.Anything, batch[0].Uuid).Return(nil).Once()
+
+	// The trace of the change which added the outbox goes on in the kafka headers
+	producer := saramamocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		headers := make(map[string]string)
+		for _, v := range msg.Headers {
+			headers[string(v.Key)] = string(v.Value)
+		}
+		assert.Contains(t, headers["traceparent"], "4bf92f3577b34da6a3ce929d0e0e4736")
+		assert.Equal(t, batch[0].Uuid.String(), headers[UuidHeader])
+		return nil
+	})
+
+	p := New(slog.Default(), storage, NewKafkaSinkWithProducer(producer), Options{BatchSize: 10})
+	defer p.Stop()
+
+	sent, err := p.Publish(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+// chatSink fails the messages in fail and records the sent ones by chat
+type chatSink struct {
+	mu   sync.Mutex
+	fail map[uuid.UUID]error
+	sent map[uuid.UUID][]int64
+}
+
+func (c *chatSink) Send(ctx context.Context, msg *domain.Outbox) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fail[msg.Uuid]; err != nil {
+		return err
+	}
+	c.sent[msg.ChatUuid] = append(c.sent[msg.ChatUuid], msg.Seq)
+	return nil
+}
+
+func (c *chatSink) Close() error {
+	return nil
+}
+
+func TestPublisher_Chats(t *testing.T) {
+	// Events of both chats are interleaved as they were created
+	first, second := outboxBatch(3), outboxBatch(3)
+	var batch []*domain.Outbox
+	for i := range first {
+		batch = append(batch, first[i], second[i])
+	}
+
+	errKafka := errors.New("kafka is down")
+	storage := mocks.NewOutboxProvider(t)
+	storage.On("ClaimOutboxBatch", mock.Anything, 10, defaultClaimLease).Return(batch, nil).Once()
+	storage.On("FailOutbox", mock.Anything, first[1].Uuid, errKafka.Error(), defaultMaxAttempts).Return(false, nil).Once()
+	storage.On("ReleaseOutbox", mock.Anything, []uuid.UUID{first[2].Uuid}).Return(nil).Once()
+	for _, v := range append([]*domain.Outbox{first[0]}, second...) {
+		storage.On("ConfirmOutboxSended", mock.Anything, v.Uuid).Return(nil).Once()
+	}
+
+	// The first chat stops at the failed message, the second one is sent in full and in order
+	sink := &chatSink{fail: map[uuid.UUID]error{first[1].Uuid: errKafka}, sent: make(map[uuid.UUID][]int64)}
+	p := New(slog.Default(), storage, sink, Options{BatchSize: 10, Workers: 2})
+	defer p.Stop()
+
+	sent, err := p.Publish(context.Background())
+	assert.ErrorIs(t, err, errKafka)
+	assert.Equal(t, 4, sent)
+	assert.Equal(t, []int64{1}, sink.sent[first[0].ChatUuid])
+	assert.Equal(t, []int64{1, 2, 3}, sink.sent[second[0].ChatUuid])
+}
+
+func TestPublisher_StartStop(t *testing.T) {
+	storage := mocks.NewOutboxProvider(t)
+	polled := make(chan struct{}, 1)
+	storage.On("ClaimOutboxBatch", mock.Anything, 10, defaultClaimLease).Return(nil, nil).Run(func(args mock.Arguments) {
+		select {
+		case polled <- struct{}{}:
+		default:
+		}
+	})
+
+	producer := saramamocks.NewSyncProducer(t, nil)
+	p := New(slog.Default(), storage, NewKafkaSinkWithProducer(producer), Options{BatchSize: 10, MinInterval: time.Millisecond, MaxInterval: time.Hour})
+	p.Start()
+	<-polled
+
+	// Stop doesn't wait for the idle backoff and may be called twice
+	done := make(chan struct{})
+	go func() {
+		p.Stop()
+		p.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publisher.Stop() is blocked")
+	}
+}
@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name SentOutboxStorage
+type SentOutboxStorage interface {
+	// DeleteSentOutbox deletes up to limit outboxes sent more than olderThan ago and returns how many were deleted
+	DeleteSentOutbox(ctx context.Context, olderThan time.Duration, limit int) (int, error)
+}
+
+type RetentionOptions struct {
+	// Sent outboxes older than MaxAge are deleted every Interval, BatchSize at a time
+	MaxAge    time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+const (
+	defaultRetentionMaxAge    = 24 * time.Hour
+	defaultRetentionInterval  = time.Minute
+	defaultRetentionBatchSize = 1000
+)
+
+// Retention periodically deletes old sent outboxes.
+// Storages that delete outboxes once they are sent, like inmemory and redis, don't need it.
+type Retention struct {
+	log     *slog.Logger
+	storage SentOutboxStorage
+	options RetentionOptions
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewRetention(log *slog.Logger, storage SentOutboxStorage, options RetentionOptions) *Retention {
+	if options.MaxAge <= 0 {
+		options.MaxAge = defaultRetentionMaxAge
+	}
+	if options.Interval <= 0 {
+		options.Interval = defaultRetentionInterval
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultRetentionBatchSize
+	}
+	return &Retention{log: log, storage: storage, options: options, stop: make(chan struct{})}
+}
+
+func (r *Retention) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.Prune(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop waits for the running pass to finish
+func (r *Retention) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// Prune deletes old sent outboxes batch by batch until none are left and returns how many were deleted.
+// Every batch is a separate transaction, so a long pass doesn't hold the table.
+func (r *Retention) Prune(ctx context.Context) int {
+	const op = "outbox.Prune"
+	log := r.log.With(slog.String("op", op))
+
+	total := 0
+	for {
+		select {
+		case <-r.stop:
+			return total
+		default:
+		}
+
+		deleted, err := r.storage.DeleteSentOutbox(ctx, r.options.MaxAge, r.options.BatchSize)
+		if err != nil {
+			log.Error("can't delete sent outboxes", sl.Err(err))
+			return total
+		}
+		total += deleted
+		prunedOutboxes.Add(float64(deleted))
+		if deleted < r.options.BatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Info("sent outboxes deleted", slog.Int("count", total))
+	}
+	return total
+}
@@ -0,0 +1,86 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/outbox/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRetention_Prune(t *testing.T) {
+	tests := []struct {
+		name     string
+		batches  []int
+		err      error
+		want     int
+		wantRuns int
+	}{
+		{
+			name:     "nothing_sent",
+			batches:  []int{0},
+			want:     0,
+			wantRuns: 1,
+		},
+		{
+			name:     "several_batches",
+			batches:  []int{2, 2, 1},
+			want:     5,
+			wantRuns: 3,
+		},
+		{
+			name:     "storage_error",
+			batches:  []int{2},
+			err:      errors.New("some error"),
+			want:     2,
+			wantRuns: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewSentOutboxStorage(t)
+			for _, b := range tt.batches {
+				storage.On("DeleteSentOutbox", mock.Anything, time.Hour, 2).Return(b, nil).Once()
+			}
+			if tt.err != nil {
+				storage.On("DeleteSentOutbox", mock.Anything, time.Hour, 2).Return(0, tt.err).Once()
+			}
+			r := NewRetention(slog.Default(), storage, RetentionOptions{MaxAge: time.Hour, BatchSize: 2})
+
+			pruned := testutil.ToFloat64(prunedOutboxes)
+			got := r.Prune(context.Background())
+			if got != tt.want {
+				t.Errorf("Retention.Prune() = %v, want %v", got, tt.want)
+			}
+			assert.Equal(t, float64(tt.want), testutil.ToFloat64(prunedOutboxes)-pruned)
+			storage.AssertNumberOfCalls(t, "DeleteSentOutbox", tt.wantRuns)
+		})
+	}
+}
+
+func TestBacklogCollector(t *testing.T) {
+	backlog := mocks.NewBacklogProvider(t)
+	backlog.On("OutboxBacklog", mock.Anything).Return(&domain.OutboxBacklog{Unsent: 3, OldestAge: 90 * time.Second}, nil).Once()
+	backlog.On("OutboxBacklog", mock.Anything).Return(nil, errors.New("some error")).Once()
+
+	collector := NewBacklogCollector(slog.Default(), backlog)
+	want := `
+# HELP outbox_backlog_size Outbox records waiting to be published.
+# TYPE outbox_backlog_size gauge
+outbox_backlog_size 3
+# HELP outbox_oldest_unsent_age_seconds How long the oldest unsent outbox record has been waiting.
+# TYPE outbox_oldest_unsent_age_seconds gauge
+outbox_oldest_unsent_age_seconds 90
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(want)))
+
+	// Nothing is reported while the storage fails
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
+)
+
+var tracer = otel.Tracer("github.com/alexandernizov/grpcmessanger/internal/outbox")
+
+// send publishes the message in a span which continues the trace of the change that added it.
+// Sinks pass the context of the span on, so consumers continue the trace as well.
+func (p *Publisher) send(ctx context.Context, msg *domain.Outbox) error {
+	ctx, span := tracer.Start(tracing.Extract(ctx, msg.TraceContext), msg.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.String("messaging.message.id", msg.Uuid.String()),
+		))
+	defer span.End()
+
+	err := p.sink.Send(ctx, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
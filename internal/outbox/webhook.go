@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+)
+
+const defaultWebhookTimeout = 5 * time.Second
+
+// WebhookSink posts every outbox message to the url.
+// The body is the marshalled event, its uuid, topic, chat, sequence number and schema version are passed in headers.
+// The trace context is passed in the W3C traceparent header.
+// Replayed dead letters arrive after the later events of their chat, X-Outbox-Seq is what events are ordered by.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookSink) Send(ctx context.Context, msg *domain.Outbox) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(msg.Message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Outbox-Uuid", msg.Uuid.String())
+	req.Header.Set("X-Outbox-Topic", msg.Topic)
+	req.Header.Set("X-Outbox-Chat-Uuid", msg.ChatUuid.String())
+	req.Header.Set("X-Outbox-Seq", strconv.FormatInt(msg.Seq, 10))
+	req.Header.Set("X-Outbox-Schema-Version", strconv.Itoa(msg.SchemaVersion))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *WebhookSink) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
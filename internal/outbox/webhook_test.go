@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSink_Send(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "rejected", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &domain.Outbox{Uuid: uuid.New(), ChatUuid: uuid.New(), Seq: 3, SchemaVersion: 1, Topic: domain.ChatTopic, Message: []byte("chat")}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, msg.Message, body)
+				assert.Equal(t, msg.Uuid.String(), r.Header.Get("X-Outbox-Uuid"))
+				assert.Equal(t, msg.Topic, r.Header.Get("X-Outbox-Topic"))
+				assert.Equal(t, msg.ChatUuid.String(), r.Header.Get("X-Outbox-Chat-Uuid"))
+				assert.Equal(t, "3", r.Header.Get("X-Outbox-Seq"))
+				assert.Equal(t, "1", r.Header.Get("X-Outbox-Schema-Version"))
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sink := NewWebhookSink(server.URL, time.Second)
+			defer sink.Close()
+
+			err := sink.Send(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WebhookSink.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -14,12 +14,37 @@ var (
 	ErrInvalidToken = errors.New("token is invalid")
 )
 
-func NewTokens(user domain.User, accessTtl time.Duration, refreshTtl time.Duration, secret []byte) (domain.Tokens, error) {
+// Token types, the typ claim keeps a refresh token from being used as an access one and vice versa
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+)
+
+// Claims are the claims of a valid token
+type Claims struct {
+	UserUuid    uuid.UUID
+	SessionUuid uuid.UUID
+	// Type is TypeAccess or TypeRefresh, it's empty for tokens issued without the typ claim
+	Type string
+	// Jti identifies the token, revoked tokens are denied by it
+	Jti      uuid.UUID
+	IssuedAt time.Time
+	Expired  time.Time
+}
+
+func NewTokens(user domain.User, sessionUuid uuid.UUID, accessTtl time.Duration, refreshTtl time.Duration, secret []byte) (domain.Tokens, error) {
+	now := time.Now()
+	refreshJti := uuid.New()
+
 	accessToken := jwt.New(jwt.SigningMethodHS256)
 	accessClaims := accessToken.Claims.(jwt.MapClaims)
 	accessClaims["uuid"] = user.Uuid
 	accessClaims["login"] = user.Login
-	accessClaims["expired"] = time.Now().Add(accessTtl).Unix()
+	accessClaims["sid"] = sessionUuid
+	accessClaims["typ"] = TypeAccess
+	accessClaims["jti"] = uuid.NewString()
+	accessClaims["iat"] = now.Unix()
+	accessClaims["expired"] = now.Add(accessTtl).Unix()
 	accessString, err := accessToken.SignedString(secret)
 	if err != nil {
 		return domain.Tokens{}, err
@@ -29,70 +54,67 @@ func NewTokens(user domain.User, accessTtl time.Duration, refreshTtl time.Durati
 	refreshClaims := refreshToken.Claims.(jwt.MapClaims)
 	refreshClaims["uuid"] = user.Uuid
 	refreshClaims["login"] = user.Login
-	refreshClaims["expired"] = time.Now().Add(refreshTtl).Unix()
+	refreshClaims["sid"] = sessionUuid
+	refreshClaims["typ"] = TypeRefresh
+	refreshClaims["jti"] = refreshJti
+	refreshClaims["iat"] = now.Unix()
+	refreshClaims["expired"] = now.Add(refreshTtl).Unix()
 	refreshString, err := refreshToken.SignedString(secret)
 	if err != nil {
 		return domain.Tokens{}, err
 	}
 
-	return domain.Tokens{AccessToken: accessString, RefreshToken: refreshString}, nil
+	return domain.Tokens{AccessToken: accessString, RefreshToken: refreshString, RefreshJti: refreshJti}, nil
 }
 
-func ValidateToken(tokenString string, secret []byte) (bool, error) {
+// ParseToken validates the token and returns its claims
+func ParseToken(tokenString string, secret []byte) (*Claims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Check signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("invalid method: %v", token.Header["alg"])
 		}
 		return secret, nil
 	})
 	if err != nil {
-		return false, fmt.Errorf("%w: %w", ErrInvalidToken, err)
-	}
-
-	//Common check
-	if !token.Valid {
-		return false, ErrInvalidToken
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
 	}
 
-	//Check if expired
 	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return false, ErrInvalidToken
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
 	}
+
 	expired, ok := claims["expired"].(float64)
+	if !ok || expired < float64(time.Now().Unix()) {
+		return nil, ErrInvalidToken
+	}
+	issuedAt, ok := claims["iat"].(float64)
 	if !ok {
-		return false, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
-
-	if expired < float64(time.Now().Unix()) {
-		return false, ErrInvalidToken
+	uuidString, _ := claims["uuid"].(string)
+	userUuid, err := uuid.Parse(uuidString)
+	if err != nil {
+		return nil, ErrInvalidToken
 	}
-
-	return true, nil
-}
-
-func GetUserUuidFromToken(tokenString string, secret []byte) (uuid.UUID, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Проверка метода подписи, ожидаемого вашим приложением
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("invalid method: %v", token.Header["alg"])
-		}
-		return secret, nil
-	})
+	sidString, _ := claims["sid"].(string)
+	sessionUuid, err := uuid.Parse(sidString)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("token parsing failed: %w", err)
+		return nil, ErrInvalidToken
 	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("error parsing token")
+	jtiString, _ := claims["jti"].(string)
+	jti, err := uuid.Parse(jtiString)
+	if err != nil {
+		return nil, ErrInvalidToken
 	}
-	uuidString, ok := claims["uuid"].(string)
+	typ, _ := claims["typ"].(string)
 
-	uuidUser, err := uuid.Parse(uuidString)
-	if err != nil || !ok {
-		return uuid.Nil, fmt.Errorf("token parsing failed: %w", err)
-	}
-	return uuidUser, nil
+	return &Claims{
+		UserUuid:    userUuid,
+		SessionUuid: sessionUuid,
+		Type:        typ,
+		Jti:         jti,
+		IssuedAt:    time.Unix(int64(issuedAt), 0),
+		Expired:     time.Unix(int64(expired), 0),
+	}, nil
 }
@@ -0,0 +1,177 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name DeadLetterStorage
+type DeadLetterStorage interface {
+	ListDeadLetters(ctx context.Context, limit int, offset int) ([]*domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error
+	DeleteDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error
+	PurgeDeadLetters(ctx context.Context) (int, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name QuotaStorage
+type QuotaStorage interface {
+	SetUserQuota(ctx context.Context, userUuid uuid.UUID, quota domain.Quota) error
+}
+
+var (
+	ErrInternal           = errors.New("internal error")
+	ErrPermissionDenied   = errors.New("have no permission for this operation")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrUserNotFound       = errors.New("user not found")
+)
+
+const (
+	defaultPageSize = 50
+	maximumPageSize = 500
+)
+
+// AdminService manages the dead letters of the outbox and the quotas of users, it's available to the configured admins only
+type AdminService struct {
+	log               *slog.Logger
+	deadLetterStorage DeadLetterStorage
+	quotaStorage      QuotaStorage
+	admins            map[uuid.UUID]bool
+}
+
+func New(log *slog.Logger, deadLetterStorage DeadLetterStorage, quotaStorage QuotaStorage, admins []uuid.UUID) *AdminService {
+	adminSet := make(map[uuid.UUID]bool, len(admins))
+	for _, v := range admins {
+		adminSet[v] = true
+	}
+	return &AdminService{log: log, deadLetterStorage: deadLetterStorage, quotaStorage: quotaStorage, admins: adminSet}
+}
+
+// ListDeadLetters returns a page of dead letters, the oldest failures first
+func (a *AdminService) ListDeadLetters(ctx context.Context, actorUuid uuid.UUID, limit int, offset int) (*domain.DeadLetterPage, error) {
+	const op = "admin.ListDeadLetters"
+	log := a.log.With(slog.String("op", op))
+
+	if !a.admins[actorUuid] {
+		return nil, ErrPermissionDenied
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maximumPageSize)
+	offset = max(offset, 0)
+
+	res, err := a.deadLetterStorage.ListDeadLetters(ctx, limit, offset)
+	if err != nil {
+		log.Error("can't list dead letters", sl.Err(err))
+		return nil, ErrInternal
+	}
+
+	page := domain.DeadLetterPage{DeadLetters: res}
+	// A short page is the last one
+	if len(res) == limit {
+		page.NextOffset = offset + limit
+	}
+	return &page, nil
+}
+
+func (a *AdminService) GetDeadLetter(ctx context.Context, actorUuid uuid.UUID, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error) {
+	const op = "admin.GetDeadLetter"
+	log := a.log.With(slog.String("op", op))
+
+	if !a.admins[actorUuid] {
+		return nil, ErrPermissionDenied
+	}
+
+	res, err := a.deadLetterStorage.GetDeadLetter(ctx, deadLetterUuid)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeadLetterNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		log.Error("can't get dead letter", sl.Err(err))
+		return nil, ErrInternal
+	}
+	return res, nil
+}
+
+// ReplayDeadLetter puts the dead letter back to the outbox, it's published again with fresh attempts.
+// The later events of its chat have been published meanwhile, so it arrives out of order with its original seq.
+func (a *AdminService) ReplayDeadLetter(ctx context.Context, actorUuid uuid.UUID, deadLetterUuid uuid.UUID) error {
+	const op = "admin.ReplayDeadLetter"
+	log := a.log.With(slog.String("op", op))
+
+	if !a.admins[actorUuid] {
+		return ErrPermissionDenied
+	}
+
+	err := a.deadLetterStorage.ReplayDeadLetter(ctx, deadLetterUuid)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeadLetterNotFound) {
+			return ErrDeadLetterNotFound
+		}
+		log.Error("can't replay dead letter", sl.Err(err))
+		return ErrInternal
+	}
+	log.Info("dead letter is replayed", slog.String("uuid", deadLetterUuid.String()), slog.String("admin", actorUuid.String()))
+	return nil
+}
+
+// PurgeDeadLetters deletes the dead letter, or all of them when deadLetterUuid is nil.
+// It returns how many dead letters were deleted.
+func (a *AdminService) PurgeDeadLetters(ctx context.Context, actorUuid uuid.UUID, deadLetterUuid uuid.UUID) (int, error) {
+	const op = "admin.PurgeDeadLetters"
+	log := a.log.With(slog.String("op", op))
+
+	if !a.admins[actorUuid] {
+		return 0, ErrPermissionDenied
+	}
+
+	if deadLetterUuid == uuid.Nil {
+		purged, err := a.deadLetterStorage.PurgeDeadLetters(ctx)
+		if err != nil {
+			log.Error("can't purge dead letters", sl.Err(err))
+			return 0, ErrInternal
+		}
+		log.Info("dead letters are purged", slog.Int("purged", purged), slog.String("admin", actorUuid.String()))
+		return purged, nil
+	}
+
+	err := a.deadLetterStorage.DeleteDeadLetter(ctx, deadLetterUuid)
+	if err != nil {
+		if errors.Is(err, storage.ErrDeadLetterNotFound) {
+			return 0, ErrDeadLetterNotFound
+		}
+		log.Error("can't delete dead letter", sl.Err(err))
+		return 0, ErrInternal
+	}
+	log.Info("dead letter is purged", slog.String("uuid", deadLetterUuid.String()), slog.String("admin", actorUuid.String()))
+	return 1, nil
+}
+
+// SetUserQuota overrides the default quota of the user.
+// Zero limits keep the defaults, negative ones lift them.
+func (a *AdminService) SetUserQuota(ctx context.Context, actorUuid uuid.UUID, userUuid uuid.UUID, quota domain.Quota) error {
+	const op = "admin.SetUserQuota"
+	log := a.log.With(slog.String("op", op))
+
+	if !a.admins[actorUuid] {
+		return ErrPermissionDenied
+	}
+
+	err := a.quotaStorage.SetUserQuota(ctx, userUuid, quota)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		log.Error("can't set user quota", sl.Err(err))
+		return ErrInternal
+	}
+	log.Info("user quota is set", slog.String("user", userUuid.String()), slog.String("admin", actorUuid.String()))
+	return nil
+}
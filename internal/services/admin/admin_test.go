@@ -0,0 +1,225 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/services/admin/mocks"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	adminUuidTest      = uuid.MustParse("6a0e7a52-8c55-4f0e-b3f4-0d1c2b3a4f01")
+	userUuidTest       = uuid.MustParse("6a0e7a52-8c55-4f0e-b3f4-0d1c2b3a4f02")
+	deadLetterUuidTest = uuid.MustParse("6a0e7a52-8c55-4f0e-b3f4-0d1c2b3a4f03")
+)
+
+type mockArgs struct {
+	methodName string
+	arguments  []any
+	returning  []any
+}
+
+func NewMockService(t *testing.T, inputMocks []mockArgs) *AdminService {
+	deadLetterStorage := mocks.NewDeadLetterStorage(t)
+	quotaStorage := mocks.NewQuotaStorage(t)
+	for _, m := range inputMocks {
+		if m.methodName == "SetUserQuota" {
+			quotaStorage.On(m.methodName, m.arguments...).Return(m.returning...).Once()
+			continue
+		}
+		deadLetterStorage.On(m.methodName, m.arguments...).Return(m.returning...).Once()
+	}
+	return New(slog.Default(), deadLetterStorage, quotaStorage, []uuid.UUID{adminUuidTest})
+}
+
+func TestAdminService_ListDeadLetters(t *testing.T) {
+	deadLetters := []*domain.DeadLetter{{Uuid: deadLetterUuidTest, Attempts: 5}}
+	fullPage := make([]*domain.DeadLetter, defaultPageSize)
+	for i := range fullPage {
+		fullPage[i] = &domain.DeadLetter{Uuid: uuid.New()}
+	}
+
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		limit    int
+		offset   int
+		mockArgs []mockArgs
+		want     *domain.DeadLetterPage
+		wantErr  error
+	}{
+		{
+			name:     "default_page_size",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "ListDeadLetters", arguments: []any{mock.Anything, defaultPageSize, 0}, returning: []any{deadLetters, nil}}},
+			want:     &domain.DeadLetterPage{DeadLetters: deadLetters},
+		},
+		{
+			name:     "default_page_size_is_full",
+			actor:    adminUuidTest,
+			offset:   10,
+			mockArgs: []mockArgs{{methodName: "ListDeadLetters", arguments: []any{mock.Anything, defaultPageSize, 10}, returning: []any{fullPage, nil}}},
+			want:     &domain.DeadLetterPage{DeadLetters: fullPage, NextOffset: 10 + defaultPageSize},
+		},
+		{
+			name:     "page_size_is_limited",
+			actor:    adminUuidTest,
+			limit:    maximumPageSize + 1,
+			mockArgs: []mockArgs{{methodName: "ListDeadLetters", arguments: []any{mock.Anything, maximumPageSize, 0}, returning: []any{deadLetters, nil}}},
+			want:     &domain.DeadLetterPage{DeadLetters: deadLetters},
+		},
+		{
+			name:    "not_admin",
+			actor:   userUuidTest,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:     "storage_error",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "ListDeadLetters", arguments: []any{mock.Anything, defaultPageSize, 0}, returning: []any{nil, errors.New("some error")}}},
+			wantErr:  ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewMockService(t, tt.mockArgs)
+			got, err := a.ListDeadLetters(context.TODO(), tt.actor, tt.limit, tt.offset)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AdminService.ListDeadLetters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAdminService_ReplayDeadLetter(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name:     "replayed",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "ReplayDeadLetter", arguments: []any{mock.Anything, deadLetterUuidTest}, returning: []any{nil}}},
+		},
+		{
+			name:     "not_found",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "ReplayDeadLetter", arguments: []any{mock.Anything, deadLetterUuidTest}, returning: []any{storage.ErrDeadLetterNotFound}}},
+			wantErr:  ErrDeadLetterNotFound,
+		},
+		{
+			name:    "not_admin",
+			actor:   userUuidTest,
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewMockService(t, tt.mockArgs)
+			err := a.ReplayDeadLetter(context.TODO(), tt.actor, deadLetterUuidTest)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AdminService.ReplayDeadLetter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdminService_PurgeDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      uuid.UUID
+		deadLetter uuid.UUID
+		mockArgs   []mockArgs
+		want       int
+		wantErr    error
+	}{
+		{
+			name:       "one",
+			actor:      adminUuidTest,
+			deadLetter: deadLetterUuidTest,
+			mockArgs:   []mockArgs{{methodName: "DeleteDeadLetter", arguments: []any{mock.Anything, deadLetterUuidTest}, returning: []any{nil}}},
+			want:       1,
+		},
+		{
+			name:       "one_not_found",
+			actor:      adminUuidTest,
+			deadLetter: deadLetterUuidTest,
+			mockArgs:   []mockArgs{{methodName: "DeleteDeadLetter", arguments: []any{mock.Anything, deadLetterUuidTest}, returning: []any{storage.ErrDeadLetterNotFound}}},
+			wantErr:    ErrDeadLetterNotFound,
+		},
+		{
+			name:     "all",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "PurgeDeadLetters", arguments: []any{mock.Anything}, returning: []any{3, nil}}},
+			want:     3,
+		},
+		{
+			name:    "not_admin",
+			actor:   userUuidTest,
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewMockService(t, tt.mockArgs)
+			got, err := a.PurgeDeadLetters(context.TODO(), tt.actor, tt.deadLetter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AdminService.PurgeDeadLetters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAdminService_SetUserQuota(t *testing.T) {
+	quota := domain.Quota{ChatsOwned: 10, MessagesPerMinute: -1}
+
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name:     "set",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "SetUserQuota", arguments: []any{mock.Anything, userUuidTest, quota}, returning: []any{nil}}},
+		},
+		{
+			name:     "user_not_found",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "SetUserQuota", arguments: []any{mock.Anything, userUuidTest, quota}, returning: []any{storage.ErrUserNotFound}}},
+			wantErr:  ErrUserNotFound,
+		},
+		{
+			name:     "storage_error",
+			actor:    adminUuidTest,
+			mockArgs: []mockArgs{{methodName: "SetUserQuota", arguments: []any{mock.Anything, userUuidTest, quota}, returning: []any{storage.ErrInternal}}},
+			wantErr:  ErrInternal,
+		},
+		{
+			name:    "not_admin",
+			actor:   userUuidTest,
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewMockService(t, tt.mockArgs)
+			err := a.SetUserQuota(context.TODO(), tt.actor, userUuidTest, quota)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AdminService.SetUserQuota() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
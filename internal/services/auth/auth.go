@@ -20,8 +20,15 @@ type AuthStorage interface {
 	GetUserByLogin(ctx context.Context, login string) (*domain.User, error)
 	GetUserByUuid(ctx context.Context, uuid uuid.UUID) (*domain.User, error)
 
-	UpsertRefreshToken(ctx context.Context, userUuid uuid.UUID, refreshToken string) error
-	GetRefreshToken(ctx context.Context, userUuid uuid.UUID) (string, error)
+	CreateSession(ctx context.Context, session domain.Session) error
+	GetSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error)
+	RotateSession(ctx context.Context, session domain.Session, previousJti uuid.UUID) error
+	ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error)
+	DeleteSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error
+	DeleteUserSessions(ctx context.Context, userUuid uuid.UUID) error
+
+	RevokeToken(ctx context.Context, jti uuid.UUID, expired time.Time) error
+	IsTokenRevoked(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID, jti uuid.UUID) (bool, error)
 }
 
 type AuthService struct {
@@ -41,6 +48,7 @@ var (
 	ErrUserAlreadyExsist  = errors.New("user is already exist")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInternalError      = errors.New("internal error")
+	ErrSessionNotFound    = errors.New("session not found")
 )
 
 func New(log *slog.Logger, authStorage AuthStorage, jwtParams JwtParams) *AuthService {
@@ -77,12 +85,10 @@ func (a *AuthService) Register(ctx context.Context, login, password string) (*do
 	return &newUser, nil
 }
 
-func (a *AuthService) Login(ctx context.Context, login, password string) (*domain.Tokens, error) {
+func (a *AuthService) Login(ctx context.Context, login, password string, device domain.Device) (*domain.Tokens, error) {
 	const op = "auth.Login"
 	log := a.log.With(slog.String("op", op))
 
-	var tokens domain.Tokens
-
 	user, err := a.authStorage.GetUserByLogin(ctx, login)
 	if errors.Is(err, storage.ErrUserNotFound) {
 		return nil, ErrInvalidCredentials
@@ -96,13 +102,25 @@ func (a *AuthService) Login(ctx context.Context, login, password string) (*domai
 		return nil, ErrInvalidCredentials
 	}
 
-	tokens, err = jwt.NewTokens(*user, a.jwtParams.AccessTtl, a.jwtParams.RefreshTtl, a.jwtParams.Secret)
+	now := time.Now()
+	session := domain.Session{
+		Uuid:       uuid.New(),
+		UserUuid:   user.Uuid,
+		DeviceName: device.Name,
+		UserAgent:  device.UserAgent,
+		Created:    now,
+		LastUsed:   now,
+		Expires:    now.Add(a.jwtParams.RefreshTtl),
+	}
+
+	tokens, err := jwt.NewTokens(*user, session.Uuid, a.jwtParams.AccessTtl, a.jwtParams.RefreshTtl, a.jwtParams.Secret)
 	if err != nil {
 		log.Error("error with generating tokens", sl.Err(err))
 		return nil, ErrInternalError
 	}
+	session.RefreshJti = tokens.RefreshJti
 
-	err = a.authStorage.UpsertRefreshToken(ctx, user.Uuid, tokens.RefreshToken)
+	err = a.authStorage.CreateSession(ctx, session)
 	if err != nil {
 		return nil, ErrInternalError
 	}
@@ -110,30 +128,45 @@ func (a *AuthService) Login(ctx context.Context, login, password string) (*domai
 	return &tokens, nil
 }
 
+// Refresh rotates the refresh token of the session.
+// A refresh token can be used only once, replaying an old one revokes the whole session.
 func (a *AuthService) Refresh(ctx context.Context, token string) (*domain.Tokens, error) {
 	const op = "auth.Refresh"
 	log := a.log.With(slog.String("op", op))
 
 	// Validate token
-	ok, err := jwt.ValidateToken(token, a.jwtParams.Secret)
-	if !ok || (err != nil) {
+	claims, err := jwt.ParseToken(token, a.jwtParams.Secret)
+	if err != nil {
 		log.Warn("someone send invalid token: ", sl.Err(err))
 		return nil, ErrInvalidCredentials
 	}
+	// An access token isn't a replayed refresh token, so the session is kept
+	if claims.Type != jwt.TypeRefresh {
+		log.Warn("someone send not a refresh token", slog.String("typ", claims.Type))
+		return nil, ErrInvalidCredentials
+	}
+	userUuid := claims.UserUuid
+	log = log.With(slog.String("user_uuid", userUuid.String()), slog.String("session_uuid", claims.SessionUuid.String()))
 
-	userUuid, err := jwt.GetUserUuidFromToken(token, a.jwtParams.Secret)
+	revoked, err := a.authStorage.IsTokenRevoked(ctx, userUuid, claims.SessionUuid, claims.Jti)
 	if err != nil {
+		return nil, ErrInternalError
+	}
+	if revoked {
+		log.Warn("attempting refresh tokens with revoked token")
 		return nil, ErrInvalidCredentials
 	}
 
-	var newTokens domain.Tokens
-	currentToken, err := a.authStorage.GetRefreshToken(ctx, userUuid)
-	if err != nil {
+	session, err := a.authStorage.GetSession(ctx, claims.SessionUuid)
+	if errors.Is(err, storage.ErrSessionNotFound) {
 		return nil, ErrInvalidCredentials
 	}
+	if err != nil {
+		return nil, ErrInternalError
+	}
 
-	if currentToken != token {
-		log.Warn("attempting refresh tokens with expired token: ", slog.String("user_uuid", userUuid.String()))
+	if session.RefreshJti != claims.Jti {
+		a.revokeReusedSession(ctx, log, *session)
 		return nil, ErrInvalidCredentials
 	}
 
@@ -143,16 +176,95 @@ func (a *AuthService) Refresh(ctx context.Context, token string) (*domain.Tokens
 	}
 
 	// Generate new token
-	newTokens, err = jwt.NewTokens(*user, a.jwtParams.AccessTtl, a.jwtParams.RefreshTtl, a.jwtParams.Secret)
+	newTokens, err := jwt.NewTokens(*user, session.Uuid, a.jwtParams.AccessTtl, a.jwtParams.RefreshTtl, a.jwtParams.Secret)
 	if err != nil {
 		log.Error("error according creating jwt tokens: ", sl.Err(err))
 		return nil, ErrInternalError
 	}
 
-	err = a.authStorage.UpsertRefreshToken(ctx, userUuid, newTokens.RefreshToken)
+	now := time.Now()
+	rotated := *session
+	rotated.RefreshJti = newTokens.RefreshJti
+	rotated.LastUsed = now
+	rotated.Expires = now.Add(a.jwtParams.RefreshTtl)
+
+	err = a.authStorage.RotateSession(ctx, rotated, claims.Jti)
+	if errors.Is(err, storage.ErrSessionNotFound) {
+		// Someone else has just used the same refresh token
+		a.revokeReusedSession(ctx, log, *session)
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, ErrInternalError
 	}
 
 	return &newTokens, nil
 }
+
+func (a *AuthService) revokeReusedSession(ctx context.Context, log *slog.Logger, session domain.Session) {
+	log.Warn("refresh token reuse detected, revoking session")
+
+	err := a.authStorage.DeleteSession(ctx, session.UserUuid, session.Uuid)
+	if err != nil && !errors.Is(err, storage.ErrSessionNotFound) {
+		log.Error("can't revoke session", sl.Err(err))
+	}
+}
+
+// Logout revokes the session of the access token together with the token itself.
+func (a *AuthService) Logout(ctx context.Context, claims domain.AccessClaims) error {
+	err := a.authStorage.RevokeToken(ctx, claims.Jti, claims.Expired)
+	if err != nil {
+		return ErrInternalError
+	}
+
+	err = a.authStorage.DeleteSession(ctx, claims.UserUuid, claims.SessionUuid)
+	if err != nil && !errors.Is(err, storage.ErrSessionNotFound) {
+		return ErrInternalError
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every session of the user, tokens of those sessions aren't accepted anymore.
+func (a *AuthService) LogoutAll(ctx context.Context, claims domain.AccessClaims) error {
+	err := a.authStorage.RevokeToken(ctx, claims.Jti, claims.Expired)
+	if err != nil {
+		return ErrInternalError
+	}
+
+	err = a.authStorage.DeleteUserSessions(ctx, claims.UserUuid)
+	if err != nil {
+		return ErrInternalError
+	}
+
+	return nil
+}
+
+func (a *AuthService) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	sessions, err := a.authStorage.ListSessions(ctx, userUuid)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs the user out on another device.
+func (a *AuthService) RevokeSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	err := a.authStorage.DeleteSession(ctx, userUuid, sessionUuid)
+	if errors.Is(err, storage.ErrSessionNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return ErrInternalError
+	}
+	return nil
+}
+
+// IsRevoked reports whether the access token of claims was revoked by logout.
+func (a *AuthService) IsRevoked(ctx context.Context, claims domain.AccessClaims) (bool, error) {
+	revoked, err := a.authStorage.IsTokenRevoked(ctx, claims.UserUuid, claims.SessionUuid, claims.Jti)
+	if err != nil {
+		return false, ErrInternalError
+	}
+	return revoked, nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"testing"
 	"time"
@@ -19,7 +20,10 @@ var (
 	userUuidTest       = uuid.MustParse("8ee4e645-b894-4477-820b-48381e10677f")
 	hashedPasswordTest = "$2a$10$yEC5DhDM3Mx4f4Wex2qqZ..ZK1vh4a/Q25x4Zm/RWztFCgsUZvVBy"
 	userTest           = domain.User{Uuid: userUuidTest, Login: "test", PasswordHash: []byte("test")}
-	tokensTest, _      = jwt.NewTokens(userTest, time.Minute, time.Minute, []byte(secretTest))
+	sessionUuidTest    = uuid.MustParse("2f1e7f7c-0d1c-4f55-a8a5-6a0c3f9b2e11")
+	tokensTest, _      = jwt.NewTokens(userTest, sessionUuidTest, time.Minute, time.Minute, []byte(secretTest))
+	sessionTest        = domain.Session{Uuid: sessionUuidTest, UserUuid: userUuidTest, RefreshJti: tokensTest.RefreshJti}
+	accessClaimsTest   = domain.AccessClaims{UserUuid: userUuidTest, SessionUuid: sessionUuidTest, Jti: uuid.MustParse("0b6d6f5e-3c1d-4a5e-9f3b-7c2e1d0a9b8c"), Expired: time.Now().Add(time.Minute)}
 )
 
 type mockArgs struct {
@@ -111,7 +115,9 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			mockArgs: []mockArgs{
 				{methodName: "GetUserByLogin", arguments: []any{mock.Anything, "test"}, returning: []any{&domain.User{Uuid: userUuidTest, Login: "test", PasswordHash: []byte(hashedPasswordTest)}, nil}},
-				{methodName: "UpsertRefreshToken", arguments: []any{mock.Anything, userUuidTest, mock.Anything}, returning: []any{nil}},
+				{methodName: "CreateSession", arguments: []any{mock.Anything, mock.MatchedBy(func(s domain.Session) bool {
+					return s.UserUuid == userUuidTest && s.DeviceName == "laptop" && s.RefreshJti != uuid.Nil
+				})}, returning: []any{nil}},
 			},
 			want:    &domain.Tokens{},
 			wantErr: false,
@@ -120,7 +126,7 @@ func TestAuthService_Login(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := NewMockService(t, tt.mockArgs)
-			got, err := a.Login(tt.funcArgs.ctx, tt.funcArgs.login, tt.funcArgs.password)
+			got, err := a.Login(tt.funcArgs.ctx, tt.funcArgs.login, tt.funcArgs.password, domain.Device{Name: "laptop"})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AuthService.Login() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -154,13 +160,65 @@ func TestAuthService_Refresh(t *testing.T) {
 				token: tokensTest.RefreshToken,
 			},
 			mockArgs: []mockArgs{
-				{methodName: "GetRefreshToken", arguments: []any{mock.Anything, userUuidTest}, returning: []any{tokensTest.RefreshToken, nil}},
+				{methodName: "IsTokenRevoked", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest, tokensTest.RefreshJti}, returning: []any{false, nil}},
+				{methodName: "GetSession", arguments: []any{mock.Anything, sessionUuidTest}, returning: []any{&sessionTest, nil}},
 				{methodName: "GetUserByUuid", arguments: []any{mock.Anything, userUuidTest}, returning: []any{&userTest, nil}},
-				{methodName: "UpsertRefreshToken", arguments: []any{mock.Anything, userUuidTest, mock.Anything}, returning: []any{nil}},
+				{methodName: "RotateSession", arguments: []any{mock.Anything, mock.Anything, tokensTest.RefreshJti}, returning: []any{nil}},
 			},
 			want:    &domain.Tokens{},
 			wantErr: false,
 		},
+		{
+			name: "revoked",
+			funcArgs: funcArgs{
+				ctx:   context.TODO(),
+				token: tokensTest.RefreshToken,
+			},
+			mockArgs: []mockArgs{
+				{methodName: "IsTokenRevoked", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest, tokensTest.RefreshJti}, returning: []any{true, nil}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "reused",
+			funcArgs: funcArgs{
+				ctx:   context.TODO(),
+				token: tokensTest.RefreshToken,
+			},
+			mockArgs: []mockArgs{
+				{methodName: "IsTokenRevoked", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest, tokensTest.RefreshJti}, returning: []any{false, nil}},
+				{methodName: "GetSession", arguments: []any{mock.Anything, sessionUuidTest}, returning: []any{&domain.Session{Uuid: sessionUuidTest, UserUuid: userUuidTest, RefreshJti: uuid.New()}, nil}},
+				{methodName: "DeleteSession", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest}, returning: []any{nil}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "access_token",
+			funcArgs: funcArgs{
+				ctx:   context.TODO(),
+				token: tokensTest.AccessToken,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "reused_concurrently",
+			funcArgs: funcArgs{
+				ctx:   context.TODO(),
+				token: tokensTest.RefreshToken,
+			},
+			mockArgs: []mockArgs{
+				{methodName: "IsTokenRevoked", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest, tokensTest.RefreshJti}, returning: []any{false, nil}},
+				{methodName: "GetSession", arguments: []any{mock.Anything, sessionUuidTest}, returning: []any{&sessionTest, nil}},
+				{methodName: "GetUserByUuid", arguments: []any{mock.Anything, userUuidTest}, returning: []any{&userTest, nil}},
+				{methodName: "RotateSession", arguments: []any{mock.Anything, mock.Anything, tokensTest.RefreshJti}, returning: []any{storage.ErrSessionNotFound}},
+				{methodName: "DeleteSession", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest}, returning: []any{nil}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -179,3 +237,78 @@ func TestAuthService_Refresh(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthService_Logout(t *testing.T) {
+	tests := []struct {
+		name     string
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name: "success",
+			mockArgs: []mockArgs{
+				{methodName: "RevokeToken", arguments: []any{mock.Anything, accessClaimsTest.Jti, accessClaimsTest.Expired}, returning: []any{nil}},
+				{methodName: "DeleteSession", arguments: []any{mock.Anything, userUuidTest, sessionUuidTest}, returning: []any{nil}},
+			},
+		},
+		{
+			name: "storage_error",
+			mockArgs: []mockArgs{
+				{methodName: "RevokeToken", arguments: []any{mock.Anything, accessClaimsTest.Jti, accessClaimsTest.Expired}, returning: []any{storage.ErrInternal}},
+			},
+			wantErr: ErrInternalError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewMockService(t, tt.mockArgs)
+			err := a.Logout(context.TODO(), accessClaimsTest)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthService.Logout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	a := NewMockService(t, []mockArgs{
+		{methodName: "RevokeToken", arguments: []any{mock.Anything, mock.Anything, mock.Anything}, returning: []any{nil}},
+		{methodName: "DeleteUserSessions", arguments: []any{mock.Anything, userUuidTest}, returning: []any{nil}},
+	})
+	err := a.LogoutAll(context.TODO(), accessClaimsTest)
+	if err != nil {
+		t.Errorf("AuthService.LogoutAll() error = %v", err)
+	}
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	otherSession := uuid.MustParse("6d0f7d0e-8b7a-4f0c-9d6e-2b1a3c4d5e6f")
+	tests := []struct {
+		name     string
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name: "success",
+			mockArgs: []mockArgs{
+				{methodName: "DeleteSession", arguments: []any{mock.Anything, userUuidTest, otherSession}, returning: []any{nil}},
+			},
+		},
+		{
+			name: "not_found",
+			mockArgs: []mockArgs{
+				{methodName: "DeleteSession", arguments: []any{mock.Anything, userUuidTest, otherSession}, returning: []any{storage.ErrSessionNotFound}},
+			},
+			wantErr: ErrSessionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewMockService(t, tt.mockArgs)
+			err := a.RevokeSession(context.TODO(), userUuidTest, otherSession)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthService.RevokeSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
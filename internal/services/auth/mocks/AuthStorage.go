@@ -4,11 +4,11 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	domain "github.com/alexandernizov/grpcmessanger/internal/domain"
-	mock "github.com/stretchr/testify/mock"
-
 	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // AuthStorage is an autogenerated mock type for the AuthStorage type
@@ -16,6 +16,20 @@ type AuthStorage struct {
 	mock.Mock
 }
 
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *AuthStorage) CreateSession(ctx context.Context, session domain.Session) error {
+	ret := _m.Called(ctx, session)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Session) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateUser provides a mock function with given fields: ctx, user
 func (_m *AuthStorage) CreateUser(ctx context.Context, user domain.User) (*domain.User, error) {
 	ret := _m.Called(ctx, user)
@@ -42,23 +56,53 @@ func (_m *AuthStorage) CreateUser(ctx context.Context, user domain.User) (*domai
 	return r0, r1
 }
 
-// GetRefreshToken provides a mock function with given fields: ctx, userUuid
-func (_m *AuthStorage) GetRefreshToken(ctx context.Context, userUuid uuid.UUID) (string, error) {
+// DeleteSession provides a mock function with given fields: ctx, userUuid, sessionUuid
+func (_m *AuthStorage) DeleteSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	ret := _m.Called(ctx, userUuid, sessionUuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUuid, sessionUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserSessions provides a mock function with given fields: ctx, userUuid
+func (_m *AuthStorage) DeleteUserSessions(ctx context.Context, userUuid uuid.UUID) error {
 	ret := _m.Called(ctx, userUuid)
 
-	var r0 string
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSession provides a mock function with given fields: ctx, sessionUuid
+func (_m *AuthStorage) GetSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error) {
+	ret := _m.Called(ctx, sessionUuid)
+
+	var r0 *domain.Session
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (string, error)); ok {
-		return rf(ctx, userUuid)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*domain.Session, error)); ok {
+		return rf(ctx, sessionUuid)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) string); ok {
-		r0 = rf(ctx, userUuid)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *domain.Session); ok {
+		r0 = rf(ctx, sessionUuid)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Session)
+		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
-		r1 = rf(ctx, userUuid)
+		r1 = rf(ctx, sessionUuid)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -118,13 +162,77 @@ func (_m *AuthStorage) GetUserByUuid(ctx context.Context, _a1 uuid.UUID) (*domai
 	return r0, r1
 }
 
-// UpsertRefreshToken provides a mock function with given fields: ctx, userUuid, refreshToken
-func (_m *AuthStorage) UpsertRefreshToken(ctx context.Context, userUuid uuid.UUID, refreshToken string) error {
-	ret := _m.Called(ctx, userUuid, refreshToken)
+// IsTokenRevoked provides a mock function with given fields: ctx, userUuid, sessionUuid, jti
+func (_m *AuthStorage) IsTokenRevoked(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID, jti uuid.UUID) (bool, error) {
+	ret := _m.Called(ctx, userUuid, sessionUuid, jti)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (bool, error)); ok {
+		return rf(ctx, userUuid, sessionUuid, jti)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) bool); ok {
+		r0 = rf(ctx, userUuid, sessionUuid, jti)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUuid, sessionUuid, jti)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSessions provides a mock function with given fields: ctx, userUuid
+func (_m *AuthStorage) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	ret := _m.Called(ctx, userUuid)
+
+	var r0 []*domain.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*domain.Session, error)); ok {
+		return rf(ctx, userUuid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*domain.Session); ok {
+		r0 = rf(ctx, userUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeToken provides a mock function with given fields: ctx, jti, expired
+func (_m *AuthStorage) RevokeToken(ctx context.Context, jti uuid.UUID, expired time.Time) error {
+	ret := _m.Called(ctx, jti, expired)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r0 = rf(ctx, jti, expired)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateSession provides a mock function with given fields: ctx, session, previousJti
+func (_m *AuthStorage) RotateSession(ctx context.Context, session domain.Session, previousJti uuid.UUID) error {
+	ret := _m.Called(ctx, session, previousJti)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
-		r0 = rf(ctx, userUuid, refreshToken)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Session, uuid.UUID) error); ok {
+		r0 = rf(ctx, session, previousJti)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,13 +3,11 @@ package chat
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
-	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/google/uuid"
 )
 
@@ -17,44 +15,69 @@ import (
 type ChatStorage interface {
 	CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat, error)
 	GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat, error)
-	ChatsCount(ctx context.Context) (int, error)
 	PostMessage(ctx context.Context, chat uuid.UUID, message domain.Message) (*domain.Message, error)
 	TrimMessages(ctx context.Context, chat uuid.UUID, maximumMessages int) (bool, error)
-	GetChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error)
+	GetChatHistoryPage(ctx context.Context, chatUuid uuid.UUID, query domain.HistoryQuery) ([]*domain.Message, error)
+
+	AddMember(ctx context.Context, member domain.Member) (*domain.Member, error)
+	GetMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error)
+	RemoveMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) error
+	ListMembers(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Member, error)
+
+	GetMessage(ctx context.Context, chatUuid uuid.UUID, messageId int) (*domain.Message, error)
+	EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string, edited time.Time) (*domain.Message, error)
+	DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, deleted time.Time) (*domain.Message, error)
+
+	GetUserQuota(ctx context.Context, userUuid uuid.UUID) (*domain.Quota, error)
+	QuotaUsage(ctx context.Context, userUuid uuid.UUID, since time.Time) (*domain.QuotaUsage, error)
 }
 
 var (
 	ErrInternal               = errors.New("internal error")
-	ErrMaximumChats           = errors.New("maximum chats created already")
+	ErrQuotaExceeded          = errors.New("quota exceeded")
 	ErrPermissionDenied       = errors.New("have no permission for this operation")
 	ErrChatNotFound           = errors.New("chat not found")
 	ErrNotificationNotCreated = errors.New("notification was not created")
+	ErrInvalidCursor          = errors.New("only one of before and after cursors can be set")
+	ErrInvalidRole            = errors.New("role is invalid")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrMessageNotFound        = errors.New("message not found")
 )
 
 type ChatService struct {
 	log         *slog.Logger
 	chatOptions ChatOptions
 	chatStorage ChatStorage
+	hub         *Hub
 }
 
 type ChatOptions struct {
-	DefaultTtl      time.Duration
-	MaximumCount    int
-	MaximumMessages int
+	DefaultTtl       time.Duration
+	MaximumMessages  int
+	SubscriberBuffer int
+	// Quota is applied to users without overrides in storage
+	Quota domain.Quota
 }
 
+const (
+	defaultSubscriberBuffer = 64
+	defaultPageSize         = 50
+	maximumPageSize         = 500
+)
+
 func New(log *slog.Logger, chatOptions ChatOptions, chatStorage ChatStorage) *ChatService {
-	return &ChatService{log: log, chatOptions: chatOptions, chatStorage: chatStorage}
+	if chatOptions.SubscriberBuffer <= 0 {
+		chatOptions.SubscriberBuffer = defaultSubscriberBuffer
+	}
+	hub := NewHub(chatOptions.SubscriberBuffer)
+	return &ChatService{log: log, chatOptions: chatOptions, chatStorage: chatStorage, hub: hub}
 }
 
 func (c *ChatService) NewChat(ctx context.Context, ownerUuid uuid.UUID, readonly bool, ttl int) (*domain.Chat, error) {
-	// Check how many chats we have already
-	chatsCount, err := c.chatStorage.ChatsCount(ctx)
-	if err != nil {
-		return nil, ErrInternal
-	}
-	if chatsCount >= c.chatOptions.MaximumCount {
-		return nil, ErrMaximumChats
+	// Check how many chats the owner has already
+	if err := c.checkChatQuota(ctx, ownerUuid); err != nil {
+		return nil, err
 	}
 	// Create new chat
 	if ttl == 0 {
@@ -77,15 +100,20 @@ func (c *ChatService) NewChat(ctx context.Context, ownerUuid uuid.UUID, readonly
 
 func (c *ChatService) NewMessage(ctx context.Context, chatUuid uuid.UUID, authorUuid uuid.UUID, message string) (*domain.Message, error) {
 	newMessage := domain.Message{AuthorUuid: authorUuid, Body: message, Published: time.Now()}
-	chat, err := c.chatStorage.GetChat(ctx, chatUuid)
+	chat, err := c.getChat(ctx, chatUuid)
 	if err != nil {
-		if errors.Is(err, storage.ErrChatNotFound) {
-			return nil, ErrChatNotFound
-		}
-		return nil, ErrInternal
+		return nil, err
+	}
+	// Only admins and the owner can write into readonly chats
+	required := domain.RoleWriter
+	if chat.Readonly {
+		required = domain.RoleAdmin
 	}
-	if chat.Readonly && chat.Owner.Uuid != authorUuid {
-		return nil, ErrPermissionDenied
+	if _, err := c.authorize(ctx, chat, authorUuid, required); err != nil {
+		return nil, err
+	}
+	if err := c.checkMessageQuota(ctx, authorUuid, len(message)); err != nil {
+		return nil, err
 	}
 	createdMessage, err := c.chatStorage.PostMessage(ctx, chatUuid, newMessage)
 	if err != nil {
@@ -95,14 +123,129 @@ func (c *ChatService) NewMessage(ctx context.Context, chatUuid uuid.UUID, author
 	if err != nil {
 		c.log.Error("error with during messages triming", sl.Err(err))
 	}
-	fmt.Println(createdMessage)
+	c.hub.Publish(chatUuid, createdMessage)
 	return createdMessage, nil
 }
 
-func (c *ChatService) ChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error) {
-	res, err := c.chatStorage.GetChatHistory(ctx, chatUuid)
+func (c *ChatService) ChatHistory(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID, query domain.HistoryQuery) (*domain.HistoryPage, error) {
+	if query.BeforeId > 0 && query.AfterId > 0 {
+		return nil, ErrInvalidCursor
+	}
+	chat, err := c.getChat(ctx, chatUuid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.authorize(ctx, chat, userUuid, domain.RoleReader); err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maximumPageSize {
+		query.Limit = maximumPageSize
+	}
+	limit := query.Limit
+
+	// Ask for one extra message to know if there is a next page
+	query.Limit++
+	res, err := c.chatStorage.GetChatHistoryPage(ctx, chatUuid, query)
 	if err != nil {
 		return nil, ErrInternal
 	}
-	return res, nil
+
+	page := domain.HistoryPage{Messages: res}
+	if len(res) > limit {
+		if query.AfterId > 0 {
+			page.Messages = res[:limit]
+			page.NextCursor = page.Messages[limit-1].Id
+		} else {
+			page.Messages = res[1:]
+			page.NextCursor = page.Messages[0].Id
+		}
+	}
+	return &page, nil
+}
+
+// Subscribe returns a channel with new messages of the chat.
+// Messages posted after lastSeenId are replayed first, so a reconnecting client misses nothing.
+// The channel is closed when ctx is done or when the subscriber is evicted for being too slow.
+func (c *ChatService) Subscribe(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID, lastSeenId int) (<-chan *domain.Message, error) {
+	chat, err := c.getChat(ctx, chatUuid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.authorize(ctx, chat, userUuid, domain.RoleReader); err != nil {
+		return nil, err
+	}
+
+	// Subscribe before reading history, otherwise messages posted in between are lost
+	sub := c.hub.Subscribe(chatUuid)
+
+	var missed []*domain.Message
+	if lastSeenId > 0 {
+		query := domain.HistoryQuery{AfterId: lastSeenId, Limit: maximumPageSize}
+		for {
+			page, err := c.chatStorage.GetChatHistoryPage(ctx, chatUuid, query)
+			if err != nil {
+				c.hub.Unsubscribe(sub)
+				return nil, ErrInternal
+			}
+			missed = append(missed, page...)
+			if len(page) < query.Limit {
+				break
+			}
+			query.AfterId = page[len(page)-1].Id
+		}
+	}
+
+	out := make(chan *domain.Message)
+	go c.forward(ctx, sub, missed, out)
+
+	return out, nil
+}
+
+func (c *ChatService) forward(ctx context.Context, sub *Subscriber, missed []*domain.Message, out chan<- *domain.Message) {
+	defer close(out)
+	defer c.hub.Unsubscribe(sub)
+
+	// Live messages may duplicate the replayed ones. Only those are skipped,
+	// concurrent posts can be published out of order and an older id isn't a duplicate.
+	replayed := make(map[int]struct{}, len(missed))
+	for _, message := range missed {
+		replayed[message.Id] = struct{}{}
+	}
+
+	send := func(message *domain.Message) bool {
+		select {
+		case out <- message:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for _, message := range missed {
+		if !send(message) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case message, ok := <-sub.Messages():
+			if !ok {
+				c.log.Warn("subscriber evicted", slog.String("chat_uuid", sub.chatUuid.String()))
+				return
+			}
+			if _, ok := replayed[message.Id]; ok {
+				delete(replayed, message.Id)
+				continue
+			}
+			if !send(message) {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
 }
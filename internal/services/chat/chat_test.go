@@ -2,12 +2,14 @@ package chat
 
 import (
 	"context"
+	"log/slog"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
 	"github.com/alexandernizov/grpcmessanger/internal/services/chat/mocks"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,6 +18,7 @@ var (
 	ownerUuidTest = uuid.MustParse("8ee4e645-b894-4477-820b-48381e10677f")
 	ownerTest     = domain.User{Uuid: ownerUuidTest, Login: "test", PasswordHash: []byte("test")}
 	chatUuidTest  = uuid.MustParse("30d88aa9-b8a5-4cfb-af4b-c043278e111e")
+	userUuidTest  = uuid.MustParse("6a3c2f5e-52c6-4d4e-9f3e-0d5b1f0a7c11")
 	deadlineTest  = time.Now()
 	publishedTest = time.Now()
 )
@@ -32,8 +35,10 @@ func NewMockService(t *testing.T, inputMocks []mockArgs) *ChatService {
 		chatStorage.On(m.methodName, m.arguments...).Return(m.returning...).Once()
 	}
 	mockService := ChatService{
+		log:         slog.Default(),
 		chatStorage: chatStorage,
-		chatOptions: ChatOptions{MaximumCount: 1, MaximumMessages: 1},
+		chatOptions: ChatOptions{MaximumMessages: 1},
+		hub:         NewHub(defaultSubscriberBuffer),
 	}
 	return &mockService
 }
@@ -59,7 +64,7 @@ func TestChatService_NewChat(t *testing.T) {
 				ttl:       10,
 			},
 			mockArgs: []mockArgs{
-				{methodName: "ChatsCount", arguments: []any{mock.Anything}, returning: []any{0, nil}},
+				noQuotaMock(ownerUuidTest),
 				{methodName: "CreateChat", arguments: []any{mock.Anything, mock.Anything}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest, Readonly: false, Deadline: deadlineTest}, nil}},
 			},
 			want:    &domain.Chat{Uuid: chatUuidTest, Owner: ownerTest, Readonly: false, Deadline: deadlineTest},
@@ -105,6 +110,7 @@ func TestChatService_NewMessage(t *testing.T) {
 			},
 			mockArgs: []mockArgs{
 				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest, Readonly: false, Deadline: deadlineTest}, nil}},
+				noQuotaMock(ownerUuidTest),
 				{methodName: "PostMessage", arguments: []any{mock.Anything, chatUuidTest, mock.Anything}, returning: []any{&domain.Message{Id: 1, AuthorUuid: ownerUuidTest, Body: "test", Published: publishedTest}, nil}},
 				{methodName: "TrimMessages", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{true, nil}},
 			},
@@ -131,12 +137,14 @@ func TestChatService_ChatHistory(t *testing.T) {
 	type funcArgs struct {
 		ctx      context.Context
 		chatUuid uuid.UUID
+		userUuid uuid.UUID
+		query    domain.HistoryQuery
 	}
 	tests := []struct {
 		name     string
 		funcArgs funcArgs
 		mockArgs []mockArgs
-		want     []*domain.Message
+		want     *domain.HistoryPage
 		wantErr  bool
 	}{
 		{
@@ -144,18 +152,90 @@ func TestChatService_ChatHistory(t *testing.T) {
 			funcArgs: funcArgs{
 				ctx:      context.TODO(),
 				chatUuid: chatUuidTest,
+				userUuid: ownerUuidTest,
 			},
 			mockArgs: []mockArgs{
-				{methodName: "GetChatHistory", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{[]*domain.Message{}, nil}},
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+				{methodName: "GetChatHistoryPage", arguments: []any{mock.Anything, chatUuidTest, domain.HistoryQuery{Limit: defaultPageSize + 1}}, returning: []any{[]*domain.Message{}, nil}},
 			},
-			want:    []*domain.Message{},
+			want:    &domain.HistoryPage{Messages: []*domain.Message{}},
 			wantErr: false,
 		},
+		{
+			name: "newest_page_has_more",
+			funcArgs: funcArgs{
+				ctx:      context.TODO(),
+				chatUuid: chatUuidTest,
+				userUuid: ownerUuidTest,
+				query:    domain.HistoryQuery{Limit: 2},
+			},
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+				{methodName: "GetChatHistoryPage", arguments: []any{mock.Anything, chatUuidTest, domain.HistoryQuery{Limit: 3}}, returning: []any{[]*domain.Message{{Id: 3}, {Id: 4}, {Id: 5}}, nil}},
+			},
+			want:    &domain.HistoryPage{Messages: []*domain.Message{{Id: 4}, {Id: 5}}, NextCursor: 4},
+			wantErr: false,
+		},
+		{
+			name: "forward_page_has_more",
+			funcArgs: funcArgs{
+				ctx:      context.TODO(),
+				chatUuid: chatUuidTest,
+				userUuid: ownerUuidTest,
+				query:    domain.HistoryQuery{Limit: 2, AfterId: 2},
+			},
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+				{methodName: "GetChatHistoryPage", arguments: []any{mock.Anything, chatUuidTest, domain.HistoryQuery{Limit: 3, AfterId: 2}}, returning: []any{[]*domain.Message{{Id: 3}, {Id: 4}, {Id: 5}}, nil}},
+			},
+			want:    &domain.HistoryPage{Messages: []*domain.Message{{Id: 3}, {Id: 4}}, NextCursor: 4},
+			wantErr: false,
+		},
+		{
+			name: "last_page",
+			funcArgs: funcArgs{
+				ctx:      context.TODO(),
+				chatUuid: chatUuidTest,
+				userUuid: ownerUuidTest,
+				query:    domain.HistoryQuery{Limit: 2, BeforeId: 3},
+			},
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+				{methodName: "GetChatHistoryPage", arguments: []any{mock.Anything, chatUuidTest, domain.HistoryQuery{Limit: 3, BeforeId: 3}}, returning: []any{[]*domain.Message{{Id: 1}, {Id: 2}}, nil}},
+			},
+			want:    &domain.HistoryPage{Messages: []*domain.Message{{Id: 1}, {Id: 2}}},
+			wantErr: false,
+		},
+		{
+			name: "both_cursors",
+			funcArgs: funcArgs{
+				ctx:      context.TODO(),
+				chatUuid: chatUuidTest,
+				userUuid: ownerUuidTest,
+				query:    domain.HistoryQuery{BeforeId: 3, AfterId: 1},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "not_a_member",
+			funcArgs: funcArgs{
+				ctx:      context.TODO(),
+				chatUuid: chatUuidTest,
+				userUuid: userUuidTest,
+			},
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+				{methodName: "GetMember", arguments: []any{mock.Anything, chatUuidTest, userUuidTest}, returning: []any{nil, storage.ErrMemberNotFound}},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewMockService(t, tt.mockArgs)
-			got, err := c.ChatHistory(tt.funcArgs.ctx, tt.funcArgs.chatUuid)
+			got, err := c.ChatHistory(tt.funcArgs.ctx, tt.funcArgs.chatUuid, tt.funcArgs.userUuid, tt.funcArgs.query)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ChatService.ChatHistory() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -166,3 +246,78 @@ func TestChatService_ChatHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestChatService_Subscribe(t *testing.T) {
+	mockArgs := []mockArgs{
+		{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+		{methodName: "GetChatHistoryPage", arguments: []any{mock.Anything, chatUuidTest, domain.HistoryQuery{AfterId: 1, Limit: maximumPageSize}}, returning: []any{[]*domain.Message{
+			{Id: 2, Body: "second"},
+			{Id: 3, Body: "third"},
+		}, nil}},
+	}
+	c := NewMockService(t, mockArgs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, err := c.Subscribe(ctx, chatUuidTest, ownerUuidTest, 1)
+	if err != nil {
+		t.Fatalf("ChatService.Subscribe() error = %v", err)
+	}
+
+	// Already replayed message must not be sent twice
+	c.hub.Publish(chatUuidTest, &domain.Message{Id: 3, Body: "third"})
+	c.hub.Publish(chatUuidTest, &domain.Message{Id: 4, Body: "fourth"})
+
+	var got []int
+	for _, want := range []int{2, 3, 4} {
+		select {
+		case message := <-messages:
+			got = append(got, message.Id)
+		case <-time.After(time.Second):
+			t.Fatalf("ChatService.Subscribe() timeout waiting for message %d", want)
+		}
+	}
+	if !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("ChatService.Subscribe() = %v, want %v", got, []int{2, 3, 4})
+	}
+
+	cancel()
+	for range messages {
+	}
+	if count := c.hub.SubscribersCount(chatUuidTest); count != 0 {
+		t.Errorf("ChatService.Subscribe() left %d subscribers after cancel", count)
+	}
+}
+
+func TestChatService_Subscribe_OutOfOrder(t *testing.T) {
+	mockArgs := []mockArgs{
+		{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}},
+	}
+	c := NewMockService(t, mockArgs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, err := c.Subscribe(ctx, chatUuidTest, ownerUuidTest, 0)
+	if err != nil {
+		t.Fatalf("ChatService.Subscribe() error = %v", err)
+	}
+
+	// Concurrent posts can be published in another order than their ids
+	c.hub.Publish(chatUuidTest, &domain.Message{Id: 11, Body: "eleventh"})
+	c.hub.Publish(chatUuidTest, &domain.Message{Id: 10, Body: "tenth"})
+
+	var got []int
+	for _, want := range []int{11, 10} {
+		select {
+		case message := <-messages:
+			got = append(got, message.Id)
+		case <-time.After(time.Second):
+			t.Fatalf("ChatService.Subscribe() timeout waiting for message %d", want)
+		}
+	}
+	if !reflect.DeepEqual(got, []int{11, 10}) {
+		t.Errorf("ChatService.Subscribe() = %v, want %v", got, []int{11, 10})
+	}
+}
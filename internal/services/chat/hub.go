@@ -0,0 +1,85 @@
+package chat
+
+import (
+	"sync"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Hub fans out new messages to everyone subscribed to a chat.
+// A subscriber that can't keep up with its buffer is evicted: its channel is closed.
+type Hub struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[uuid.UUID]map[*Subscriber]struct{}
+}
+
+type Subscriber struct {
+	chatUuid uuid.UUID
+	messages chan *domain.Message
+}
+
+func NewHub(bufferSize int) *Hub {
+	return &Hub{bufferSize: bufferSize, subscribers: make(map[uuid.UUID]map[*Subscriber]struct{})}
+}
+
+func (s *Subscriber) Messages() <-chan *domain.Message {
+	return s.messages
+}
+
+func (h *Hub) Subscribe(chatUuid uuid.UUID) *Subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscriber{chatUuid: chatUuid, messages: make(chan *domain.Message, h.bufferSize)}
+	if h.subscribers[chatUuid] == nil {
+		h.subscribers[chatUuid] = make(map[*Subscriber]struct{})
+	}
+	h.subscribers[chatUuid][sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub)
+}
+
+func (h *Hub) Publish(chatUuid uuid.UUID, message *domain.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[chatUuid] {
+		select {
+		case sub.messages <- message:
+		default:
+			// Slow consumer, drop it instead of blocking the publisher
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *Hub) SubscribersCount(chatUuid uuid.UUID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers[chatUuid])
+}
+
+// remove must be called with h.mu held
+func (h *Hub) remove(sub *Subscriber) {
+	subs, ok := h.subscribers[sub.chatUuid]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.messages)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.chatUuid)
+	}
+}
@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_Publish(t *testing.T) {
+	hub := NewHub(1)
+	otherChat := uuid.New()
+
+	first := hub.Subscribe(chatUuidTest)
+	second := hub.Subscribe(chatUuidTest)
+	other := hub.Subscribe(otherChat)
+
+	message := &domain.Message{Id: 1, AuthorUuid: ownerUuidTest, Body: "test", Published: publishedTest}
+	hub.Publish(chatUuidTest, message)
+
+	assert.Equal(t, message, <-first.Messages())
+	assert.Equal(t, message, <-second.Messages())
+	assert.Len(t, other.Messages(), 0)
+}
+
+func TestHub_EvictSlowSubscriber(t *testing.T) {
+	hub := NewHub(1)
+
+	slow := hub.Subscribe(chatUuidTest)
+	fast := hub.Subscribe(chatUuidTest)
+
+	hub.Publish(chatUuidTest, &domain.Message{Id: 1})
+	<-fast.Messages()
+	hub.Publish(chatUuidTest, &domain.Message{Id: 2})
+
+	assert.Equal(t, 1, hub.SubscribersCount(chatUuidTest))
+
+	first, ok := <-slow.Messages()
+	assert.True(t, ok)
+	assert.Equal(t, 1, first.Id)
+	_, ok = <-slow.Messages()
+	assert.False(t, ok, "slow subscriber should be closed")
+
+	second, ok := <-fast.Messages()
+	assert.True(t, ok)
+	assert.Equal(t, 2, second.Id)
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	hub := NewHub(1)
+
+	sub := hub.Subscribe(chatUuidTest)
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+
+	_, ok := <-sub.Messages()
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.SubscribersCount(chatUuidTest))
+
+	// Publishing into a chat without subscribers is a no-op
+	hub.Publish(chatUuidTest, &domain.Message{Id: 1})
+}
@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+)
+
+// InviteMember adds a user to the chat or changes the role of an existing member.
+// Actors can only grant roles lower than their own and can't touch members of their level or above.
+func (c *ChatService) InviteMember(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, userUuid uuid.UUID, role domain.Role) (*domain.Member, error) {
+	if !role.Valid() || role == domain.RoleOwner {
+		return nil, ErrInvalidRole
+	}
+	chat, err := c.getChat(ctx, chatUuid)
+	if err != nil {
+		return nil, err
+	}
+	actorRole, err := c.authorize(ctx, chat, actorUuid, domain.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !actorRole.Above(role) {
+		return nil, ErrPermissionDenied
+	}
+	userRole, err := c.memberRole(ctx, chat, userUuid)
+	if err != nil {
+		return nil, err
+	}
+	if userRole != "" && !actorRole.Above(userRole) {
+		return nil, ErrPermissionDenied
+	}
+
+	member := domain.Member{ChatUuid: chatUuid, UserUuid: userUuid, Role: role, InvitedBy: actorUuid, Joined: time.Now()}
+	created, err := c.chatStorage.AddMember(ctx, member)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternal
+	}
+	return created, nil
+}
+
+// RemoveMember removes a user from the chat. Everyone but the owner can leave the chat on their own.
+func (c *ChatService) RemoveMember(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, userUuid uuid.UUID) error {
+	chat, err := c.getChat(ctx, chatUuid)
+	if err != nil {
+		return err
+	}
+	userRole, err := c.memberRole(ctx, chat, userUuid)
+	if err != nil {
+		return err
+	}
+	if userRole == "" {
+		return ErrMemberNotFound
+	}
+	if userRole == domain.RoleOwner {
+		return ErrPermissionDenied
+	}
+	if actorUuid != userUuid {
+		actorRole, err := c.authorize(ctx, chat, actorUuid, domain.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if !actorRole.Above(userRole) {
+			return ErrPermissionDenied
+		}
+	}
+
+	err = c.chatStorage.RemoveMember(ctx, chatUuid, userUuid)
+	if err != nil {
+		if errors.Is(err, storage.ErrMemberNotFound) {
+			return ErrMemberNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func (c *ChatService) ListMembers(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID) ([]*domain.Member, error) {
+	chat, err := c.getChat(ctx, chatUuid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.authorize(ctx, chat, actorUuid, domain.RoleReader); err != nil {
+		return nil, err
+	}
+
+	members, err := c.chatStorage.ListMembers(ctx, chatUuid)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return members, nil
+}
+
+func (c *ChatService) getChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat, error) {
+	chat, err := c.chatStorage.GetChat(ctx, chatUuid)
+	if err != nil {
+		if errors.Is(err, storage.ErrChatNotFound) {
+			return nil, ErrChatNotFound
+		}
+		return nil, ErrInternal
+	}
+	return chat, nil
+}
+
+// memberRole returns an empty role for users who aren't members of the chat
+func (c *ChatService) memberRole(ctx context.Context, chat *domain.Chat, userUuid uuid.UUID) (domain.Role, error) {
+	// Chats created before memberships existed have no member record for the owner
+	if chat.Owner.Uuid == userUuid {
+		return domain.RoleOwner, nil
+	}
+	member, err := c.chatStorage.GetMember(ctx, chat.Uuid, userUuid)
+	if err != nil {
+		if errors.Is(err, storage.ErrMemberNotFound) {
+			return "", nil
+		}
+		return "", ErrInternal
+	}
+	return member.Role, nil
+}
+
+func (c *ChatService) authorize(ctx context.Context, chat *domain.Chat, userUuid uuid.UUID, required domain.Role) (domain.Role, error) {
+	role, err := c.memberRole(ctx, chat, userUuid)
+	if err != nil {
+		return "", err
+	}
+	if !role.AtLeast(required) {
+		return "", ErrPermissionDenied
+	}
+	return role, nil
+}
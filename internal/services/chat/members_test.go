@@ -0,0 +1,207 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	adminUuidTest  = uuid.MustParse("0f1d0a8e-3b8c-4b57-9f1e-54d4c1c1a001")
+	writerUuidTest = uuid.MustParse("0f1d0a8e-3b8c-4b57-9f1e-54d4c1c1a002")
+	chatForTest    = &domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}
+)
+
+func memberMock(userUuid uuid.UUID, role domain.Role) mockArgs {
+	if role == "" {
+		return mockArgs{methodName: "GetMember", arguments: []any{mock.Anything, chatUuidTest, userUuid}, returning: []any{nil, storage.ErrMemberNotFound}}
+	}
+	return mockArgs{methodName: "GetMember", arguments: []any{mock.Anything, chatUuidTest, userUuid}, returning: []any{&domain.Member{ChatUuid: chatUuidTest, UserUuid: userUuid, Role: role}, nil}}
+}
+
+func TestChatService_InviteMember(t *testing.T) {
+	getChat := mockArgs{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}}
+	addMember := mockArgs{methodName: "AddMember", arguments: []any{mock.Anything, mock.Anything}, returning: []any{&domain.Member{ChatUuid: chatUuidTest, UserUuid: userUuidTest, Role: domain.RoleWriter}, nil}}
+
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		role     domain.Role
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name:     "owner_invites_admin",
+			actor:    ownerUuidTest,
+			role:     domain.RoleAdmin,
+			mockArgs: []mockArgs{getChat, memberMock(userUuidTest, ""), addMember},
+		},
+		{
+			name:     "admin_invites_writer",
+			actor:    adminUuidTest,
+			role:     domain.RoleWriter,
+			mockArgs: []mockArgs{getChat, memberMock(adminUuidTest, domain.RoleAdmin), memberMock(userUuidTest, ""), addMember},
+		},
+		{
+			name:     "admin_can't_grant_admin",
+			actor:    adminUuidTest,
+			role:     domain.RoleAdmin,
+			mockArgs: []mockArgs{getChat, memberMock(adminUuidTest, domain.RoleAdmin)},
+			wantErr:  ErrPermissionDenied,
+		},
+		{
+			name:     "admin_can't_demote_admin",
+			actor:    adminUuidTest,
+			role:     domain.RoleReader,
+			mockArgs: []mockArgs{getChat, memberMock(adminUuidTest, domain.RoleAdmin), memberMock(userUuidTest, domain.RoleAdmin)},
+			wantErr:  ErrPermissionDenied,
+		},
+		{
+			name:     "writer_can't_invite",
+			actor:    writerUuidTest,
+			role:     domain.RoleReader,
+			mockArgs: []mockArgs{getChat, memberMock(writerUuidTest, domain.RoleWriter)},
+			wantErr:  ErrPermissionDenied,
+		},
+		{
+			name:    "owner_role_can't_be_granted",
+			actor:   ownerUuidTest,
+			role:    domain.RoleOwner,
+			wantErr: ErrInvalidRole,
+		},
+		{
+			name:  "user_not_found",
+			actor: ownerUuidTest,
+			role:  domain.RoleReader,
+			mockArgs: []mockArgs{getChat, memberMock(userUuidTest, ""),
+				{methodName: "AddMember", arguments: []any{mock.Anything, mock.Anything}, returning: []any{nil, storage.ErrUserNotFound}},
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			_, err := c.InviteMember(context.TODO(), chatUuidTest, tt.actor, userUuidTest, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChatService.InviteMember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChatService_RemoveMember(t *testing.T) {
+	getChat := mockArgs{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}}
+	removeMember := mockArgs{methodName: "RemoveMember", arguments: []any{mock.Anything, chatUuidTest, userUuidTest}, returning: []any{nil}}
+
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		user     uuid.UUID
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name:     "admin_removes_writer",
+			actor:    adminUuidTest,
+			user:     userUuidTest,
+			mockArgs: []mockArgs{getChat, memberMock(userUuidTest, domain.RoleWriter), memberMock(adminUuidTest, domain.RoleAdmin), removeMember},
+		},
+		{
+			name:     "member_leaves",
+			actor:    userUuidTest,
+			user:     userUuidTest,
+			mockArgs: []mockArgs{getChat, memberMock(userUuidTest, domain.RoleReader), removeMember},
+		},
+		{
+			name:     "owner_can't_leave",
+			actor:    ownerUuidTest,
+			user:     ownerUuidTest,
+			mockArgs: []mockArgs{getChat},
+			wantErr:  ErrPermissionDenied,
+		},
+		{
+			name:     "writer_can't_remove",
+			actor:    writerUuidTest,
+			user:     userUuidTest,
+			mockArgs: []mockArgs{getChat, memberMock(userUuidTest, domain.RoleReader), memberMock(writerUuidTest, domain.RoleWriter)},
+			wantErr:  ErrPermissionDenied,
+		},
+		{
+			name:     "not_a_member",
+			actor:    ownerUuidTest,
+			user:     userUuidTest,
+			mockArgs: []mockArgs{getChat, memberMock(userUuidTest, "")},
+			wantErr:  ErrMemberNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			err := c.RemoveMember(context.TODO(), chatUuidTest, tt.actor, tt.user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChatService.RemoveMember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChatService_NewMessagePermissions(t *testing.T) {
+	readonlyChat := &domain.Chat{Uuid: chatUuidTest, Owner: ownerTest, Readonly: true}
+	posted := &domain.Message{Id: 1, AuthorUuid: userUuidTest, Body: "test"}
+
+	tests := []struct {
+		name     string
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name: "writer_posts",
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}},
+				memberMock(userUuidTest, domain.RoleWriter),
+				noQuotaMock(userUuidTest),
+				{methodName: "PostMessage", arguments: []any{mock.Anything, chatUuidTest, mock.Anything}, returning: []any{posted, nil}},
+				{methodName: "TrimMessages", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{true, nil}},
+			},
+		},
+		{
+			name: "reader_can't_post",
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}},
+				memberMock(userUuidTest, domain.RoleReader),
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "writer_can't_post_into_readonly",
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{readonlyChat, nil}},
+				memberMock(userUuidTest, domain.RoleWriter),
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "stranger_can't_post",
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}},
+				memberMock(userUuidTest, ""),
+			},
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			_, err := c.NewMessage(context.TODO(), chatUuidTest, userUuidTest, "test")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChatService.NewMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
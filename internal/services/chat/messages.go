@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+)
+
+// EditMessage replaces the text of the message, the previous one is kept in its edit history.
+// Only the author of the message and the owner of the chat can edit it.
+// A longer text is checked against the message bytes quota of the author.
+func (c *ChatService) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string) (*domain.Message, error) {
+	message, err := c.authorizeMessageChange(ctx, chatUuid, actorUuid, messageId)
+	if err != nil {
+		return nil, err
+	}
+	if grown := len(body) - len(message.Body); grown > 0 {
+		if err := c.checkEditQuota(ctx, message.AuthorUuid, grown); err != nil {
+			return nil, err
+		}
+	}
+
+	edited, err := c.chatStorage.EditMessage(ctx, chatUuid, actorUuid, messageId, body, time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrMessageNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, ErrInternal
+	}
+	return edited, nil
+}
+
+// DeleteMessage replaces the message with a tombstone.
+// Only the author of the message and the owner of the chat can delete it.
+func (c *ChatService) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int) error {
+	if _, err := c.authorizeMessageChange(ctx, chatUuid, actorUuid, messageId); err != nil {
+		return err
+	}
+
+	_, err := c.chatStorage.DeleteMessage(ctx, chatUuid, actorUuid, messageId, time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrMessageNotFound) {
+			return ErrMessageNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func (c *ChatService) authorizeMessageChange(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	chat, err := c.getChat(ctx, chatUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := c.chatStorage.GetMessage(ctx, chatUuid, messageId)
+	if err != nil {
+		if errors.Is(err, storage.ErrMessageNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, ErrInternal
+	}
+	if message.Deleted() {
+		return nil, ErrMessageNotFound
+	}
+
+	if message.AuthorUuid != actorUuid && chat.Owner.Uuid != actorUuid {
+		return nil, ErrPermissionDenied
+	}
+	return message, nil
+}
@@ -0,0 +1,130 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChatService_EditMessage(t *testing.T) {
+	getChat := mockArgs{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}}
+	authored := &domain.Message{Id: 1, AuthorUuid: userUuidTest, Body: "old", Published: publishedTest}
+	edited := &domain.Message{Id: 1, AuthorUuid: userUuidTest, Body: "new", Published: publishedTest,
+		Edits: []domain.MessageEdit{{Body: "old", Edited: publishedTest}}}
+
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name:  "author_edits",
+			actor: userUuidTest,
+			mockArgs: []mockArgs{getChat,
+				{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{authored, nil}},
+				{methodName: "EditMessage", arguments: []any{mock.Anything, chatUuidTest, userUuidTest, 1, "new", mock.Anything}, returning: []any{edited, nil}},
+			},
+		},
+		{
+			name:  "owner_edits",
+			actor: ownerUuidTest,
+			mockArgs: []mockArgs{getChat,
+				{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{authored, nil}},
+				{methodName: "EditMessage", arguments: []any{mock.Anything, chatUuidTest, ownerUuidTest, 1, "new", mock.Anything}, returning: []any{edited, nil}},
+			},
+		},
+		{
+			name:  "admin_can't_edit",
+			actor: adminUuidTest,
+			mockArgs: []mockArgs{getChat,
+				{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{authored, nil}},
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:  "deleted_message",
+			actor: userUuidTest,
+			mockArgs: []mockArgs{getChat,
+				{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{&domain.Message{Id: 1, AuthorUuid: userUuidTest, DeletedAt: publishedTest}, nil}},
+			},
+			wantErr: ErrMessageNotFound,
+		},
+		{
+			name:  "message_not_found",
+			actor: userUuidTest,
+			mockArgs: []mockArgs{getChat,
+				{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{nil, storage.ErrMessageNotFound}},
+			},
+			wantErr: ErrMessageNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			res, err := c.EditMessage(context.TODO(), chatUuidTest, tt.actor, 1, "new")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChatService.EditMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && res.Body != "new" {
+				t.Errorf("ChatService.EditMessage() body = %v, want new", res.Body)
+			}
+		})
+	}
+}
+
+func TestChatService_DeleteMessage(t *testing.T) {
+	getChat := mockArgs{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}}
+	getMessage := mockArgs{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{&domain.Message{Id: 1, AuthorUuid: userUuidTest, Body: "test"}, nil}}
+
+	tests := []struct {
+		name     string
+		actor    uuid.UUID
+		mockArgs []mockArgs
+		wantErr  error
+	}{
+		{
+			name:  "author_deletes",
+			actor: userUuidTest,
+			mockArgs: []mockArgs{getChat, getMessage,
+				{methodName: "DeleteMessage", arguments: []any{mock.Anything, chatUuidTest, userUuidTest, 1, mock.Anything}, returning: []any{&domain.Message{Id: 1, DeletedAt: publishedTest}, nil}},
+			},
+		},
+		{
+			name:     "stranger_can't_delete",
+			actor:    writerUuidTest,
+			mockArgs: []mockArgs{getChat, getMessage},
+			wantErr:  ErrPermissionDenied,
+		},
+		{
+			name:  "deleted_concurrently",
+			actor: ownerUuidTest,
+			mockArgs: []mockArgs{getChat, getMessage,
+				{methodName: "DeleteMessage", arguments: []any{mock.Anything, chatUuidTest, ownerUuidTest, 1, mock.Anything}, returning: []any{nil, storage.ErrMessageNotFound}},
+			},
+			wantErr: ErrMessageNotFound,
+		},
+		{
+			name:  "chat_not_found",
+			actor: userUuidTest,
+			mockArgs: []mockArgs{
+				{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{nil, storage.ErrChatNotFound}},
+			},
+			wantErr: ErrChatNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			err := c.DeleteMessage(context.TODO(), chatUuidTest, tt.actor, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChatService.DeleteMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,11 +4,11 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	domain "github.com/alexandernizov/grpcmessanger/internal/domain"
-	mock "github.com/stretchr/testify/mock"
-
 	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
 )
 
 // ChatStorage is an autogenerated mock type for the ChatStorage type
@@ -16,23 +16,25 @@ type ChatStorage struct {
 	mock.Mock
 }
 
-// ChatsCount provides a mock function with given fields: ctx
-func (_m *ChatStorage) ChatsCount(ctx context.Context) (int, error) {
-	ret := _m.Called(ctx)
+// AddMember provides a mock function with given fields: ctx, member
+func (_m *ChatStorage) AddMember(ctx context.Context, member domain.Member) (*domain.Member, error) {
+	ret := _m.Called(ctx, member)
 
-	var r0 int
+	var r0 *domain.Member
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Member) (*domain.Member, error)); ok {
+		return rf(ctx, member)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Member) *domain.Member); ok {
+		r0 = rf(ctx, member)
 	} else {
-		r0 = ret.Get(0).(int)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Member)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Member) error); ok {
+		r1 = rf(ctx, member)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -66,6 +68,58 @@ func (_m *ChatStorage) CreateChat(ctx context.Context, _a1 domain.Chat) (*domain
 	return r0, r1
 }
 
+// DeleteMessage provides a mock function with given fields: ctx, chatUuid, actorUuid, messageId, deleted
+func (_m *ChatStorage) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, deleted time.Time) (*domain.Message, error) {
+	ret := _m.Called(ctx, chatUuid, actorUuid, messageId, deleted)
+
+	var r0 *domain.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int, time.Time) (*domain.Message, error)); ok {
+		return rf(ctx, chatUuid, actorUuid, messageId, deleted)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int, time.Time) *domain.Message); ok {
+		r0 = rf(ctx, chatUuid, actorUuid, messageId, deleted)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, int, time.Time) error); ok {
+		r1 = rf(ctx, chatUuid, actorUuid, messageId, deleted)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EditMessage provides a mock function with given fields: ctx, chatUuid, actorUuid, messageId, body, edited
+func (_m *ChatStorage) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string, edited time.Time) (*domain.Message, error) {
+	ret := _m.Called(ctx, chatUuid, actorUuid, messageId, body, edited)
+
+	var r0 *domain.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int, string, time.Time) (*domain.Message, error)); ok {
+		return rf(ctx, chatUuid, actorUuid, messageId, body, edited)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, int, string, time.Time) *domain.Message); ok {
+		r0 = rf(ctx, chatUuid, actorUuid, messageId, body, edited)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, int, string, time.Time) error); ok {
+		r1 = rf(ctx, chatUuid, actorUuid, messageId, body, edited)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChat provides a mock function with given fields: ctx, chatUuid
 func (_m *ChatStorage) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat, error) {
 	ret := _m.Called(ctx, chatUuid)
@@ -92,20 +146,124 @@ func (_m *ChatStorage) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain
 	return r0, r1
 }
 
-// GetChatHistory provides a mock function with given fields: ctx, chatUuid
-func (_m *ChatStorage) GetChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error) {
-	ret := _m.Called(ctx, chatUuid)
+// GetChatHistoryPage provides a mock function with given fields: ctx, chatUuid, query
+func (_m *ChatStorage) GetChatHistoryPage(ctx context.Context, chatUuid uuid.UUID, query domain.HistoryQuery) ([]*domain.Message, error) {
+	ret := _m.Called(ctx, chatUuid, query)
 
 	var r0 []*domain.Message
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*domain.Message, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, domain.HistoryQuery) ([]*domain.Message, error)); ok {
+		return rf(ctx, chatUuid, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, domain.HistoryQuery) []*domain.Message); ok {
+		r0 = rf(ctx, chatUuid, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, domain.HistoryQuery) error); ok {
+		r1 = rf(ctx, chatUuid, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMember provides a mock function with given fields: ctx, chatUuid, userUuid
+func (_m *ChatStorage) GetMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error) {
+	ret := _m.Called(ctx, chatUuid, userUuid)
+
+	var r0 *domain.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (*domain.Member, error)); ok {
+		return rf(ctx, chatUuid, userUuid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) *domain.Member); ok {
+		r0 = rf(ctx, chatUuid, userUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, chatUuid, userUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessage provides a mock function with given fields: ctx, chatUuid, messageId
+func (_m *ChatStorage) GetMessage(ctx context.Context, chatUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	ret := _m.Called(ctx, chatUuid, messageId)
+
+	var r0 *domain.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) (*domain.Message, error)); ok {
+		return rf(ctx, chatUuid, messageId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) *domain.Message); ok {
+		r0 = rf(ctx, chatUuid, messageId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, int) error); ok {
+		r1 = rf(ctx, chatUuid, messageId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserQuota provides a mock function with given fields: ctx, userUuid
+func (_m *ChatStorage) GetUserQuota(ctx context.Context, userUuid uuid.UUID) (*domain.Quota, error) {
+	ret := _m.Called(ctx, userUuid)
+
+	var r0 *domain.Quota
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*domain.Quota, error)); ok {
+		return rf(ctx, userUuid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *domain.Quota); ok {
+		r0 = rf(ctx, userUuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Quota)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userUuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMembers provides a mock function with given fields: ctx, chatUuid
+func (_m *ChatStorage) ListMembers(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Member, error) {
+	ret := _m.Called(ctx, chatUuid)
+
+	var r0 []*domain.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*domain.Member, error)); ok {
 		return rf(ctx, chatUuid)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*domain.Message); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*domain.Member); ok {
 		r0 = rf(ctx, chatUuid)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*domain.Message)
+			r0 = ret.Get(0).([]*domain.Member)
 		}
 	}
 
@@ -144,6 +302,46 @@ func (_m *ChatStorage) PostMessage(ctx context.Context, _a1 uuid.UUID, message d
 	return r0, r1
 }
 
+// QuotaUsage provides a mock function with given fields: ctx, userUuid, since
+func (_m *ChatStorage) QuotaUsage(ctx context.Context, userUuid uuid.UUID, since time.Time) (*domain.QuotaUsage, error) {
+	ret := _m.Called(ctx, userUuid, since)
+
+	var r0 *domain.QuotaUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) (*domain.QuotaUsage, error)); ok {
+		return rf(ctx, userUuid, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) *domain.QuotaUsage); ok {
+		r0 = rf(ctx, userUuid, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.QuotaUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r1 = rf(ctx, userUuid, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: ctx, chatUuid, userUuid
+func (_m *ChatStorage) RemoveMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) error {
+	ret := _m.Called(ctx, chatUuid, userUuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, chatUuid, userUuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TrimMessages provides a mock function with given fields: ctx, _a1, maximumMessages
 func (_m *ChatStorage) TrimMessages(ctx context.Context, _a1 uuid.UUID, maximumMessages int) (bool, error) {
 	ret := _m.Called(ctx, _a1, maximumMessages)
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ExpiredChatsStorage is an autogenerated mock type for the ExpiredChatsStorage type
+type ExpiredChatsStorage struct {
+	mock.Mock
+}
+
+// DeleteExpiredChats provides a mock function with given fields: ctx, now, limit
+func (_m *ExpiredChatsStorage) DeleteExpiredChats(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]uuid.UUID, error)); ok {
+		return rf(ctx, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []uuid.UUID); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewExpiredChatsStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewExpiredChatsStorage creates a new instance of ExpiredChatsStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewExpiredChatsStorage(t mockConstructorTestingTNewExpiredChatsStorage) *ExpiredChatsStorage {
+	mock := &ExpiredChatsStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
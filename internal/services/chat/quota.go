@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	QuotaChatsOwned        = "chats_owned"
+	QuotaMessagesPerMinute = "messages_per_minute"
+	QuotaMessageBytes      = "message_bytes"
+)
+
+// QuotaError tells which quota the user has run out of
+type QuotaError struct {
+	Quota string
+	Limit int64
+	Used  int64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s is limited to %d, %d used", ErrQuotaExceeded, e.Quota, e.Limit, e.Used)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// userQuota applies overrides of the user to the default quota
+func (c *ChatService) userQuota(ctx context.Context, userUuid uuid.UUID) (domain.Quota, error) {
+	override, err := c.chatStorage.GetUserQuota(ctx, userUuid)
+	if errors.Is(err, storage.ErrQuotaNotFound) {
+		return c.chatOptions.Quota, nil
+	}
+	if err != nil {
+		return domain.Quota{}, ErrInternal
+	}
+	return c.chatOptions.Quota.Override(*override), nil
+}
+
+// The checks are soft: concurrent requests of the same user can go a little over the limits
+func (c *ChatService) checkChatQuota(ctx context.Context, ownerUuid uuid.UUID) error {
+	quota, err := c.userQuota(ctx, ownerUuid)
+	if err != nil {
+		return err
+	}
+	if quota.ChatsOwned <= 0 {
+		return nil
+	}
+	usage, err := c.chatStorage.QuotaUsage(ctx, ownerUuid, time.Now().Add(-time.Minute))
+	if err != nil {
+		return ErrInternal
+	}
+	if usage.ChatsOwned >= quota.ChatsOwned {
+		return &QuotaError{Quota: QuotaChatsOwned, Limit: int64(quota.ChatsOwned), Used: int64(usage.ChatsOwned)}
+	}
+	return nil
+}
+
+func (c *ChatService) checkMessageQuota(ctx context.Context, authorUuid uuid.UUID, size int) error {
+	quota, err := c.userQuota(ctx, authorUuid)
+	if err != nil {
+		return err
+	}
+	if quota.MessagesPerMinute <= 0 && quota.MessageBytes <= 0 {
+		return nil
+	}
+	usage, err := c.chatStorage.QuotaUsage(ctx, authorUuid, time.Now().Add(-time.Minute))
+	if err != nil {
+		return ErrInternal
+	}
+	if quota.MessagesPerMinute > 0 && usage.MessagesLastMinute >= quota.MessagesPerMinute {
+		return &QuotaError{Quota: QuotaMessagesPerMinute, Limit: int64(quota.MessagesPerMinute), Used: int64(usage.MessagesLastMinute)}
+	}
+	if quota.MessageBytes > 0 && usage.MessageBytes+int64(size) > quota.MessageBytes {
+		return &QuotaError{Quota: QuotaMessageBytes, Limit: quota.MessageBytes, Used: usage.MessageBytes}
+	}
+	return nil
+}
+
+// checkEditQuota checks only the bytes, an edit isn't a new message. The bytes belong to the author,
+// even when the message is edited by the owner of the chat.
+func (c *ChatService) checkEditQuota(ctx context.Context, authorUuid uuid.UUID, grown int) error {
+	quota, err := c.userQuota(ctx, authorUuid)
+	if err != nil {
+		return err
+	}
+	if quota.MessageBytes <= 0 {
+		return nil
+	}
+	usage, err := c.chatStorage.QuotaUsage(ctx, authorUuid, time.Now().Add(-time.Minute))
+	if err != nil {
+		return ErrInternal
+	}
+	if usage.MessageBytes+int64(grown) > quota.MessageBytes {
+		return &QuotaError{Quota: QuotaMessageBytes, Limit: quota.MessageBytes, Used: usage.MessageBytes}
+	}
+	return nil
+}
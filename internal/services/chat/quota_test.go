@@ -0,0 +1,171 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func noQuotaMock(userUuid uuid.UUID) mockArgs {
+	return mockArgs{methodName: "GetUserQuota", arguments: []any{mock.Anything, userUuid}, returning: []any{nil, storage.ErrQuotaNotFound}}
+}
+
+func usageMock(userUuid uuid.UUID, usage domain.QuotaUsage) mockArgs {
+	return mockArgs{methodName: "QuotaUsage", arguments: []any{mock.Anything, userUuid, mock.Anything}, returning: []any{&usage, nil}}
+}
+
+func TestChatService_NewChatQuota(t *testing.T) {
+	createChat := mockArgs{methodName: "CreateChat", arguments: []any{mock.Anything, mock.Anything}, returning: []any{&domain.Chat{Uuid: chatUuidTest, Owner: ownerTest}, nil}}
+
+	tests := []struct {
+		name      string
+		defaults  domain.Quota
+		mockArgs  []mockArgs
+		wantQuota string
+	}{
+		{
+			name:     "under_default",
+			defaults: domain.Quota{ChatsOwned: 2},
+			mockArgs: []mockArgs{noQuotaMock(ownerUuidTest), usageMock(ownerUuidTest, domain.QuotaUsage{ChatsOwned: 1}), createChat},
+		},
+		{
+			name:      "default_exhausted",
+			defaults:  domain.Quota{ChatsOwned: 2},
+			mockArgs:  []mockArgs{noQuotaMock(ownerUuidTest), usageMock(ownerUuidTest, domain.QuotaUsage{ChatsOwned: 2})},
+			wantQuota: QuotaChatsOwned,
+		},
+		{
+			name:     "override_raises_limit",
+			defaults: domain.Quota{ChatsOwned: 2},
+			mockArgs: []mockArgs{
+				{methodName: "GetUserQuota", arguments: []any{mock.Anything, ownerUuidTest}, returning: []any{&domain.Quota{ChatsOwned: 10}, nil}},
+				usageMock(ownerUuidTest, domain.QuotaUsage{ChatsOwned: 2}),
+				createChat,
+			},
+		},
+		{
+			name:     "override_lifts_limit",
+			defaults: domain.Quota{ChatsOwned: 2},
+			mockArgs: []mockArgs{
+				{methodName: "GetUserQuota", arguments: []any{mock.Anything, ownerUuidTest}, returning: []any{&domain.Quota{ChatsOwned: -1}, nil}},
+				createChat,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			c.chatOptions.Quota = tt.defaults
+			_, err := c.NewChat(context.TODO(), ownerUuidTest, false, 10)
+			checkQuotaError(t, err, tt.wantQuota)
+		})
+	}
+}
+
+func TestChatService_NewMessageQuota(t *testing.T) {
+	getChat := mockArgs{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}}
+	postMessage := mockArgs{methodName: "PostMessage", arguments: []any{mock.Anything, chatUuidTest, mock.Anything}, returning: []any{&domain.Message{Id: 1, AuthorUuid: ownerUuidTest, Body: "test"}, nil}}
+	trimMessages := mockArgs{methodName: "TrimMessages", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{true, nil}}
+	defaults := domain.Quota{MessagesPerMinute: 2, MessageBytes: 10}
+
+	tests := []struct {
+		name      string
+		mockArgs  []mockArgs
+		wantQuota string
+	}{
+		{
+			name:     "under_quota",
+			mockArgs: []mockArgs{getChat, noQuotaMock(ownerUuidTest), usageMock(ownerUuidTest, domain.QuotaUsage{MessagesLastMinute: 1, MessageBytes: 6}), postMessage, trimMessages},
+		},
+		{
+			name:      "too_many_messages",
+			mockArgs:  []mockArgs{getChat, noQuotaMock(ownerUuidTest), usageMock(ownerUuidTest, domain.QuotaUsage{MessagesLastMinute: 2})},
+			wantQuota: QuotaMessagesPerMinute,
+		},
+		{
+			name:      "too_many_bytes",
+			mockArgs:  []mockArgs{getChat, noQuotaMock(ownerUuidTest), usageMock(ownerUuidTest, domain.QuotaUsage{MessageBytes: 7})},
+			wantQuota: QuotaMessageBytes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			c.chatOptions.Quota = defaults
+			_, err := c.NewMessage(context.TODO(), chatUuidTest, ownerUuidTest, "test")
+			checkQuotaError(t, err, tt.wantQuota)
+		})
+	}
+}
+
+func TestChatService_EditMessageQuota(t *testing.T) {
+	getChat := mockArgs{methodName: "GetChat", arguments: []any{mock.Anything, chatUuidTest}, returning: []any{chatForTest, nil}}
+	getMessage := mockArgs{methodName: "GetMessage", arguments: []any{mock.Anything, chatUuidTest, 1}, returning: []any{&domain.Message{Id: 1, AuthorUuid: userUuidTest, Body: "test"}, nil}}
+	editMessage := mockArgs{methodName: "EditMessage", arguments: []any{mock.Anything, chatUuidTest, mock.Anything, 1, mock.Anything, mock.Anything}, returning: []any{&domain.Message{Id: 1, AuthorUuid: userUuidTest, Body: "edited"}, nil}}
+	defaults := domain.Quota{MessagesPerMinute: 1, MessageBytes: 10}
+
+	tests := []struct {
+		name      string
+		actor     uuid.UUID
+		body      string
+		mockArgs  []mockArgs
+		wantQuota string
+	}{
+		{
+			name:     "shorter_isn't_checked",
+			actor:    userUuidTest,
+			body:     "new",
+			mockArgs: []mockArgs{getChat, getMessage, editMessage},
+		},
+		{
+			name:     "rate_isn't_checked",
+			actor:    userUuidTest,
+			body:     "edited",
+			mockArgs: []mockArgs{getChat, getMessage, noQuotaMock(userUuidTest), usageMock(userUuidTest, domain.QuotaUsage{MessagesLastMinute: 5, MessageBytes: 8}), editMessage},
+		},
+		{
+			name:      "too_many_bytes",
+			actor:     userUuidTest,
+			body:      "edited",
+			mockArgs:  []mockArgs{getChat, getMessage, noQuotaMock(userUuidTest), usageMock(userUuidTest, domain.QuotaUsage{MessageBytes: 9})},
+			wantQuota: QuotaMessageBytes,
+		},
+		{
+			name:      "owner_edit_counts_to_author",
+			actor:     ownerUuidTest,
+			body:      "edited",
+			mockArgs:  []mockArgs{getChat, getMessage, noQuotaMock(userUuidTest), usageMock(userUuidTest, domain.QuotaUsage{MessageBytes: 9})},
+			wantQuota: QuotaMessageBytes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMockService(t, tt.mockArgs)
+			c.chatOptions.Quota = defaults
+			_, err := c.EditMessage(context.TODO(), chatUuidTest, tt.actor, 1, tt.body)
+			checkQuotaError(t, err, tt.wantQuota)
+		})
+	}
+}
+
+func checkQuotaError(t *testing.T, err error, wantQuota string) {
+	t.Helper()
+	if wantQuota == "" {
+		if err != nil {
+			t.Errorf("unexpected error = %v", err)
+		}
+		return
+	}
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.Quota != wantQuota {
+		t.Errorf("error = %v, want %s quota error", err, wantQuota)
+	}
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("error = %v, want ErrQuotaExceeded", err)
+	}
+}
@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/google/uuid"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ExpiredChatsStorage
+type ExpiredChatsStorage interface {
+	DeleteExpiredChats(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+}
+
+const (
+	defaultReaperInterval  = time.Minute
+	defaultReaperBatchSize = 100
+)
+
+// Reaper periodically deletes chats whose deadline has passed.
+// Storages that expire chats by themselves, like redis, don't need it.
+type Reaper struct {
+	log       *slog.Logger
+	storage   ExpiredChatsStorage
+	interval  time.Duration
+	batchSize int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewReaper(log *slog.Logger, storage ExpiredChatsStorage, interval time.Duration) *Reaper {
+	if interval <= 0 {
+		interval = defaultReaperInterval
+	}
+	return &Reaper{log: log, storage: storage, interval: interval, batchSize: defaultReaperBatchSize, stop: make(chan struct{})}
+}
+
+func (r *Reaper) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.Reap(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop waits for the running pass to finish
+func (r *Reaper) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// Reap deletes expired chats batch by batch until none are left and returns how many were deleted
+func (r *Reaper) Reap(ctx context.Context) int {
+	const op = "chat.Reap"
+	log := r.log.With(slog.String("op", op))
+
+	total := 0
+	for {
+		expired, err := r.storage.DeleteExpiredChats(ctx, time.Now(), r.batchSize)
+		if err != nil {
+			log.Error("can't delete expired chats", sl.Err(err))
+			return total
+		}
+		total += len(expired)
+		if len(expired) < r.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Info("expired chats deleted", slog.Int("count", total))
+	}
+	return total
+}
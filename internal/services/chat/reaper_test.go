@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/services/chat/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReaper_Reap(t *testing.T) {
+	batch := func(n int) []uuid.UUID {
+		res := make([]uuid.UUID, n)
+		for i := range res {
+			res[i] = uuid.New()
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		batches  [][]uuid.UUID
+		err      error
+		want     int
+		wantRuns int
+	}{
+		{
+			name:     "nothing_expired",
+			batches:  [][]uuid.UUID{nil},
+			want:     0,
+			wantRuns: 1,
+		},
+		{
+			name:     "several_batches",
+			batches:  [][]uuid.UUID{batch(2), batch(2), batch(1)},
+			want:     5,
+			wantRuns: 3,
+		},
+		{
+			name:     "storage_error",
+			batches:  [][]uuid.UUID{batch(2)},
+			err:      errors.New("some error"),
+			want:     2,
+			wantRuns: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewExpiredChatsStorage(t)
+			for _, b := range tt.batches {
+				storage.On("DeleteExpiredChats", mock.Anything, mock.Anything, 2).Return(b, nil).Once()
+			}
+			if tt.err != nil {
+				storage.On("DeleteExpiredChats", mock.Anything, mock.Anything, 2).Return(nil, tt.err).Once()
+			}
+			r := NewReaper(slog.Default(), storage, 0)
+			r.batchSize = 2
+
+			got := r.Reap(context.Background())
+			if got != tt.want {
+				t.Errorf("Reaper.Reap() = %v, want %v", got, tt.want)
+			}
+			storage.AssertNumberOfCalls(t, "DeleteExpiredChats", tt.wantRuns)
+		})
+	}
+}
@@ -7,9 +7,15 @@ var (
 
 	ErrInternal = errors.New("internal error")
 
-	ErrUserNotFound  = errors.New("user is not found")
-	ErrTokenNotFound = errors.New("token is not found")
-	ErrChatNotFound  = errors.New("chat is not found")
+	ErrUserNotFound    = errors.New("user is not found")
+	ErrSessionNotFound = errors.New("session is not found")
+	ErrChatNotFound    = errors.New("chat is not found")
 
-	ErrNoOutbox = errors.New("have no outbox to send")
+	ErrMemberNotFound  = errors.New("member is not found")
+	ErrMessageNotFound = errors.New("message is not found")
+
+	ErrQuotaNotFound = errors.New("quota is not found")
+
+	ErrOutboxNotFound     = errors.New("outbox is not found")
+	ErrDeadLetterNotFound = errors.New("dead letter is not found")
 )
@@ -0,0 +1,17 @@
+package inmemory_test
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/alexandernizov/grpcmessanger/internal/storage/inmemory"
+	"github.com/alexandernizov/grpcmessanger/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return inmemory.New(log)
+	})
+}
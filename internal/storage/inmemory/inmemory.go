@@ -3,36 +3,80 @@ package inmemory
 import (
 	"context"
 	"log/slog"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
 	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/google/uuid"
 
 	"github.com/alexandernizov/grpcmessanger/api/gen/outbox"
-	"google.golang.org/protobuf/proto"
 )
 
+// Inmemory keeps everything in maps guarded by a single lock.
+// It's meant for local development and integration tests.
 type Inmemory struct {
 	log *slog.Logger
 
-	users         []User
-	refreshTokens []RefreshToken
-	chats         []Chat
-	messages      []Message
-
-	outboxes []Outbox
+	mu sync.RWMutex
+
+	users         map[uuid.UUID]User
+	userLogins    map[string]uuid.UUID
+	sessions      map[uuid.UUID]domain.Session
+	userSessions  map[uuid.UUID]map[uuid.UUID]struct{}
+	revokedTokens map[uuid.UUID]time.Time
+	chats         map[uuid.UUID]*Chat
+	quotas        map[uuid.UUID]domain.Quota
+	// usage indexes what users have spent of their quotas, it's updated together with chats and messages
+	usage map[uuid.UUID]*userUsage
+
+	// Only unsent outboxes are kept, in the order they were added
+	outboxes []*Outbox
+	// outboxSeqs are the last outbox sequence numbers of chats
+	outboxSeqs map[uuid.UUID]int64
+	// Dead letters are kept in the order they failed
+	deadLetters []*domain.DeadLetter
 }
 
 func New(log *slog.Logger) *Inmemory {
-	return &Inmemory{log: log}
+	return &Inmemory{
+		log:           log,
+		users:         make(map[uuid.UUID]User),
+		userLogins:    make(map[string]uuid.UUID),
+		sessions:      make(map[uuid.UUID]domain.Session),
+		userSessions:  make(map[uuid.UUID]map[uuid.UUID]struct{}),
+		revokedTokens: make(map[uuid.UUID]time.Time),
+		chats:         make(map[uuid.UUID]*Chat),
+		quotas:        make(map[uuid.UUID]domain.Quota),
+		usage:         make(map[uuid.UUID]*userUsage),
+		outboxSeqs:    make(map[uuid.UUID]int64),
+	}
 }
 
 type Outbox struct {
-	uuid    uuid.UUID
-	topic   string
-	message []byte
-	sent_at time.Time
+	uuid     uuid.UUID
+	chatUuid uuid.UUID
+	seq      int64
+	topic    string
+	// schemaVersion is the version of the marshalled event
+	schemaVersion int
+	traceContext  map[string]string
+	message       []byte
+	created       time.Time
+	attempts      int
+	lastError     string
+	claimedUntil  time.Time
+}
+
+type userUsage struct {
+	// Chats owned by the user until they are deleted, expired ones are skipped when counted
+	chats        map[uuid.UUID]struct{}
+	messageBytes int64
+	// Publication times of the recent messages, they count for the messages per minute quota
+	// even after the messages are trimmed or deleted
+	messageTimes []time.Time
 }
 
 type User struct {
@@ -41,218 +85,775 @@ type User struct {
 	PasswordHash []byte
 }
 
-type RefreshToken struct {
-	userUuid     uuid.UUID
-	refreshToken string
-}
-
 type Chat struct {
 	Uuid     uuid.UUID
 	Owner    uuid.UUID
 	Readonly bool
 	Deadline time.Time
+
+	// Message ids are sequential within a chat, messages are kept ordered by them
+	lastId   int
+	messages []*Message
+	members  map[uuid.UUID]Member
+}
+
+// message returns the position of the message in the chat or -1
+func (c *Chat) message(messageId int) int {
+	pos := sort.Search(len(c.messages), func(i int) bool { return c.messages[i].Id >= messageId })
+	if pos < len(c.messages) && c.messages[pos].Id == messageId {
+		return pos
+	}
+	return -1
+}
+
+func (c *Chat) expired(now time.Time) bool {
+	return !c.Deadline.After(now)
 }
 
 type Message struct {
 	Id         int
-	ChatUuid   uuid.UUID
 	AuthorUuid uuid.UUID
 	Body       string
 	Published  time.Time
+	Edits      []domain.MessageEdit
+	DeletedAt  time.Time
 }
 
-type numerator struct {
-	current int
+func (m *Message) toDomain() *domain.Message {
+	edits := append([]domain.MessageEdit(nil), m.Edits...)
+	return &domain.Message{Id: m.Id, AuthorUuid: m.AuthorUuid, Body: m.Body, Published: m.Published, Edits: edits, DeletedAt: m.DeletedAt}
 }
 
-func (n *numerator) GetNext() int {
-	n.current = n.current + 1
-	return n.current
+type Member struct {
+	UserUuid  uuid.UUID
+	Role      domain.Role
+	InvitedBy uuid.UUID
+	Joined    time.Time
 }
 
-var singleNumerator *numerator
+func (m *Member) toDomain(chatUuid uuid.UUID) *domain.Member {
+	return &domain.Member{ChatUuid: chatUuid, UserUuid: m.UserUuid, Role: m.Role, InvitedBy: m.InvitedBy, Joined: m.Joined}
+}
 
-func getNumerator() *numerator {
-	if singleNumerator == nil {
-		singleNumerator = &numerator{}
-	}
-	return singleNumerator
+// addOutbox adds the event of the chat to the outbox with the next sequence number of the chat
+func (i *Inmemory) addOutbox(ctx context.Context, eventUuid uuid.UUID, chatUuid uuid.UUID, topic string, message []byte) {
+	i.outboxSeqs[chatUuid]++
+	i.outboxes = append(i.outboxes, &Outbox{
+		uuid:          eventUuid,
+		chatUuid:      chatUuid,
+		seq:           i.outboxSeqs[chatUuid],
+		topic:         topic,
+		message:       message,
+		schemaVersion: storage.EventSchemaVersion,
+		traceContext:  tracing.Inject(ctx),
+		created:       time.Now(),
+	})
 }
 
 func (i *Inmemory) CreateUser(ctx context.Context, user domain.User) (*domain.User, error) {
-	newUser := User{
-		Uuid:         user.Uuid,
-		Login:        user.Login,
-		PasswordHash: user.PasswordHash,
-	}
-	i.users = append(i.users, newUser)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.users[user.Uuid] = User{Uuid: user.Uuid, Login: user.Login, PasswordHash: user.PasswordHash}
+	i.userLogins[user.Login] = user.Uuid
 	return &user, nil
 }
 
 func (i *Inmemory) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
-	var user domain.User
-	for _, v := range i.users {
-		if v.Login == login {
-			user.Uuid = v.Uuid
-			user.PasswordHash = v.PasswordHash
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 
-			return &user, nil
-		}
+	user, ok := i.users[i.userLogins[login]]
+	if !ok {
+		return &domain.User{}, storage.ErrUserNotFound
 	}
-	return &user, storage.ErrUserNotFound
+	return &domain.User{Uuid: user.Uuid, Login: user.Login, PasswordHash: user.PasswordHash}, nil
 }
 
 func (i *Inmemory) GetUserByUuid(ctx context.Context, uuid uuid.UUID) (*domain.User, error) {
-	var user domain.User
-	for _, v := range i.users {
-		if v.Uuid == uuid {
-			user.Login = v.Login
-			user.PasswordHash = v.PasswordHash
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 
-			return &user, nil
-		}
+	user, ok := i.users[uuid]
+	if !ok {
+		return &domain.User{}, storage.ErrUserNotFound
 	}
-	return &user, storage.ErrUserNotFound
+	return &domain.User{Uuid: user.Uuid, Login: user.Login, PasswordHash: user.PasswordHash}, nil
 }
 
-func (i *Inmemory) UpsertRefreshToken(ctx context.Context, userUuid uuid.UUID, refreshToken string) error {
-	for key := range i.refreshTokens {
-		if i.refreshTokens[key].userUuid == userUuid {
-			i.refreshTokens[key].refreshToken = refreshToken
+func (i *Inmemory) RevokeToken(ctx context.Context, jti uuid.UUID, expired time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-			return nil
+	// Drop tokens which have expired on their own
+	now := time.Now()
+	for k, v := range i.revokedTokens {
+		if !v.After(now) {
+			delete(i.revokedTokens, k)
 		}
 	}
-	newRefreshToken := RefreshToken{userUuid: userUuid, refreshToken: refreshToken}
-	i.refreshTokens = append(i.refreshTokens, newRefreshToken)
+	i.revokedTokens[jti] = expired
 	return nil
 }
 
-func (i *Inmemory) GetRefreshToken(ctx context.Context, userUuid uuid.UUID) (string, error) {
-	for _, v := range i.refreshTokens {
-		if v.userUuid == userUuid {
-			return v.refreshToken, nil
+func (i *Inmemory) IsTokenRevoked(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID, jti uuid.UUID) (bool, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if _, ok := i.revokedTokens[jti]; ok {
+		return true, nil
+	}
+	session, ok := i.sessions[sessionUuid]
+	if !ok || !session.Expires.After(time.Now()) {
+		return true, nil
+	}
+	return session.UserUuid != userUuid, nil
+}
+
+func (i *Inmemory) CreateSession(ctx context.Context, session domain.Session) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.sessions[session.Uuid] = session
+	if i.userSessions[session.UserUuid] == nil {
+		i.userSessions[session.UserUuid] = make(map[uuid.UUID]struct{})
+	}
+	i.userSessions[session.UserUuid][session.Uuid] = struct{}{}
+	return nil
+}
+
+func (i *Inmemory) GetSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	session, ok := i.sessions[sessionUuid]
+	if !ok || !session.Expires.After(time.Now()) {
+		return nil, storage.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (i *Inmemory) RotateSession(ctx context.Context, session domain.Session, previousJti uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	current, ok := i.sessions[session.Uuid]
+	if !ok || current.RefreshJti != previousJti {
+		return storage.ErrSessionNotFound
+	}
+	i.sessions[session.Uuid] = session
+	return nil
+}
+
+func (i *Inmemory) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var res []*domain.Session
+	now := time.Now()
+	for sessionUuid := range i.userSessions[userUuid] {
+		session := i.sessions[sessionUuid]
+		if session.Expires.After(now) {
+			res = append(res, &session)
 		}
 	}
-	return "", storage.ErrTokenNotFound
+	sort.Slice(res, func(a, b int) bool { return res[a].Created.Before(res[b].Created) })
+	return res, nil
 }
 
-func (i *Inmemory) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat, error) {
-	newChat := Chat{Uuid: chat.Uuid, Owner: chat.Owner.Uuid, Readonly: chat.Readonly, Deadline: chat.Deadline}
+func (i *Inmemory) DeleteSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	msg := outbox.OutboxChat{
-		Uuid:      newChat.Uuid.String(),
-		OwnerUuid: newChat.Owner.String(),
-		Readonly:  newChat.Readonly,
-		Deadline:  newChat.Deadline.String(),
+	if _, ok := i.userSessions[userUuid][sessionUuid]; !ok {
+		return storage.ErrSessionNotFound
 	}
+	delete(i.userSessions[userUuid], sessionUuid)
+	delete(i.sessions, sessionUuid)
+	return nil
+}
+
+func (i *Inmemory) DeleteUserSessions(ctx context.Context, userUuid uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for sessionUuid := range i.userSessions[userUuid] {
+		delete(i.sessions, sessionUuid)
+	}
+	delete(i.userSessions, userUuid)
+	return nil
+}
 
-	marshalledMessage, err := proto.Marshal(&msg)
+func (i *Inmemory) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat, error) {
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalChatEvent(eventUuid, outbox.EventType_CHAT_CREATED, &chat, time.Now())
 	if err != nil {
 		return &domain.Chat{}, storage.ErrInternal
 	}
 
-	i.chats = append(i.chats, newChat)
-	i.outboxes = append(i.outboxes, Outbox{uuid: uuid.New(), topic: domain.ChatTopic, message: marshalledMessage})
+	newChat := &Chat{
+		Uuid:     chat.Uuid,
+		Owner:    chat.Owner.Uuid,
+		Readonly: chat.Readonly,
+		Deadline: chat.Deadline,
+		members:  map[uuid.UUID]Member{chat.Owner.Uuid: {UserUuid: chat.Owner.Uuid, Role: domain.RoleOwner, Joined: time.Now()}},
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.chats[newChat.Uuid] = newChat
+	i.userUsage(newChat.Owner).chats[newChat.Uuid] = struct{}{}
+	i.addOutbox(ctx, eventUuid, newChat.Uuid, domain.ChatTopic, marshalledMessage)
 
 	return &chat, nil
 }
 
+// chat returns the chat unless it has expired.
+// Expired chats are left for the reaper, but they are already gone for users.
+func (i *Inmemory) chat(chatUuid uuid.UUID) (*Chat, error) {
+	chat, ok := i.chats[chatUuid]
+	if !ok || chat.expired(time.Now()) {
+		return nil, storage.ErrChatNotFound
+	}
+	return chat, nil
+}
+
 func (i *Inmemory) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat, error) {
-	var chat Chat
-	for _, v := range i.chats {
-		if v.Uuid == chatUuid {
-			chat.Uuid = v.Uuid
-			chat.Readonly = v.Readonly
-			chat.Owner = v.Owner
-			chat.Deadline = v.Deadline
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 
-			break
-		}
+	chat, err := i.chat(chatUuid)
+	if err != nil {
+		return &domain.Chat{}, err
+	}
+	owner, ok := i.users[chat.Owner]
+	if !ok {
+		return &domain.Chat{}, storage.ErrChatNotFound
 	}
-	var owner User
-	if chat.Uuid != uuid.Nil {
-		for _, v := range i.users {
-			if v.Uuid == chat.Owner {
-				owner.Uuid = v.Uuid
-				owner.Login = v.Login
-				owner.PasswordHash = v.PasswordHash
 
-				break
-			}
+	return &domain.Chat{
+		Uuid:     chat.Uuid,
+		Owner:    domain.User{Uuid: owner.Uuid, Login: owner.Login, PasswordHash: owner.PasswordHash},
+		Readonly: chat.Readonly,
+		Deadline: chat.Deadline,
+	}, nil
+}
+
+func (i *Inmemory) ChatsCount(ctx context.Context) (int, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	count := 0
+	now := time.Now()
+	for _, v := range i.chats {
+		if !v.expired(now) {
+			count++
 		}
 	}
-	res := domain.Chat{}
-	if chat.Uuid != uuid.Nil && owner.Uuid != uuid.Nil {
-		res.Uuid = chat.Uuid
-		res.Readonly = chat.Readonly
-		res.Deadline = chat.Deadline
-		res.Owner.Uuid = owner.Uuid
-		res.Owner.Login = owner.Login
-		res.Owner.PasswordHash = owner.PasswordHash
-		return &res, nil
-	}
-	return &res, storage.ErrChatNotFound
+	return count, nil
 }
 
-func (i *Inmemory) ChatsCount(ctx context.Context) (int, error) {
-	return len(i.chats), nil
+// DeleteExpiredChats deletes up to limit chats whose deadline has passed together with their messages and members.
+// A chat expired event is added to the outbox for every deleted chat.
+func (i *Inmemory) DeleteExpiredChats(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var res []uuid.UUID
+	for chatUuid, v := range i.chats {
+		if len(res) >= limit {
+			break
+		}
+		if !v.expired(now) {
+			continue
+		}
+		chat := domain.Chat{Uuid: v.Uuid, Owner: domain.User{Uuid: v.Owner}, Readonly: v.Readonly, Deadline: v.Deadline}
+		eventUuid := uuid.New()
+		marshalledMessage, err := storage.MarshalChatEvent(eventUuid, outbox.EventType_CHAT_EXPIRED, &chat, now)
+		if err != nil {
+			return nil, storage.ErrInternal
+		}
+		delete(i.chats, chatUuid)
+		delete(i.userUsage(v.Owner).chats, chatUuid)
+		for _, message := range v.messages {
+			i.userUsage(message.AuthorUuid).messageBytes -= int64(len(message.Body))
+		}
+		i.addOutbox(ctx, eventUuid, chatUuid, domain.ChatTopic, marshalledMessage)
+		// The expired event is the last one of the chat
+		delete(i.outboxSeqs, chatUuid)
+		res = append(res, chatUuid)
+	}
+	return res, nil
 }
 
-func (i *Inmemory) PostMessage(ctx context.Context, chat uuid.UUID, message domain.Message) (*domain.Message, error) {
-	nextId := getNumerator().GetNext()
-	newMessage := Message{Id: nextId, AuthorUuid: message.AuthorUuid, Body: message.Body, Published: message.Published, ChatUuid: chat}
+func (i *Inmemory) PostMessage(ctx context.Context, chatUuid uuid.UUID, message domain.Message) (*domain.Message, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	msg := outbox.OutboxMessage{
-		Id:         int64(newMessage.Id),
-		AuthorUuid: newMessage.AuthorUuid.String(),
-		Body:       newMessage.Body,
-		Published:  newMessage.Published.String(),
+	chat, err := i.chat(chatUuid)
+	if err != nil {
+		return &domain.Message{}, err
 	}
 
-	marshalledMessage, err := proto.Marshal(&msg)
+	newMessage := &Message{Id: chat.lastId + 1, AuthorUuid: message.AuthorUuid, Body: message.Body, Published: message.Published}
+	res := newMessage.toDomain()
+
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalMessageEvent(eventUuid, outbox.EventType_MESSAGE_POSTED, chatUuid, message.AuthorUuid, res)
 	if err != nil {
 		return &domain.Message{}, storage.ErrInternal
 	}
 
-	i.messages = append(i.messages, newMessage)
-	i.outboxes = append(i.outboxes, Outbox{uuid: uuid.New(), topic: domain.MessageTopic, message: marshalledMessage})
+	chat.lastId = newMessage.Id
+	chat.messages = append(chat.messages, newMessage)
+	usage := i.userUsage(message.AuthorUuid)
+	usage.messageBytes += int64(len(message.Body))
+	usage.logMessageTime(message.Published)
+	i.addOutbox(ctx, eventUuid, chatUuid, domain.MessageTopic, marshalledMessage)
 
-	return &domain.Message{Id: newMessage.Id, AuthorUuid: newMessage.AuthorUuid, Body: newMessage.Body, Published: newMessage.Published}, nil
+	return res, nil
 }
 
-func (i *Inmemory) TrimMessages(ctx context.Context, chat uuid.UUID, maximumMessages int) (bool, error) {
-	if len(i.messages) > maximumMessages {
-		i.messages = i.messages[len(i.messages)-maximumMessages:]
+// messageTimesWindow is how long publication times are kept for the messages per minute quota
+const messageTimesWindow = time.Hour
+
+// userUsage returns the usage of the user, it must be called with the write lock held
+func (i *Inmemory) userUsage(userUuid uuid.UUID) *userUsage {
+	usage, ok := i.usage[userUuid]
+	if !ok {
+		usage = &userUsage{chats: make(map[uuid.UUID]struct{})}
+		i.usage[userUuid] = usage
 	}
-	return true, nil
+	return usage
 }
 
-func (i *Inmemory) GetChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error) {
+// logMessageTime adds the publication time to the log and drops the times older than the window
+func (u *userUsage) logMessageTime(published time.Time) {
+	oldest := published.Add(-messageTimesWindow)
+	times := u.messageTimes[:0]
+	for _, v := range u.messageTimes {
+		if v.After(oldest) {
+			times = append(times, v)
+		}
+	}
+	u.messageTimes = append(times, published)
+}
+
+// TrimMessages keeps only the newest maximumMessages messages of the chat
+func (i *Inmemory) TrimMessages(ctx context.Context, chatUuid uuid.UUID, maximumMessages int) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return false, nil
+	}
+	if extra := len(chat.messages) - maximumMessages; extra > 0 {
+		for _, v := range chat.messages[:extra] {
+			i.userUsage(v.AuthorUuid).messageBytes -= int64(len(v.Body))
+		}
+		// Copy, so the trimmed messages don't stay in the underlying array
+		chat.messages = append([]*Message(nil), chat.messages[extra:]...)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (i *Inmemory) GetChatHistoryPage(ctx context.Context, chatUuid uuid.UUID, query domain.HistoryQuery) ([]*domain.Message, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return nil, nil
+	}
+
+	// Messages are ordered by id, so the page bounds are found by binary search
+	from, to := 0, len(chat.messages)
+	if query.AfterId > 0 {
+		from = sort.Search(len(chat.messages), func(k int) bool { return chat.messages[k].Id > query.AfterId })
+	}
+	if query.BeforeId > 0 {
+		to = sort.Search(len(chat.messages), func(k int) bool { return chat.messages[k].Id >= query.BeforeId })
+	}
+	if from > to {
+		from = to
+	}
+	if to-from > query.Limit {
+		if query.AfterId > 0 {
+			to = from + query.Limit
+		} else {
+			from = to - query.Limit
+		}
+	}
+
 	var res []*domain.Message
-	for _, v := range i.messages {
-		if v.ChatUuid == chatUuid {
-			nextMessage := domain.Message{Id: v.Id, AuthorUuid: v.AuthorUuid, Body: v.Body, Published: v.Published}
-			res = append(res, &nextMessage)
+	for _, v := range chat.messages[from:to] {
+		res = append(res, v.toDomain())
+	}
+	return res, nil
+}
+
+func (i *Inmemory) GetMessage(ctx context.Context, chatUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return nil, storage.ErrMessageNotFound
+	}
+	pos := chat.message(messageId)
+	if pos < 0 {
+		return nil, storage.ErrMessageNotFound
+	}
+	return chat.messages[pos].toDomain(), nil
+}
+
+func (i *Inmemory) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string, edited time.Time) (*domain.Message, error) {
+	return i.updateMessage(ctx, chatUuid, actorUuid, messageId, outbox.EventType_MESSAGE_EDITED, func(m *Message) {
+		m.Edits = append(m.Edits, domain.MessageEdit{Body: m.Body, Edited: edited})
+		m.Body = body
+	})
+}
+
+func (i *Inmemory) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, deleted time.Time) (*domain.Message, error) {
+	return i.updateMessage(ctx, chatUuid, actorUuid, messageId, outbox.EventType_MESSAGE_DELETED, func(m *Message) {
+		m.Body = ""
+		m.Edits = nil
+		m.DeletedAt = deleted
+	})
+}
+
+// updateMessage changes a message which isn't deleted yet and records the outbox event for it
+func (i *Inmemory) updateMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, event outbox.EventType, update func(m *Message)) (*domain.Message, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return nil, storage.ErrMessageNotFound
+	}
+	pos := chat.message(messageId)
+	if pos < 0 || !chat.messages[pos].DeletedAt.IsZero() {
+		return nil, storage.ErrMessageNotFound
+	}
+
+	// Messages are replaced, not changed in place: readers may still hold the previous version
+	updated := *chat.messages[pos]
+	update(&updated)
+	res := updated.toDomain()
+
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalMessageEvent(eventUuid, event, chatUuid, actorUuid, res)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	i.userUsage(updated.AuthorUuid).messageBytes += int64(len(updated.Body) - len(chat.messages[pos].Body))
+	chat.messages[pos] = &updated
+	i.addOutbox(ctx, eventUuid, chatUuid, domain.MessageTopic, marshalledMessage)
+	return res, nil
+}
+
+// ClaimOutboxBatch returns up to limit oldest unsent outboxes and claims them for the lease,
+// claimed ones aren't returned again until the lease is over.
+// Outboxes of a chat with claimed outboxes are skipped.
+func (i *Inmemory) ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.Outbox, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now()
+	// A chat is published by one publisher at a time
+	busyChats := make(map[uuid.UUID]bool)
+	for _, v := range i.outboxes {
+		if !v.claimedUntil.Before(now) && v.chatUuid != uuid.Nil {
+			busyChats[v.chatUuid] = true
+		}
+	}
+
+	var res []*domain.Outbox
+	for _, v := range i.outboxes {
+		if len(res) >= limit {
+			break
+		}
+		if v.claimedUntil.Before(now) && !busyChats[v.chatUuid] {
+			v.claimedUntil = now.Add(lease)
+			res = append(res, &domain.Outbox{
+				Uuid:          v.uuid,
+				ChatUuid:      v.chatUuid,
+				Seq:           v.seq,
+				Topic:         v.topic,
+				Message:       v.message,
+				SchemaVersion: v.schemaVersion,
+				TraceContext:  v.traceContext,
+				Attempts:      v.attempts,
+				LastError:     v.lastError,
+			})
 		}
 	}
 	return res, nil
 }
 
-func (i *Inmemory) GetNextOutbox(ctx context.Context) (*domain.Outbox, error) {
+// ReleaseOutbox returns claimed outboxes before their lease is over
+func (i *Inmemory) ReleaseOutbox(ctx context.Context, outboxUuids []uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	released := make(map[uuid.UUID]bool, len(outboxUuids))
+	for _, v := range outboxUuids {
+		released[v] = true
+	}
 	for _, v := range i.outboxes {
-		if v.sent_at.IsZero() {
-			return &domain.Outbox{Uuid: v.uuid, Topic: v.topic, Message: v.message, Sent_at: v.sent_at}, nil
+		if released[v.uuid] {
+			v.claimedUntil = time.Time{}
 		}
 	}
-	return nil, storage.ErrNoOutbox
+	return nil
 }
 
+// ConfirmOutboxSended drops the sent outbox, nothing reads it afterwards
 func (i *Inmemory) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
-	for key := range i.outboxes {
-		if i.outboxes[key].uuid == outboxUuid {
-			i.outboxes[key].sent_at = time.Now()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for pos, v := range i.outboxes {
+		if v.uuid == outboxUuid {
+			i.outboxes = append(i.outboxes[:pos:pos], i.outboxes[pos+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// OutboxBacklog counts unsent outboxes and measures how long the oldest one has been waiting
+func (i *Inmemory) OutboxBacklog(ctx context.Context) (*domain.OutboxBacklog, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	res := domain.OutboxBacklog{Unsent: len(i.outboxes)}
+	if len(i.outboxes) > 0 {
+		res.OldestAge = time.Since(i.outboxes[0].created)
+	}
+	return &res, nil
+}
+
+// FailOutbox records a failed attempt to publish the outbox.
+// After maxAttempts the outbox is moved to the dead letters and true is returned.
+func (i *Inmemory) FailOutbox(ctx context.Context, outboxUuid uuid.UUID, lastError string, maxAttempts int) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for pos, v := range i.outboxes {
+		if v.uuid != outboxUuid {
+			continue
+		}
+		v.attempts++
+		v.lastError = lastError
+		v.claimedUntil = time.Time{}
+		if v.attempts < maxAttempts {
+			return false, nil
+		}
+		i.outboxes = append(i.outboxes[:pos:pos], i.outboxes[pos+1:]...)
+		i.deadLetters = append(i.deadLetters, &domain.DeadLetter{
+			Uuid:          v.uuid,
+			Topic:         v.topic,
+			ChatUuid:      v.chatUuid,
+			Seq:           v.seq,
+			Message:       v.message,
+			SchemaVersion: v.schemaVersion,
+			TraceContext:  v.traceContext,
+			Attempts:      v.attempts,
+			LastError:     v.lastError,
+			Failed:        time.Now(),
+		})
+		return true, nil
+	}
+	return false, storage.ErrOutboxNotFound
+}
+
+// ListDeadLetters returns a page of dead letters, the oldest failures first
+func (i *Inmemory) ListDeadLetters(ctx context.Context, limit int, offset int) ([]*domain.DeadLetter, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if offset >= len(i.deadLetters) {
+		return nil, nil
+	}
+	page := i.deadLetters[offset:min(offset+limit, len(i.deadLetters))]
+	res := make([]*domain.DeadLetter, len(page))
+	for pos, v := range page {
+		deadLetter := *v
+		res[pos] = &deadLetter
+	}
+	return res, nil
+}
+
+func (i *Inmemory) GetDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	pos := i.deadLetter(deadLetterUuid)
+	if pos < 0 {
+		return nil, storage.ErrDeadLetterNotFound
+	}
+	deadLetter := *i.deadLetters[pos]
+	return &deadLetter, nil
+}
+
+// ReplayDeadLetter puts the dead letter back to the end of the outbox with its attempts reset
+func (i *Inmemory) ReplayDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	pos := i.deadLetter(deadLetterUuid)
+	if pos < 0 {
+		return storage.ErrDeadLetterNotFound
+	}
+	deadLetter := i.deadLetters[pos]
+	i.deadLetters = append(i.deadLetters[:pos:pos], i.deadLetters[pos+1:]...)
+	i.outboxes = append(i.outboxes, &Outbox{uuid: deadLetter.Uuid, chatUuid: deadLetter.ChatUuid, seq: deadLetter.Seq, topic: deadLetter.Topic, message: deadLetter.Message, schemaVersion: deadLetter.SchemaVersion, traceContext: deadLetter.TraceContext, created: time.Now()})
+	return nil
+}
+
+func (i *Inmemory) DeleteDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	pos := i.deadLetter(deadLetterUuid)
+	if pos < 0 {
+		return storage.ErrDeadLetterNotFound
+	}
+	i.deadLetters = append(i.deadLetters[:pos:pos], i.deadLetters[pos+1:]...)
+	return nil
+}
+
+// PurgeDeadLetters deletes all dead letters and returns how many were deleted
+func (i *Inmemory) PurgeDeadLetters(ctx context.Context) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	purged := len(i.deadLetters)
+	i.deadLetters = nil
+	return purged, nil
+}
+
+// deadLetter returns the position of the dead letter or -1
+func (i *Inmemory) deadLetter(deadLetterUuid uuid.UUID) int {
+	for pos, v := range i.deadLetters {
+		if v.Uuid == deadLetterUuid {
+			return pos
 		}
 	}
+	return -1
+}
+
+func (i *Inmemory) AddMember(ctx context.Context, member domain.Member) (*domain.Member, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.users[member.UserUuid]; !ok {
+		return nil, storage.ErrUserNotFound
+	}
+	chat, err := i.chat(member.ChatUuid)
+	if err != nil {
+		return nil, err
+	}
+	if current, ok := chat.members[member.UserUuid]; ok {
+		member.Joined = current.Joined
+	}
+	chat.members[member.UserUuid] = Member{UserUuid: member.UserUuid, Role: member.Role, InvitedBy: member.InvitedBy, Joined: member.Joined}
+	return &member, nil
+}
+
+func (i *Inmemory) GetMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return nil, storage.ErrMemberNotFound
+	}
+	member, ok := chat.members[userUuid]
+	if !ok {
+		return nil, storage.ErrMemberNotFound
+	}
+	return member.toDomain(chatUuid), nil
+}
+
+func (i *Inmemory) RemoveMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return storage.ErrMemberNotFound
+	}
+	if _, ok := chat.members[userUuid]; !ok {
+		return storage.ErrMemberNotFound
+	}
+	delete(chat.members, userUuid)
+	return nil
+}
+
+func (i *Inmemory) ListMembers(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Member, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	chat, ok := i.chats[chatUuid]
+	if !ok {
+		return nil, nil
+	}
+	res := make([]*domain.Member, 0, len(chat.members))
+	for _, v := range chat.members {
+		res = append(res, v.toDomain(chatUuid))
+	}
+	sort.Slice(res, func(a, b int) bool { return res[a].Joined.Before(res[b].Joined) })
+	return res, nil
+}
+
+func (i *Inmemory) GetUserQuota(ctx context.Context, userUuid uuid.UUID) (*domain.Quota, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	quota, ok := i.quotas[userUuid]
+	if !ok {
+		return nil, storage.ErrQuotaNotFound
+	}
+	return &quota, nil
+}
+
+func (i *Inmemory) SetUserQuota(ctx context.Context, userUuid uuid.UUID, quota domain.Quota) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.users[userUuid]; !ok {
+		return storage.ErrUserNotFound
+	}
+	i.quotas[userUuid] = quota
 	return nil
 }
+
+func (i *Inmemory) QuotaUsage(ctx context.Context, userUuid uuid.UUID, since time.Time) (*domain.QuotaUsage, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var usage domain.QuotaUsage
+	user, ok := i.usage[userUuid]
+	if !ok {
+		return &usage, nil
+	}
+	now := time.Now()
+	for chatUuid := range user.chats {
+		if chat, ok := i.chats[chatUuid]; ok && !chat.expired(now) {
+			usage.ChatsOwned++
+		}
+	}
+	usage.MessageBytes = user.messageBytes
+	for _, v := range user.messageTimes {
+		if v.After(since) {
+			usage.MessagesLastMinute++
+		}
+	}
+	return &usage, nil
+}
@@ -0,0 +1,71 @@
+package inmemory_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage/inmemory"
+)
+
+func newChat(t *testing.T, im *inmemory.Inmemory) uuid.UUID {
+	ctx := context.Background()
+	owner, err := im.CreateUser(ctx, domain.User{Uuid: uuid.New(), Login: uuid.NewString()})
+	require.NoError(t, err)
+	chat, err := im.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *owner, Deadline: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	return chat.Uuid
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	t.Parallel()
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	im := inmemory.New(log)
+	ctx := context.Background()
+
+	const writers, messages = 8, 50
+	chatUuid := newChat(t, im)
+
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range messages {
+				_, err := im.PostMessage(ctx, chatUuid, domain.Message{Body: "hi", Published: time.Now()})
+				assert.NoError(t, err)
+				_, err = im.TrimMessages(ctx, chatUuid, writers*messages)
+				assert.NoError(t, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range messages {
+				_, err := im.GetChatHistoryPage(ctx, chatUuid, domain.HistoryQuery{Limit: 10})
+				assert.NoError(t, err)
+				batch, err := im.ClaimOutboxBatch(ctx, 10, time.Minute)
+				assert.NoError(t, err)
+				for _, v := range batch {
+					assert.NoError(t, im.ConfirmOutboxSended(ctx, v.Uuid))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Ids are unique and sequential within the chat
+	page, err := im.GetChatHistoryPage(ctx, chatUuid, domain.HistoryQuery{Limit: writers * messages})
+	require.NoError(t, err)
+	require.Len(t, page, writers*messages)
+	for k, v := range page {
+		assert.Equal(t, k+1, v.Id)
+	}
+}
@@ -0,0 +1,88 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/services/auth"
+)
+
+type Auth struct {
+	backend string
+	storage auth.AuthStorage
+}
+
+var _ auth.AuthStorage = (*Auth)(nil)
+
+func NewAuth(backend string, storage auth.AuthStorage) *Auth {
+	return &Auth{backend: backend, storage: storage}
+}
+
+func (a *Auth) CreateUser(ctx context.Context, user domain.User) (*domain.User, error) {
+	return call(ctx, a.backend, "CreateUser", func(ctx context.Context) (*domain.User, error) {
+		return a.storage.CreateUser(ctx, user)
+	})
+}
+
+func (a *Auth) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
+	return call(ctx, a.backend, "GetUserByLogin", func(ctx context.Context) (*domain.User, error) {
+		return a.storage.GetUserByLogin(ctx, login)
+	})
+}
+
+func (a *Auth) GetUserByUuid(ctx context.Context, uuid uuid.UUID) (*domain.User, error) {
+	return call(ctx, a.backend, "GetUserByUuid", func(ctx context.Context) (*domain.User, error) {
+		return a.storage.GetUserByUuid(ctx, uuid)
+	})
+}
+
+func (a *Auth) CreateSession(ctx context.Context, session domain.Session) error {
+	return exec(ctx, a.backend, "CreateSession", func(ctx context.Context) error {
+		return a.storage.CreateSession(ctx, session)
+	})
+}
+
+func (a *Auth) GetSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error) {
+	return call(ctx, a.backend, "GetSession", func(ctx context.Context) (*domain.Session, error) {
+		return a.storage.GetSession(ctx, sessionUuid)
+	})
+}
+
+func (a *Auth) RotateSession(ctx context.Context, session domain.Session, previousJti uuid.UUID) error {
+	return exec(ctx, a.backend, "RotateSession", func(ctx context.Context) error {
+		return a.storage.RotateSession(ctx, session, previousJti)
+	})
+}
+
+func (a *Auth) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	return call(ctx, a.backend, "ListSessions", func(ctx context.Context) ([]*domain.Session, error) {
+		return a.storage.ListSessions(ctx, userUuid)
+	})
+}
+
+func (a *Auth) DeleteSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	return exec(ctx, a.backend, "DeleteSession", func(ctx context.Context) error {
+		return a.storage.DeleteSession(ctx, userUuid, sessionUuid)
+	})
+}
+
+func (a *Auth) DeleteUserSessions(ctx context.Context, userUuid uuid.UUID) error {
+	return exec(ctx, a.backend, "DeleteUserSessions", func(ctx context.Context) error {
+		return a.storage.DeleteUserSessions(ctx, userUuid)
+	})
+}
+
+func (a *Auth) RevokeToken(ctx context.Context, jti uuid.UUID, expired time.Time) error {
+	return exec(ctx, a.backend, "RevokeToken", func(ctx context.Context) error {
+		return a.storage.RevokeToken(ctx, jti, expired)
+	})
+}
+
+func (a *Auth) IsTokenRevoked(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID, jti uuid.UUID) (bool, error) {
+	return call(ctx, a.backend, "IsTokenRevoked", func(ctx context.Context) (bool, error) {
+		return a.storage.IsTokenRevoked(ctx, userUuid, sessionUuid, jti)
+	})
+}
@@ -0,0 +1,141 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/services/admin"
+	"github.com/alexandernizov/grpcmessanger/internal/services/chat"
+)
+
+type Chat struct {
+	backend string
+	storage chat.ChatStorage
+}
+
+var _ chat.ChatStorage = (*Chat)(nil)
+
+func NewChat(backend string, storage chat.ChatStorage) *Chat {
+	return &Chat{backend: backend, storage: storage}
+}
+
+func (c *Chat) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat, error) {
+	return call(ctx, c.backend, "CreateChat", func(ctx context.Context) (*domain.Chat, error) {
+		return c.storage.CreateChat(ctx, chat)
+	})
+}
+
+func (c *Chat) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat, error) {
+	return call(ctx, c.backend, "GetChat", func(ctx context.Context) (*domain.Chat, error) {
+		return c.storage.GetChat(ctx, chatUuid)
+	})
+}
+
+func (c *Chat) PostMessage(ctx context.Context, chat uuid.UUID, message domain.Message) (*domain.Message, error) {
+	return call(ctx, c.backend, "PostMessage", func(ctx context.Context) (*domain.Message, error) {
+		return c.storage.PostMessage(ctx, chat, message)
+	})
+}
+
+func (c *Chat) TrimMessages(ctx context.Context, chat uuid.UUID, maximumMessages int) (bool, error) {
+	return call(ctx, c.backend, "TrimMessages", func(ctx context.Context) (bool, error) {
+		return c.storage.TrimMessages(ctx, chat, maximumMessages)
+	})
+}
+
+func (c *Chat) GetChatHistoryPage(ctx context.Context, chatUuid uuid.UUID, query domain.HistoryQuery) ([]*domain.Message, error) {
+	return call(ctx, c.backend, "GetChatHistoryPage", func(ctx context.Context) ([]*domain.Message, error) {
+		return c.storage.GetChatHistoryPage(ctx, chatUuid, query)
+	})
+}
+
+func (c *Chat) AddMember(ctx context.Context, member domain.Member) (*domain.Member, error) {
+	return call(ctx, c.backend, "AddMember", func(ctx context.Context) (*domain.Member, error) {
+		return c.storage.AddMember(ctx, member)
+	})
+}
+
+func (c *Chat) GetMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error) {
+	return call(ctx, c.backend, "GetMember", func(ctx context.Context) (*domain.Member, error) {
+		return c.storage.GetMember(ctx, chatUuid, userUuid)
+	})
+}
+
+func (c *Chat) RemoveMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) error {
+	return exec(ctx, c.backend, "RemoveMember", func(ctx context.Context) error {
+		return c.storage.RemoveMember(ctx, chatUuid, userUuid)
+	})
+}
+
+func (c *Chat) ListMembers(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Member, error) {
+	return call(ctx, c.backend, "ListMembers", func(ctx context.Context) ([]*domain.Member, error) {
+		return c.storage.ListMembers(ctx, chatUuid)
+	})
+}
+
+func (c *Chat) GetMessage(ctx context.Context, chatUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	return call(ctx, c.backend, "GetMessage", func(ctx context.Context) (*domain.Message, error) {
+		return c.storage.GetMessage(ctx, chatUuid, messageId)
+	})
+}
+
+func (c *Chat) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string, edited time.Time) (*domain.Message, error) {
+	return call(ctx, c.backend, "EditMessage", func(ctx context.Context) (*domain.Message, error) {
+		return c.storage.EditMessage(ctx, chatUuid, actorUuid, messageId, body, edited)
+	})
+}
+
+func (c *Chat) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, deleted time.Time) (*domain.Message, error) {
+	return call(ctx, c.backend, "DeleteMessage", func(ctx context.Context) (*domain.Message, error) {
+		return c.storage.DeleteMessage(ctx, chatUuid, actorUuid, messageId, deleted)
+	})
+}
+
+func (c *Chat) GetUserQuota(ctx context.Context, userUuid uuid.UUID) (*domain.Quota, error) {
+	return call(ctx, c.backend, "GetUserQuota", func(ctx context.Context) (*domain.Quota, error) {
+		return c.storage.GetUserQuota(ctx, userUuid)
+	})
+}
+
+func (c *Chat) QuotaUsage(ctx context.Context, userUuid uuid.UUID, since time.Time) (*domain.QuotaUsage, error) {
+	return call(ctx, c.backend, "QuotaUsage", func(ctx context.Context) (*domain.QuotaUsage, error) {
+		return c.storage.QuotaUsage(ctx, userUuid, since)
+	})
+}
+
+type ExpiredChats struct {
+	backend string
+	storage chat.ExpiredChatsStorage
+}
+
+var _ chat.ExpiredChatsStorage = (*ExpiredChats)(nil)
+
+func NewExpiredChats(backend string, storage chat.ExpiredChatsStorage) *ExpiredChats {
+	return &ExpiredChats{backend: backend, storage: storage}
+}
+
+func (e *ExpiredChats) DeleteExpiredChats(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	return call(ctx, e.backend, "DeleteExpiredChats", func(ctx context.Context) ([]uuid.UUID, error) {
+		return e.storage.DeleteExpiredChats(ctx, now, limit)
+	})
+}
+
+type Quotas struct {
+	backend string
+	storage admin.QuotaStorage
+}
+
+var _ admin.QuotaStorage = (*Quotas)(nil)
+
+func NewQuotas(backend string, storage admin.QuotaStorage) *Quotas {
+	return &Quotas{backend: backend, storage: storage}
+}
+
+func (q *Quotas) SetUserQuota(ctx context.Context, userUuid uuid.UUID, quota domain.Quota) error {
+	return exec(ctx, q.backend, "SetUserQuota", func(ctx context.Context) error {
+		return q.storage.SetUserQuota(ctx, userUuid, quota)
+	})
+}
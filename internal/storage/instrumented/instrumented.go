@@ -0,0 +1,71 @@
+// Package instrumented wraps storages to trace their calls and measure the latency and failures per backend and method.
+// Every storage interface has its own wrapper, so optional interfaces of a backend are wrapped only if it has them.
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+)
+
+var (
+	callDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "storage_call_duration_seconds",
+		Help:    "Latency of storage calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"backend", "method"})
+	callErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "storage_call_errors_total",
+		Help: "Storage calls which failed, answers like not found aren't counted.",
+	}, []string{"backend", "method"})
+)
+
+var tracer = otel.Tracer("github.com/alexandernizov/grpcmessanger/internal/storage/instrumented")
+
+// failed tells errors of the storage from answers like not found
+func failed(err error) bool {
+	return errors.Is(err, storage.ErrInternal) || errors.Is(err, storage.ErrNoConnection)
+}
+
+// start opens the span of the call, the span is ended by observe
+func start(ctx context.Context, backend string, method string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, backend+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", backend), attribute.String("db.operation", method)))
+}
+
+// observe records the call which began at begin
+func observe(span trace.Span, backend string, method string, begin time.Time, err error) {
+	callDuration.WithLabelValues(backend, method).Observe(time.Since(begin).Seconds())
+	if failed(err) {
+		callErrors.WithLabelValues(backend, method).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func call[T any](ctx context.Context, backend string, method string, fn func(ctx context.Context) (T, error)) (T, error) {
+	begin := time.Now()
+	ctx, span := start(ctx, backend, method)
+	res, err := fn(ctx)
+	observe(span, backend, method, begin, err)
+	return res, err
+}
+
+func exec(ctx context.Context, backend string, method string, fn func(ctx context.Context) error) error {
+	begin := time.Now()
+	ctx, span := start(ctx, backend, method)
+	err := fn(ctx)
+	observe(span, backend, method, begin, err)
+	return err
+}
@@ -0,0 +1,54 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/services/chat/mocks"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+)
+
+func TestChat_GetChat(t *testing.T) {
+	chatUuid := uuid.New()
+
+	tests := []struct {
+		name      string
+		backend   string
+		err       error
+		wantError float64
+	}{
+		{name: "ok", backend: "test_ok"},
+		{name: "not_found", backend: "test_not_found", err: storage.ErrChatNotFound},
+		{name: "internal", backend: "test_internal", err: fmt.Errorf("get chat: %w", storage.ErrInternal), wantError: 1},
+		{name: "no_connection", backend: "test_no_connection", err: storage.ErrNoConnection, wantError: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatStorage := mocks.NewChatStorage(t)
+			var want *domain.Chat
+			if tt.err == nil {
+				want = &domain.Chat{Uuid: chatUuid}
+			}
+			chatStorage.On("GetChat", mock.Anything, chatUuid).Return(want, tt.err).Once()
+
+			got, err := NewChat(tt.backend, chatStorage).GetChat(context.Background(), chatUuid)
+			assert.Equal(t, want, got)
+			assert.True(t, errors.Is(err, tt.err))
+
+			duration := &dto.Metric{}
+			assert.NoError(t, callDuration.WithLabelValues(tt.backend, "GetChat").(prometheus.Histogram).Write(duration))
+			assert.Equal(t, uint64(1), duration.GetHistogram().GetSampleCount())
+			assert.Equal(t, tt.wantError, testutil.ToFloat64(callErrors.WithLabelValues(tt.backend, "GetChat")))
+		})
+	}
+}
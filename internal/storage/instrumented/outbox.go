@@ -0,0 +1,122 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/services/admin"
+)
+
+type Outbox struct {
+	backend string
+	storage outbox.OutboxProvider
+}
+
+var _ outbox.OutboxProvider = (*Outbox)(nil)
+
+func NewOutbox(backend string, storage outbox.OutboxProvider) *Outbox {
+	return &Outbox{backend: backend, storage: storage}
+}
+
+func (o *Outbox) ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.Outbox, error) {
+	return call(ctx, o.backend, "ClaimOutboxBatch", func(ctx context.Context) ([]*domain.Outbox, error) {
+		return o.storage.ClaimOutboxBatch(ctx, limit, lease)
+	})
+}
+
+func (o *Outbox) ReleaseOutbox(ctx context.Context, outboxUuids []uuid.UUID) error {
+	return exec(ctx, o.backend, "ReleaseOutbox", func(ctx context.Context) error {
+		return o.storage.ReleaseOutbox(ctx, outboxUuids)
+	})
+}
+
+func (o *Outbox) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
+	return exec(ctx, o.backend, "ConfirmOutboxSended", func(ctx context.Context) error {
+		return o.storage.ConfirmOutboxSended(ctx, outboxUuid)
+	})
+}
+
+func (o *Outbox) FailOutbox(ctx context.Context, outboxUuid uuid.UUID, lastError string, maxAttempts int) (bool, error) {
+	return call(ctx, o.backend, "FailOutbox", func(ctx context.Context) (bool, error) {
+		return o.storage.FailOutbox(ctx, outboxUuid, lastError, maxAttempts)
+	})
+}
+
+type Backlog struct {
+	backend string
+	storage outbox.BacklogProvider
+}
+
+var _ outbox.BacklogProvider = (*Backlog)(nil)
+
+func NewBacklog(backend string, storage outbox.BacklogProvider) *Backlog {
+	return &Backlog{backend: backend, storage: storage}
+}
+
+func (b *Backlog) OutboxBacklog(ctx context.Context) (*domain.OutboxBacklog, error) {
+	return call(ctx, b.backend, "OutboxBacklog", func(ctx context.Context) (*domain.OutboxBacklog, error) {
+		return b.storage.OutboxBacklog(ctx)
+	})
+}
+
+type SentOutbox struct {
+	backend string
+	storage outbox.SentOutboxStorage
+}
+
+var _ outbox.SentOutboxStorage = (*SentOutbox)(nil)
+
+func NewSentOutbox(backend string, storage outbox.SentOutboxStorage) *SentOutbox {
+	return &SentOutbox{backend: backend, storage: storage}
+}
+
+func (s *SentOutbox) DeleteSentOutbox(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	return call(ctx, s.backend, "DeleteSentOutbox", func(ctx context.Context) (int, error) {
+		return s.storage.DeleteSentOutbox(ctx, olderThan, limit)
+	})
+}
+
+type DeadLetters struct {
+	backend string
+	storage admin.DeadLetterStorage
+}
+
+var _ admin.DeadLetterStorage = (*DeadLetters)(nil)
+
+func NewDeadLetters(backend string, storage admin.DeadLetterStorage) *DeadLetters {
+	return &DeadLetters{backend: backend, storage: storage}
+}
+
+func (d *DeadLetters) ListDeadLetters(ctx context.Context, limit int, offset int) ([]*domain.DeadLetter, error) {
+	return call(ctx, d.backend, "ListDeadLetters", func(ctx context.Context) ([]*domain.DeadLetter, error) {
+		return d.storage.ListDeadLetters(ctx, limit, offset)
+	})
+}
+
+func (d *DeadLetters) GetDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error) {
+	return call(ctx, d.backend, "GetDeadLetter", func(ctx context.Context) (*domain.DeadLetter, error) {
+		return d.storage.GetDeadLetter(ctx, deadLetterUuid)
+	})
+}
+
+func (d *DeadLetters) ReplayDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	return exec(ctx, d.backend, "ReplayDeadLetter", func(ctx context.Context) error {
+		return d.storage.ReplayDeadLetter(ctx, deadLetterUuid)
+	})
+}
+
+func (d *DeadLetters) DeleteDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	return exec(ctx, d.backend, "DeleteDeadLetter", func(ctx context.Context) error {
+		return d.storage.DeleteDeadLetter(ctx, deadLetterUuid)
+	})
+}
+
+func (d *DeadLetters) PurgeDeadLetters(ctx context.Context) (int, error) {
+	return call(ctx, d.backend, "PurgeDeadLetters", func(ctx context.Context) (int, error) {
+		return d.storage.PurgeDeadLetters(ctx)
+	})
+}
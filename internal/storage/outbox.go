@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/alexandernizov/grpcmessanger/api/gen/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/tracing"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventSchemaVersion is the version of the events marshalled here, it's stored with every outbox
+const EventSchemaVersion = int(outbox.SchemaVersion_SCHEMA_VERSION_1)
+
+// MarshalChatEvent builds the outbox event for the chats topic
+func MarshalChatEvent(eventUuid uuid.UUID, eventType outbox.EventType, chat *domain.Chat, occurred time.Time) ([]byte, error) {
+	event := outbox.Event{
+		Id:         eventUuid.String(),
+		Type:       eventType,
+		ChatUuid:   chat.Uuid.String(),
+		OccurredAt: timestamppb.New(occurred),
+	}
+	payload := &outbox.Chat{
+		Uuid:      chat.Uuid.String(),
+		OwnerUuid: chat.Owner.Uuid.String(),
+		Readonly:  chat.Readonly,
+		Deadline:  timestamppb.New(chat.Deadline),
+	}
+	switch eventType {
+	case outbox.EventType_CHAT_CREATED:
+		event.ActorUuid = chat.Owner.Uuid.String()
+		event.Payload = &outbox.Event_ChatCreated{ChatCreated: &outbox.ChatCreated{Chat: payload}}
+	case outbox.EventType_CHAT_EXPIRED:
+		event.Payload = &outbox.Event_ChatExpired{ChatExpired: &outbox.ChatExpired{Chat: payload}}
+	default:
+		return nil, ErrInternal
+	}
+
+	return proto.Marshal(&event)
+}
+
+// MarshalMessageEvent builds the outbox event for the messages topic.
+// actorUuid is the user who edited or deleted the message, the author is the actor of posted ones.
+func MarshalMessageEvent(eventUuid uuid.UUID, eventType outbox.EventType, chatUuid uuid.UUID, actorUuid uuid.UUID, message *domain.Message) ([]byte, error) {
+	event := outbox.Event{
+		Id:        eventUuid.String(),
+		Type:      eventType,
+		ChatUuid:  chatUuid.String(),
+		ActorUuid: actorUuid.String(),
+	}
+	payload := &outbox.Message{
+		Id:         int64(message.Id),
+		AuthorUuid: message.AuthorUuid.String(),
+		Body:       message.Body,
+		Published:  timestamppb.New(message.Published),
+	}
+	if len(message.Edits) > 0 {
+		payload.Edited = timestamppb.New(message.Edits[len(message.Edits)-1].Edited)
+	}
+	if message.Deleted() {
+		payload.Deleted = timestamppb.New(message.DeletedAt)
+	}
+	switch eventType {
+	case outbox.EventType_MESSAGE_POSTED:
+		event.ActorUuid = message.AuthorUuid.String()
+		event.OccurredAt = payload.Published
+		event.Payload = &outbox.Event_MessagePosted{MessagePosted: &outbox.MessagePosted{Message: payload}}
+	case outbox.EventType_MESSAGE_EDITED:
+		event.OccurredAt = payload.Edited
+		event.Payload = &outbox.Event_MessageEdited{MessageEdited: &outbox.MessageEdited{Message: payload}}
+	case outbox.EventType_MESSAGE_DELETED:
+		event.OccurredAt = payload.Deleted
+		event.Payload = &outbox.Event_MessageDeleted{MessageDeleted: &outbox.MessageDeleted{Message: payload}}
+	default:
+		return nil, ErrInternal
+	}
+
+	return proto.Marshal(&event)
+}
+
+// OutboxTraceContext returns the trace context of ctx to be stored with an outbox, it's nil without a trace
+func OutboxTraceContext(ctx context.Context) []byte {
+	return MarshalTraceContext(tracing.Inject(ctx))
+}
+
+// MarshalTraceContext returns nil for an empty trace context
+func MarshalTraceContext(traceContext map[string]string) []byte {
+	if len(traceContext) == 0 {
+		return nil
+	}
+	marshalled, err := json.Marshal(traceContext)
+	if err != nil {
+		return nil
+	}
+	return marshalled
+}
+
+// UnmarshalTraceContext reads the stored trace context, outboxes without one or added before tracing have none
+func UnmarshalTraceContext(marshalled []byte) map[string]string {
+	if len(marshalled) == 0 {
+		return nil
+	}
+	var traceContext map[string]string
+	if err := json.Unmarshal(marshalled, &traceContext); err != nil {
+		return nil
+	}
+	return traceContext
+}
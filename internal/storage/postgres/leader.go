@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+)
+
+// leaderLockKey is the advisory lock held by the leader replica
+const leaderLockKey int64 = 7_265_424_170_115
+
+// LeaderLock is a session advisory lock held on a connection of its own.
+// Postgres releases it when the connection is lost, so a dead leader is replaced as soon as its session ends.
+type LeaderLock struct {
+	log *slog.Logger
+	db  *sql.DB
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// LeaderLock returns the lock replicas compete for to become the leader
+func (p *Postgres) LeaderLock() *LeaderLock {
+	return &LeaderLock{log: p.log, db: p.db}
+}
+
+func (l *LeaderLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		return true, nil
+	}
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("can't get connection: %w", ErrNoConnection)
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", leaderLockKey).Scan(&acquired)
+	if err != nil {
+		discard(conn)
+		return false, fmt.Errorf("can't take leader lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Renew checks that the session holding the lock is alive
+func (l *LeaderLock) Renew(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return false, nil
+	}
+	if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err != nil {
+		// The lock may be gone with the session
+		discard(l.conn)
+		l.conn = nil
+		return false, fmt.Errorf("can't check leader lock: %w", err)
+	}
+	return true, nil
+}
+
+func (l *LeaderLock) Release(ctx context.Context) error {
+	const op = "postgres.ReleaseLeaderLock"
+	log := l.log.With(slog.String("op", op))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", leaderLockKey); err != nil {
+		log.Error("can't unlock, the session is closed instead", sl.Err(err))
+		discard(conn)
+		return nil
+	}
+	return conn.Close()
+}
+
+// discard closes the session of the connection instead of returning it to the pool,
+// so a lock taken by the session can't stay with a pooled connection
+func discard(conn *sql.Conn) {
+	conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+}
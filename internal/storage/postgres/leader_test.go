@@ -0,0 +1,75 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexandernizov/grpcmessanger/internal/storage/postgres"
+)
+
+func TestLeaderLock(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	lock := postgres.New(log, db).LeaderLock()
+	ctx := context.Background()
+
+	// Another replica holds the lock
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	acquired, err := lock.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	held, err := lock.Renew(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	acquired, err = lock.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The lock is checked on the connection which holds it
+	mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	held, err = lock.Renew(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	mock.ExpectExec("SELECT pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, lock.Release(ctx))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLeaderLockLost(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	lock := postgres.New(log, db).LeaderLock()
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	acquired, err := lock.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The session has gone and the lock with it
+	mock.ExpectExec("SELECT 1").WillReturnError(errors.New("connection reset"))
+	held, err := lock.Renew(ctx)
+	assert.Error(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, lock.Release(ctx))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
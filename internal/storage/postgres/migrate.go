@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/alexandernizov/grpcmessanger/migrations"
+)
+
+const schemaMigrationsTable = "schema_migrations"
+
+// migrationLockKey is the advisory lock taken while migrating, so replicas starting together don't race
+const migrationLockKey int64 = 7_265_424_170_113
+
+var (
+	ErrDirtyMigration   = errors.New("database is dirty after a failed migration, fix it by hand")
+	ErrNoMigration      = errors.New("have no migration to roll back")
+	ErrUnknownMigration = errors.New("database version is unknown to this build")
+)
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus is the schema version of the database and versions which are not applied yet.
+// Version 0 is an empty database.
+type MigrationStatus struct {
+	Version int64
+	Dirty   bool
+	Pending []int64
+}
+
+// Migrator applies versioned schema migrations.
+// Versions are kept the same way golang-migrate does it, so databases migrated by it are picked up as is.
+type Migrator struct {
+	log        *slog.Logger
+	db         *sql.DB
+	migrations []migration
+}
+
+// Migrator returns the migrator of the schema embedded into the binary
+func (p *Postgres) Migrator() (*Migrator, error) {
+	return NewMigrator(p.log, p.db, migrations.FS)
+}
+
+func NewMigrator(log *slog.Logger, db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("can't read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s has invalid version", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("can't read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		}
+		if match[3] == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	res := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", m.version)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].version < res[j].version })
+
+	return &Migrator{log: log, db: db, migrations: res}, nil
+}
+
+// Up applies all pending migrations and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	const op = "postgres.MigrateUp"
+	log := m.log.With(slog.String("op", op))
+
+	applied := 0
+	err := m.locked(ctx, func(conn *sql.Conn, version int64) error {
+		for _, next := range m.migrations {
+			if next.version <= version {
+				continue
+			}
+			if err := m.apply(ctx, conn, next.up, next.version); err != nil {
+				return fmt.Errorf("can't apply migration %d_%s: %w", next.version, next.name, err)
+			}
+			log.Info("migration applied", slog.Int64("version", next.version), slog.String("name", next.name))
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("migration failed", sl.Err(err))
+		return applied, err
+	}
+	return applied, nil
+}
+
+// Down rolls back the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	const op = "postgres.MigrateDown"
+	log := m.log.With(slog.String("op", op))
+
+	err := m.locked(ctx, func(conn *sql.Conn, version int64) error {
+		if version == 0 {
+			return ErrNoMigration
+		}
+		pos := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].version >= version })
+		if pos == len(m.migrations) || m.migrations[pos].version != version {
+			return fmt.Errorf("version %d: %w", version, ErrUnknownMigration)
+		}
+
+		var previous int64
+		if pos > 0 {
+			previous = m.migrations[pos-1].version
+		}
+		last := m.migrations[pos]
+		if last.down == "" {
+			return fmt.Errorf("migration %d_%s has no down file", last.version, last.name)
+		}
+		if err := m.apply(ctx, conn, last.down, previous); err != nil {
+			return fmt.Errorf("can't roll back migration %d_%s: %w", last.version, last.name, err)
+		}
+		log.Info("migration rolled back", slog.Int64("version", last.version), slog.String("name", last.name))
+		return nil
+	})
+	if err != nil {
+		log.Error("migration failed", sl.Err(err))
+		return err
+	}
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get connection: %w", ErrNoConnection)
+	}
+	defer conn.Close()
+
+	var status MigrationStatus
+	status.Version, status.Dirty, err = currentVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range m.migrations {
+		if v.version > status.Version {
+			status.Pending = append(status.Pending, v.version)
+		}
+	}
+	return &status, nil
+}
+
+// locked runs fn with the current version while holding the migration lock.
+// Session advisory locks belong to a connection, so everything is done on the same one.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, version int64) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("can't get connection: %w", ErrNoConnection)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("can't take migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			m.log.Error("can't release migration lock", sl.Err(err))
+		}
+	}()
+
+	version, dirty, err := currentVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("version %d: %w", version, ErrDirtyMigration)
+	}
+	return fn(conn, version)
+}
+
+func currentVersion(ctx context.Context, conn *sql.Conn) (version int64, dirty bool, err error) {
+	query1 := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)", schemaMigrationsTable)
+	query2 := fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", schemaMigrationsTable)
+
+	_, err = conn.ExecContext(ctx, query1)
+	if err == nil {
+		err = conn.QueryRowContext(ctx, query2).Scan(&version, &dirty)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("can't read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// apply runs the migration and stores the new version in one transaction, a failed migration leaves nothing behind
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, body string, version int64) error {
+	query1 := fmt.Sprintf("DELETE FROM %s", schemaMigrationsTable)
+	query2 := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES ($1, false)", schemaMigrationsTable)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, body)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, query1)
+	}
+	if err == nil && version > 0 {
+		_, err = tx.ExecContext(ctx, query2, version)
+	}
+	if err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			m.log.Error("error according rollback transaction in DB", sl.Err(errRollback))
+		}
+		return err
+	}
+	return tx.Commit()
+}
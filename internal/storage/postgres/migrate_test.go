@@ -0,0 +1,189 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexandernizov/grpcmessanger/internal/storage/postgres"
+	"github.com/alexandernizov/grpcmessanger/migrations"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_init.up.sql":     {Data: []byte("CREATE TABLE first (id INTEGER)")},
+	"000001_init.down.sql":   {Data: []byte("DROP TABLE first")},
+	"000002_second.up.sql":   {Data: []byte("CREATE TABLE second (id INTEGER)")},
+	"000002_second.down.sql": {Data: []byte("DROP TABLE second")},
+	"README.md":              {Data: []byte("not a migration")},
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if version > 0 {
+		rows.AddRow(version, dirty)
+	}
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").WillReturnRows(rows)
+}
+
+func expectApply(mock sqlmock.Sqlmock, body string, version int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(body).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 {
+		mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	t.Parallel()
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err = postgres.NewMigrator(log, db, migrations.FS)
+	assert.NoError(t, err)
+}
+
+func TestMigrateUp(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		version int64
+		dirty   bool
+		applied int
+		wantErr error
+	}{
+		{name: "empty database", version: 0, applied: 2},
+		{name: "partly migrated", version: 1, applied: 1},
+		{name: "up to date", version: 2, applied: 0},
+		{name: "dirty", version: 1, dirty: true, wantErr: postgres.ErrDirtyMigration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			migrator, err := postgres.NewMigrator(log, db, testMigrations)
+			require.NoError(t, err)
+
+			mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+			expectVersion(mock, tt.version, tt.dirty)
+			if tt.wantErr == nil {
+				if tt.version < 1 {
+					expectApply(mock, "CREATE TABLE first", 1)
+				}
+				if tt.version < 2 {
+					expectApply(mock, "CREATE TABLE second", 2)
+				}
+			}
+			mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			applied, err := migrator.Up(context.Background())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.applied, applied)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrateUpFailed(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	migrator, err := postgres.NewMigrator(log, db, testMigrations)
+	require.NoError(t, err)
+
+	// The failed migration is rolled back together with its version
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectVersion(mock, 1, false)
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE second").WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+	assert.Error(t, err)
+	assert.Zero(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateDown(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		version  int64
+		body     string
+		previous int64
+		wantErr  error
+	}{
+		{name: "last", version: 2, body: "DROP TABLE second", previous: 1},
+		{name: "first", version: 1, body: "DROP TABLE first", previous: 0},
+		{name: "empty database", version: 0, wantErr: postgres.ErrNoMigration},
+		{name: "unknown version", version: 3, wantErr: postgres.ErrUnknownMigration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			migrator, err := postgres.NewMigrator(log, db, testMigrations)
+			require.NoError(t, err)
+
+			mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+			expectVersion(mock, tt.version, false)
+			if tt.wantErr == nil {
+				expectApply(mock, tt.body, tt.previous)
+			}
+			mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			err = migrator.Down(context.Background())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	migrator, err := postgres.NewMigrator(log, db, testMigrations)
+	require.NoError(t, err)
+
+	expectVersion(mock, 1, false)
+
+	status, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &postgres.MigrationStatus{Version: 1, Pending: []int64{2}}, status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -13,8 +13,7 @@ import (
 	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
 	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
-	"google.golang.org/protobuf/proto"
+	"github.com/lib/pq"
 )
 
 type Postgres struct {
@@ -36,12 +35,22 @@ var (
 
 const (
 	usersTable         = "users"
-	refreshTokensTable = "refresh_tokens"
+	sessionsTable      = "sessions"
 	chatsTable         = "chats"
 	messagesTable      = "messages"
 	outboxTable        = "outbox"
+	deadLettersTable   = "outbox_dead_letters"
+	outboxSeqsTable    = "outbox_chat_seqs"
+	chatMembersTable   = "chat_members"
+	messageEditsTable  = "message_edits"
+	revokedTokensTable = "revoked_tokens"
+	userQuotasTable    = "user_quotas"
+	messageTimesTable  = "user_message_times"
 )
 
+// foreignKeyViolation is the postgres error code for a missing referenced row
+const foreignKeyViolation = "23503"
+
 func New(log *slog.Logger, db *sql.DB) *Postgres {
 	return &Postgres{log, db}
 }
@@ -221,14 +230,15 @@ func (p *Postgres) GetUserByUuid(ctx context.Context, uuid uuid.UUID) (*domain.U
 	return &domain.User{Uuid: pgUser.Uuid, Login: pgUser.Login, PasswordHash: pgUser.PasswordHash}, nil
 }
 
-func (p *Postgres) UpsertRefreshToken(ctx context.Context, userUuid uuid.UUID, refreshToken string) error {
-	const op = "postgres.UpsertRefreshToken"
+// RevokeToken puts the token into the denylist until it expires
+func (p *Postgres) RevokeToken(ctx context.Context, jti uuid.UUID, expired time.Time) error {
+	const op = "postgres.RevokeToken"
 	log := p.log.With(slog.String("op", op))
 
 	tx, closeTx := p.extractTx(ctx)
 
-	query := fmt.Sprintf("INSERT INTO %s (user_uuid, token) VALUES($1, $2) ON CONFLICT (user_uuid) DO UPDATE SET token = ($2)", refreshTokensTable)
-	_, err := tx.Exec(query, userUuid, refreshToken)
+	query := fmt.Sprintf("INSERT INTO %s (jti, expired) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING", revokedTokensTable)
+	_, err := tx.Exec(query, jti, expired)
 	closeTx(err)
 
 	if err != nil {
@@ -239,42 +249,183 @@ func (p *Postgres) UpsertRefreshToken(ctx context.Context, userUuid uuid.UUID, r
 	return nil
 }
 
-func (p *Postgres) GetRefreshToken(ctx context.Context, userUuid uuid.UUID) (string, error) {
-	const op = "postgres.GetRefreshToken"
+// IsTokenRevoked reports whether the token is in the denylist or its session is gone
+func (p *Postgres) IsTokenRevoked(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID, jti uuid.UUID) (bool, error) {
+	const op = "postgres.IsTokenRevoked"
 	log := p.log.With(slog.String("op", op))
 
 	tx, closeTx := p.extractTx(ctx)
 
-	var token string
+	var revoked bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE jti = $1)
+		OR NOT EXISTS (SELECT 1 FROM %s WHERE uuid = $2 AND user_uuid = $3)`, revokedTokensTable, sessionsTable)
+	err := tx.QueryRow(query, jti, sessionUuid, userUuid).Scan(&revoked)
+	closeTx(err)
+
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+
+	return revoked, nil
+}
+
+func (p *Postgres) CreateSession(ctx context.Context, session domain.Session) error {
+	const op = "postgres.CreateSession"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
 
-	query := fmt.Sprintf("SELECT token FROM %s WHERE user_uuid = $1", refreshTokensTable)
-	row := tx.QueryRow(query, userUuid)
-	err := row.Scan(&token)
+	query := fmt.Sprintf(`INSERT INTO %s (uuid, user_uuid, refresh_jti, device_name, user_agent, created, last_used, expires)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`, sessionsTable)
+	_, err := tx.Exec(query, session.Uuid, session.UserUuid, session.RefreshJti, session.DeviceName, session.UserAgent,
+		session.Created, session.LastUsed, session.Expires)
 	closeTx(err)
 
-	if errors.Is(err, sql.ErrNoRows) {
-		return "", storage.ErrTokenNotFound
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return storage.ErrInternal
 	}
+
+	return nil
+}
+
+func (p *Postgres) GetSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error) {
+	const op = "postgres.GetSession"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`SELECT uuid, user_uuid, refresh_jti, device_name, user_agent, created, last_used, expires
+		FROM %s WHERE uuid = $1 AND expires > $2`, sessionsTable)
+	res, err := scanSessions(tx.Query(query, sessionUuid, time.Now()))
+	closeTx(err)
+
 	if err != nil {
 		log.Info("error: ", sl.Err(err))
-		return "", storage.ErrInternal
+		return nil, storage.ErrInternal
+	}
+	if len(res) == 0 {
+		return nil, storage.ErrSessionNotFound
 	}
 
-	return token, nil
+	return res[0], nil
 }
 
-func (p *Postgres) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat, error) {
-	const op = "postgres.CreateChat"
+// RotateSession stores the new refresh token of the session if previousJti is still the current one.
+// Otherwise the previous token was used already and storage.ErrSessionNotFound is returned.
+func (p *Postgres) RotateSession(ctx context.Context, session domain.Session, previousJti uuid.UUID) error {
+	const op = "postgres.RotateSession"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("UPDATE %s SET refresh_jti = $3, last_used = $4, expires = $5 WHERE uuid = $1 AND refresh_jti = $2", sessionsTable)
+	res, err := tx.Exec(query, session.Uuid, previousJti, session.RefreshJti, session.LastUsed, session.Expires)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if affected == 0 {
+		return storage.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	const op = "postgres.ListSessions"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`SELECT uuid, user_uuid, refresh_jti, device_name, user_agent, created, last_used, expires
+		FROM %s WHERE user_uuid = $1 AND expires > $2 ORDER BY created`, sessionsTable)
+	res, err := scanSessions(tx.Query(query, userUuid, time.Now()))
+	closeTx(err)
+
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return res, nil
+}
+
+func scanSessions(rows *sql.Rows, err error) ([]*domain.Session, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*domain.Session
+	for rows.Next() {
+		var session domain.Session
+		err := rows.Scan(&session.Uuid, &session.UserUuid, &session.RefreshJti, &session.DeviceName, &session.UserAgent,
+			&session.Created, &session.LastUsed, &session.Expires)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &session)
+	}
+	return res, rows.Err()
+}
+
+func (p *Postgres) DeleteSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	const op = "postgres.DeleteSession"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE uuid = $1 AND user_uuid = $2", sessionsTable)
+	res, err := tx.Exec(query, sessionUuid, userUuid)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if affected == 0 {
+		return storage.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteUserSessions(ctx context.Context, userUuid uuid.UUID) error {
+	const op = "postgres.DeleteUserSessions"
 	log := p.log.With(slog.String("op", op))
 
-	msg := outbox.OutboxChat{
-		Uuid:      chat.Uuid.String(),
-		OwnerUuid: chat.Owner.Uuid.String(),
-		Readonly:  chat.Readonly,
-		Deadline:  chat.Deadline.String(),
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_uuid = $1", sessionsTable)
+	_, err := tx.Exec(query, userUuid)
+	closeTx(err)
+
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return storage.ErrInternal
 	}
 
-	marshalledMessage, err := proto.Marshal(&msg)
+	return nil
+}
+
+func (p *Postgres) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat, error) {
+	const op = "postgres.CreateChat"
+	log := p.log.With(slog.String("op", op))
+
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalChatEvent(eventUuid, outbox.EventType_CHAT_CREATED, &chat, time.Now())
 	if err != nil {
 		return &domain.Chat{}, storage.ErrInternal
 	}
@@ -284,9 +435,14 @@ func (p *Postgres) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Ch
 	pgChat := Chat{Uuid: chat.Uuid, Owner: chat.Owner.Uuid, ReadOnly: chat.Readonly, Deadline: &chat.Deadline}
 
 	query1 := fmt.Sprintf("INSERT INTO %s (uuid, owner, read_only, dead_line) VALUES ($1,$2,$3,$4)", chatsTable)
-	query2 := fmt.Sprintf("INSERT INTO %s (uuid, topic, message) VALUES ($1,$2,$3)", outboxTable)
+	query2 := fmt.Sprintf("INSERT INTO %s (chat_uuid, user_uuid, role, joined) VALUES ($1,$2,$3,$4)", chatMembersTable)
 	_, err = tx.Exec(query1, pgChat.Uuid, pgChat.Owner, pgChat.ReadOnly, pgChat.Deadline)
-	_, err = tx.Exec(query2, chat.Uuid, domain.ChatTopic, marshalledMessage)
+	if err == nil {
+		_, err = tx.Exec(query2, pgChat.Uuid, pgChat.Owner, domain.RoleOwner, time.Now())
+	}
+	if err == nil {
+		err = insertOutbox(ctx, tx, eventUuid, chat.Uuid, domain.ChatTopic, marshalledMessage)
+	}
 
 	closeTx(err)
 
@@ -305,26 +461,29 @@ func (p *Postgres) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Cha
 	tx, closeTx := p.extractTx(ctx)
 
 	var chat Chat
+	var owner User
 
-	query := fmt.Sprintf("SELECT uuid, owner, read_only, dead_line FROM %s WHERE uuid = $1;", chatsTable)
-	row := tx.QueryRow(query, chatUuid)
-	err := row.Scan(&chat.Uuid, &chat.Owner, &chat.ReadOnly, &chat.Deadline)
+	// Expired chats are left for the reaper, but they are already gone for users
+	query := fmt.Sprintf(`SELECT c.uuid, c.owner, c.read_only, c.dead_line, u.login, u.password
+		FROM %s c JOIN %s u ON u.uuid = c.owner WHERE c.uuid = $1 AND c.dead_line > $2;`, chatsTable, usersTable)
+	row := tx.QueryRow(query, chatUuid, time.Now())
+	err := row.Scan(&chat.Uuid, &chat.Owner, &chat.ReadOnly, &chat.Deadline, &owner.Login, &owner.PasswordHash)
 	closeTx(err)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, storage.ErrTokenNotFound
+		return nil, storage.ErrChatNotFound
 	}
 	if err != nil {
 		log.Info("error: ", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
 
-	user, err := p.GetUserByUuid(ctx, chat.Owner)
-	if err != nil {
-		return nil, storage.ErrInternal
-	}
-
-	return &domain.Chat{Uuid: chat.Uuid, Owner: *user, Readonly: chat.ReadOnly, Deadline: *chat.Deadline}, nil
+	return &domain.Chat{
+		Uuid:     chat.Uuid,
+		Owner:    domain.User{Uuid: chat.Owner, Login: owner.Login, PasswordHash: owner.PasswordHash},
+		Readonly: chat.ReadOnly,
+		Deadline: *chat.Deadline,
+	}, nil
 }
 
 func (p *Postgres) ChatsCount(ctx context.Context) (int, error) {
@@ -334,8 +493,8 @@ func (p *Postgres) ChatsCount(ctx context.Context) (int, error) {
 	tx, closeTx := p.extractTx(ctx)
 
 	var count int
-	query := "SELECT count (*) FROM chats;"
-	row := tx.QueryRow(query)
+	query := fmt.Sprintf("SELECT count (*) FROM %s WHERE dead_line > $1;", chatsTable)
+	row := tx.QueryRow(query, time.Now())
 	err := row.Scan(&count)
 	closeTx(err)
 
@@ -347,31 +506,93 @@ func (p *Postgres) ChatsCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (p *Postgres) PostMessage(ctx context.Context, chat uuid.UUID, message domain.Message) (*domain.Message, error) {
-	const op = "postgres.PostMessage"
+// DeleteExpiredChats deletes up to limit chats whose deadline has passed, their messages and members go by cascade.
+// A chat expired event is added to the outbox for every deleted chat.
+func (p *Postgres) DeleteExpiredChats(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	const op = "postgres.DeleteExpiredChats"
 	log := p.log.With(slog.String("op", op))
 
-	msg := outbox.OutboxMessage{
-		Id:         int64(message.Id),
-		AuthorUuid: message.AuthorUuid.String(),
-		Body:       message.Body,
-		Published:  message.Published.String(),
+	tx, closeTx := p.extractTx(ctx)
+
+	query1 := fmt.Sprintf(`DELETE FROM %[1]s WHERE uuid IN
+		(SELECT uuid FROM %[1]s WHERE dead_line <= $1 ORDER BY dead_line LIMIT $2 FOR UPDATE SKIP LOCKED)
+		RETURNING uuid, owner, read_only, dead_line`, chatsTable)
+	query2 := fmt.Sprintf("DELETE FROM %s WHERE chat_uuid = $1", outboxSeqsTable)
+
+	var expired []domain.Chat
+	rows, err := tx.Query(query1, now, limit)
+	if err == nil {
+		for rows.Next() {
+			var chat Chat
+			if err = rows.Scan(&chat.Uuid, &chat.Owner, &chat.ReadOnly, &chat.Deadline); err != nil {
+				break
+			}
+			expired = append(expired, domain.Chat{Uuid: chat.Uuid, Owner: domain.User{Uuid: chat.Owner}, Readonly: chat.ReadOnly, Deadline: *chat.Deadline})
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+	}
+	for i := 0; err == nil && i < len(expired); i++ {
+		var marshalledMessage []byte
+		eventUuid := uuid.New()
+		marshalledMessage, err = storage.MarshalChatEvent(eventUuid, outbox.EventType_CHAT_EXPIRED, &expired[i], now)
+		if err == nil {
+			err = insertOutbox(ctx, tx, eventUuid, expired[i].Uuid, domain.ChatTopic, marshalledMessage)
+		}
+		// The expired event is the last one of the chat
+		if err == nil {
+			_, err = tx.Exec(query2, expired[i].Uuid)
+		}
 	}
 
-	marshalledMessage, err := proto.Marshal(&msg)
+	closeTx(err)
+
 	if err != nil {
-		return &domain.Message{}, storage.ErrInternal
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	res := make([]uuid.UUID, 0, len(expired))
+	for _, chat := range expired {
+		res = append(res, chat.Uuid)
 	}
+	return res, nil
+}
+
+func (p *Postgres) PostMessage(ctx context.Context, chat uuid.UUID, message domain.Message) (*domain.Message, error) {
+	const op = "postgres.PostMessage"
+	log := p.log.With(slog.String("op", op))
 
 	tx, closeTx := p.extractTx(ctx)
 
 	pgMessage := Message{ChatUuid: chat, AuthorUuid: message.AuthorUuid, Body: []byte(message.Body), Published: &message.Published}
 
-	query1 := fmt.Sprintf("INSERT INTO %s (chat_uuid, author_uuid, body, published) VALUES ($1,$2,$3,$4)", messagesTable)
-	query2 := fmt.Sprintf("INSERT INTO %s (uuid, topic, message) VALUES ($1,$2,$3)", outboxTable)
+	query1 := fmt.Sprintf("INSERT INTO %s (chat_uuid, author_uuid, body, published) VALUES ($1,$2,$3,$4) RETURNING id", messagesTable)
+
+	err := tx.QueryRow(query1, pgMessage.ChatUuid, pgMessage.AuthorUuid, pgMessage.Body, pgMessage.Published).Scan(&message.Id)
+	if err != nil {
+		closeTx(err)
+		log.Error("error: %v", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	err = logMessageTime(tx, message.AuthorUuid, message.Published)
+	if err != nil {
+		closeTx(err)
+		log.Error("error: %v", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalMessageEvent(eventUuid, outbox.EventType_MESSAGE_POSTED, chat, message.AuthorUuid, &message)
+	if err != nil {
+		closeTx(err)
+		return nil, storage.ErrInternal
+	}
 
-	_, err = tx.Exec(query1, pgMessage.ChatUuid, pgMessage.AuthorUuid, pgMessage.Body, pgMessage.Published)
-	_, err = tx.Exec(query2, uuid.New(), domain.MessageTopic, marshalledMessage)
+	err = insertOutbox(ctx, tx, eventUuid, chat, domain.MessageTopic, marshalledMessage)
 	closeTx(err)
 
 	if err != nil {
@@ -382,6 +603,20 @@ func (p *Postgres) PostMessage(ctx context.Context, chat uuid.UUID, message doma
 	return &message, nil
 }
 
+// messageTimesWindow is how long publication times are kept for the messages per minute quota
+const messageTimesWindow = time.Hour
+
+// logMessageTime adds the publication time to the log of the author and drops the times older than the window
+func logMessageTime(tx *sql.Tx, authorUuid uuid.UUID, published time.Time) error {
+	query := fmt.Sprintf("INSERT INTO %s (user_uuid, published) VALUES ($1,$2)", messageTimesTable)
+	if _, err := tx.Exec(query, authorUuid, published); err != nil {
+		return err
+	}
+	query = fmt.Sprintf("DELETE FROM %s WHERE user_uuid = $1 AND published <= $2", messageTimesTable)
+	_, err := tx.Exec(query, authorUuid, published.Add(-messageTimesWindow))
+	return err
+}
+
 func (p *Postgres) TrimMessages(ctx context.Context, chat uuid.UUID, maximumMessages int) (bool, error) {
 	const op = "postgres.TrimMessages"
 	log := p.log.With(slog.String("op", op))
@@ -392,7 +627,7 @@ func (p *Postgres) TrimMessages(ctx context.Context, chat uuid.UUID, maximumMess
 	WITH numbered_messages AS (
     	SELECT 
         	id,
-        	ROW_NUMBER() OVER (PARTITION BY chat_uuid ORDER BY published DESC) AS row_num
+        	ROW_NUMBER() OVER (PARTITION BY chat_uuid ORDER BY id DESC) AS row_num
     	FROM 
         	messages
 		WHERE
@@ -405,86 +640,794 @@ func (p *Postgres) TrimMessages(ctx context.Context, chat uuid.UUID, maximumMess
     	WHERE row_num > $2
 	);`
 
-	_, err := tx.Exec(query, chat, maximumMessages)
+	res, err := tx.Exec(query, chat, maximumMessages)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
 	closeTx(err)
 	if err != nil {
 		log.Error("error: %v", sl.Err(err))
 		return false, storage.ErrInternal
 	}
 
-	return true, nil
+	return affected > 0, nil
 }
 
-func (p *Postgres) GetChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error) {
-	const op = "postgres.GetChatHistory"
+func (p *Postgres) GetChatHistoryPage(ctx context.Context, chatUuid uuid.UUID, query domain.HistoryQuery) ([]*domain.Message, error) {
+	const op = "postgres.GetChatHistoryPage"
 	log := p.log.With(slog.String("op", op))
 
 	tx, closeTx := p.extractTx(ctx)
 
-	var res []*domain.Message
+	var sqlQuery string
+	var args []any
+	forward := query.AfterId > 0
+	if forward {
+		sqlQuery = fmt.Sprintf(`SELECT id, author_uuid, body, published, deleted_at FROM %s
+			WHERE chat_uuid = $1 AND id > $2 ORDER BY id ASC LIMIT $3`, messagesTable)
+		args = []any{chatUuid, query.AfterId, query.Limit}
+	} else {
+		sqlQuery = fmt.Sprintf(`SELECT id, author_uuid, body, published, deleted_at FROM %s
+			WHERE chat_uuid = $1 AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3`, messagesTable)
+		args = []any{chatUuid, query.BeforeId, query.Limit}
+	}
 
-	query := fmt.Sprintf("SELECT id, author_uuid, body, published FROM %s WHERE chat_uuid = $1", messagesTable)
-	rows, err := tx.Query(query, chatUuid)
-	defer closeTx(err)
+	res, err := scanMessages(tx.Query(sqlQuery, args...))
+	if err == nil {
+		err = loadEdits(tx, res)
+	}
+	closeTx(err)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return res, nil
-		}
 		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	// Newest page is selected in reverse, history is always returned oldest first
+	if !forward {
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+	}
+
+	return res, nil
+}
+
+func scanMessages(rows *sql.Rows, err error) ([]*domain.Message, error) {
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
+	var res []*domain.Message
 	for rows.Next() {
 		var msg domain.Message
-		err := rows.Scan(&msg.Id, &msg.AuthorUuid, &msg.Body, &msg.Published)
+		var deletedAt sql.NullTime
+		err := rows.Scan(&msg.Id, &msg.AuthorUuid, &msg.Body, &msg.Published, &deletedAt)
 		if err != nil {
-			log.Error("error scanning row: ", sl.Err(err))
 			return nil, err
 		}
+		msg.DeletedAt = deletedAt.Time
 		res = append(res, &msg)
 	}
+	return res, rows.Err()
+}
+
+// loadEdits fills edit histories of the messages with a single query
+func loadEdits(tx *sql.Tx, messages []*domain.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
 
-	return res, nil
+	byId := make(map[int]*domain.Message, len(messages))
+	ids := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		byId[msg.Id] = msg
+		ids = append(ids, int64(msg.Id))
+	}
+
+	query := fmt.Sprintf("SELECT message_id, body, edited FROM %s WHERE message_id = ANY($1) ORDER BY id", messageEditsTable)
+	rows, err := tx.Query(query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageId int
+		var edit domain.MessageEdit
+		if err := rows.Scan(&messageId, &edit.Body, &edit.Edited); err != nil {
+			return err
+		}
+		if msg, ok := byId[messageId]; ok {
+			msg.Edits = append(msg.Edits, edit)
+		}
+	}
+	return rows.Err()
+}
+
+func getMessage(tx *sql.Tx, chatUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	query := fmt.Sprintf("SELECT id, author_uuid, body, published, deleted_at FROM %s WHERE chat_uuid = $1 AND id = $2", messagesTable)
+	res, err := scanMessages(tx.Query(query, chatUuid, messageId))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, storage.ErrMessageNotFound
+	}
+	if err := loadEdits(tx, res); err != nil {
+		return nil, err
+	}
+	return res[0], nil
 }
 
-func (p *Postgres) GetNextOutbox(ctx context.Context) (*domain.Outbox, error) {
-	const op = "postgres.GetNextOutbox"
+func (p *Postgres) GetMessage(ctx context.Context, chatUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	const op = "postgres.GetMessage"
 	log := p.log.With(slog.String("op", op))
 
 	tx, closeTx := p.extractTx(ctx)
 
-	var next domain.Outbox
-
-	query := fmt.Sprintf("SELECT uuid, topic, message FROM %s WHERE sent_at IS NULL;", outboxTable)
-	row := tx.QueryRow(query)
-	err := row.Scan(&next.Uuid, &next.Topic, &next.Message)
+	message, err := getMessage(tx, chatUuid, messageId)
 	closeTx(err)
 
+	if errors.Is(err, storage.ErrMessageNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return message, nil
+}
+
+// EditMessage replaces the body of the message and keeps the previous one in its edit history.
+// Deleted messages can't be edited.
+func (p *Postgres) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string, edited time.Time) (*domain.Message, error) {
+	const op = "postgres.EditMessage"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query1 := fmt.Sprintf("SELECT body FROM %s WHERE chat_uuid = $1 AND id = $2 AND deleted_at IS NULL FOR UPDATE", messagesTable)
+	query2 := fmt.Sprintf("INSERT INTO %s (message_id, body, edited) VALUES ($1,$2,$3)", messageEditsTable)
+	query3 := fmt.Sprintf("UPDATE %s SET body = $1 WHERE id = $2", messagesTable)
+
+	var previous string
+	err := tx.QueryRow(query1, chatUuid, messageId).Scan(&previous)
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, storage.ErrNoOutbox
+		closeTx(err)
+		return nil, storage.ErrMessageNotFound
+	}
+	if err == nil {
+		_, err = tx.Exec(query2, messageId, previous, edited)
+	}
+	if err == nil {
+		_, err = tx.Exec(query3, body, messageId)
+	}
+	var message *domain.Message
+	if err == nil {
+		message, err = getMessage(tx, chatUuid, messageId)
+	}
+	eventUuid := uuid.New()
+	var marshalledMessage []byte
+	if err == nil {
+		marshalledMessage, err = storage.MarshalMessageEvent(eventUuid, outbox.EventType_MESSAGE_EDITED, chatUuid, actorUuid, message)
 	}
+	if err == nil {
+		err = insertOutbox(ctx, tx, eventUuid, chatUuid, domain.MessageTopic, marshalledMessage)
+	}
+	closeTx(err)
+
 	if err != nil {
-		log.Info("error: ", sl.Err(err))
+		log.Error("error: ", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
 
-	return &next, nil
+	return message, nil
 }
 
-func (p *Postgres) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
-	const op = "postgres.ConfigOutboxSended"
+// DeleteMessage leaves a tombstone instead of the message, its body and edit history are dropped.
+func (p *Postgres) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, deleted time.Time) (*domain.Message, error) {
+	const op = "postgres.DeleteMessage"
 	log := p.log.With(slog.String("op", op))
 
 	tx, closeTx := p.extractTx(ctx)
 
-	query := fmt.Sprintf("UPDATE %s SET sent_at = current_timestamp WHERE uuid = $1", outboxTable)
-	_, err := tx.Exec(query, outboxUuid)
+	query1 := fmt.Sprintf(`UPDATE %s SET body = '', deleted_at = $3
+		WHERE chat_uuid = $1 AND id = $2 AND deleted_at IS NULL RETURNING author_uuid, published`, messagesTable)
+	query2 := fmt.Sprintf("DELETE FROM %s WHERE message_id = $1", messageEditsTable)
+
+	message := domain.Message{Id: messageId, DeletedAt: deleted}
+	err := tx.QueryRow(query1, chatUuid, messageId, deleted).Scan(&message.AuthorUuid, &message.Published)
+	if errors.Is(err, sql.ErrNoRows) {
+		closeTx(err)
+		return nil, storage.ErrMessageNotFound
+	}
+	if err == nil {
+		_, err = tx.Exec(query2, messageId)
+	}
+	eventUuid := uuid.New()
+	var marshalledMessage []byte
+	if err == nil {
+		marshalledMessage, err = storage.MarshalMessageEvent(eventUuid, outbox.EventType_MESSAGE_DELETED, chatUuid, actorUuid, &message)
+	}
+	if err == nil {
+		err = insertOutbox(ctx, tx, eventUuid, chatUuid, domain.MessageTopic, marshalledMessage)
+	}
 	closeTx(err)
 
 	if err != nil {
-		log.Info("error: ", sl.Err(err))
-		return storage.ErrInternal
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
 	}
 
-	return nil
+	return &message, nil
+}
+
+// insertOutbox adds the event to the outbox with the next sequence number of its chat.
+// The counter row stays locked until the transaction ends, so events of a chat are created in sequence order.
+func insertOutbox(ctx context.Context, tx *sql.Tx, outboxUuid uuid.UUID, chatUuid uuid.UUID, topic string, message []byte) error {
+	query1 := fmt.Sprintf(`INSERT INTO %[1]s (chat_uuid, seq) VALUES ($1, 1)
+		ON CONFLICT (chat_uuid) DO UPDATE SET seq = %[1]s.seq + 1 RETURNING seq`, outboxSeqsTable)
+	query2 := fmt.Sprintf(`INSERT INTO %s (uuid, chat_uuid, seq, topic, message, schema_version, trace_context)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)`, outboxTable)
+
+	traceContext := storage.OutboxTraceContext(ctx)
+
+	var seq int64
+	err := tx.QueryRow(query1, chatUuid).Scan(&seq)
+	if err == nil {
+		_, err = tx.Exec(query2, outboxUuid, chatUuid, seq, topic, message, storage.EventSchemaVersion, sql.Null[[]byte]{V: traceContext, Valid: traceContext != nil})
+	}
+	return err
+}
+
+// ClaimOutboxBatch returns up to limit oldest unsent outboxes and claims them for the lease.
+// Rows locked by another publisher are skipped, claimed ones aren't returned again until the lease is over.
+// Outboxes of a chat with claimed outboxes are skipped as well, so a chat is published by one publisher at a time.
+func (p *Postgres) ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.Outbox, error) {
+	const op = "postgres.ClaimOutboxBatch"
+	log := p.log.With(slog.String("op", op))
+
+	// A publisher claims a chat by locking its head, the oldest unsent outbox.
+	// Other publishers never take the rest of a chat without its head, so they skip the chat
+	// while the head is locked and see its lease after the claim is committed.
+	query1 := fmt.Sprintf(`SELECT o.uuid, o.chat_uuid FROM %[1]s o
+		WHERE o.sent_at IS NULL AND (o.claimed_until IS NULL OR o.claimed_until < clock_timestamp())
+		AND NOT EXISTS (SELECT 1 FROM %[1]s c WHERE c.chat_uuid = o.chat_uuid AND c.sent_at IS NULL AND (c.seq < o.seq OR c.claimed_until >= clock_timestamp()))
+		ORDER BY o.created_at LIMIT $1 FOR UPDATE SKIP LOCKED`, outboxTable)
+	query2 := fmt.Sprintf(`SELECT o.uuid, o.chat_uuid, o.seq, o.topic, o.message, o.schema_version, o.trace_context, o.attempts, o.last_error FROM %s o
+		WHERE o.sent_at IS NULL AND (o.claimed_until IS NULL OR o.claimed_until < clock_timestamp())
+		AND (o.uuid = ANY($1::uuid[]) OR o.chat_uuid = ANY($2::uuid[]))
+		ORDER BY o.created_at, o.seq LIMIT $3 FOR UPDATE`, outboxTable)
+	query3 := fmt.Sprintf("UPDATE %s SET claimed_until = clock_timestamp() + $2 * interval '1 millisecond' WHERE uuid = ANY($1::uuid[])", outboxTable)
+
+	var res []*domain.Outbox
+	err := p.WithTx(ctx, func(ctx context.Context) error {
+		tx, _ := p.extractTx(ctx)
+
+		heads, chats, err := claimOutboxHeads(tx, query1, limit)
+		if err != nil || len(heads) == 0 {
+			return err
+		}
+		rows, err := tx.Query(query2, pq.Array(heads), pq.Array(chats), limit)
+		if err != nil {
+			return err
+		}
+		var claimed []string
+		for rows.Next() {
+			var next domain.Outbox
+			var chatUuid uuid.NullUUID
+			var traceContext []byte
+			if err = rows.Scan(&next.Uuid, &chatUuid, &next.Seq, &next.Topic, &next.Message, &next.SchemaVersion, &traceContext, &next.Attempts, &next.LastError); err != nil {
+				break
+			}
+			next.ChatUuid = chatUuid.UUID
+			next.TraceContext = storage.UnmarshalTraceContext(traceContext)
+			res = append(res, &next)
+			claimed = append(claimed, next.Uuid.String())
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		rows.Close()
+
+		if err == nil && len(claimed) > 0 {
+			_, err = tx.Exec(query3, pq.Array(claimed), lease.Milliseconds())
+		}
+		return err
+	})
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return res, nil
+}
+
+// claimOutboxHeads locks the heads of chats, outboxes without a chat are heads of their own
+func claimOutboxHeads(tx *sql.Tx, query string, limit int) (heads []string, chats []string, err error) {
+	rows, err := tx.Query(query, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var head uuid.UUID
+		var chatUuid uuid.NullUUID
+		if err := rows.Scan(&head, &chatUuid); err != nil {
+			return nil, nil, err
+		}
+		heads = append(heads, head.String())
+		if chatUuid.Valid {
+			chats = append(chats, chatUuid.UUID.String())
+		}
+	}
+	return heads, chats, rows.Err()
+}
+
+// ReleaseOutbox returns claimed outboxes before their lease is over
+func (p *Postgres) ReleaseOutbox(ctx context.Context, outboxUuids []uuid.UUID) error {
+	const op = "postgres.ReleaseOutbox"
+	log := p.log.With(slog.String("op", op))
+
+	if len(outboxUuids) == 0 {
+		return nil
+	}
+	released := make([]string, len(outboxUuids))
+	for i, v := range outboxUuids {
+		released[i] = v.String()
+	}
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("UPDATE %s SET claimed_until = NULL WHERE uuid = ANY($1::uuid[]) AND sent_at IS NULL", outboxTable)
+	_, err := tx.Exec(query, pq.Array(released))
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+
+	return nil
+}
+
+func (p *Postgres) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
+	const op = "postgres.ConfigOutboxSended"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("UPDATE %s SET sent_at = current_timestamp, claimed_until = NULL WHERE uuid = $1", outboxTable)
+	_, err := tx.Exec(query, outboxUuid)
+	closeTx(err)
+
+	if err != nil {
+		log.Info("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+
+	return nil
+}
+
+// DeleteSentOutbox deletes up to limit outboxes sent more than olderThan ago and returns how many were deleted
+func (p *Postgres) DeleteSentOutbox(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	const op = "postgres.DeleteSentOutbox"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`DELETE FROM %[1]s WHERE uuid IN
+		(SELECT uuid FROM %[1]s WHERE sent_at < current_timestamp - $1 * interval '1 millisecond'
+		ORDER BY sent_at LIMIT $2 FOR UPDATE SKIP LOCKED)`, outboxTable)
+	res, err := tx.Exec(query, olderThan.Milliseconds(), limit)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return 0, storage.ErrInternal
+	}
+
+	return int(affected), nil
+}
+
+// OutboxBacklog counts unsent outboxes and measures how long the oldest one has been waiting
+func (p *Postgres) OutboxBacklog(ctx context.Context) (*domain.OutboxBacklog, error) {
+	const op = "postgres.OutboxBacklog"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`SELECT count(*), COALESCE(EXTRACT(EPOCH FROM clock_timestamp()::timestamp - MIN(created_at)), 0)
+		FROM %s WHERE sent_at IS NULL`, outboxTable)
+	var unsent int
+	var oldestAge float64
+	err := tx.QueryRow(query).Scan(&unsent, &oldestAge)
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return &domain.OutboxBacklog{Unsent: unsent, OldestAge: time.Duration(oldestAge * float64(time.Second))}, nil
+}
+
+// FailOutbox records a failed attempt to publish the outbox.
+// After maxAttempts the outbox is moved to the dead letters and true is returned.
+func (p *Postgres) FailOutbox(ctx context.Context, outboxUuid uuid.UUID, lastError string, maxAttempts int) (bool, error) {
+	const op = "postgres.FailOutbox"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query1 := fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1, last_error = $2, claimed_until = NULL
+		WHERE uuid = $1 AND sent_at IS NULL RETURNING attempts`, outboxTable)
+	query2 := fmt.Sprintf(`INSERT INTO %s (uuid, chat_uuid, seq, topic, message, schema_version, trace_context, attempts, last_error, failed_at)
+		SELECT uuid, chat_uuid, seq, topic, message, schema_version, trace_context, attempts, last_error, $2 FROM %s WHERE uuid = $1`, deadLettersTable, outboxTable)
+	query3 := fmt.Sprintf("DELETE FROM %s WHERE uuid = $1", outboxTable)
+
+	var attempts int
+	err := tx.QueryRow(query1, outboxUuid, lastError).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		closeTx(err)
+		return false, storage.ErrOutboxNotFound
+	}
+	deadLettered := attempts >= maxAttempts
+	if err == nil && deadLettered {
+		_, err = tx.Exec(query2, outboxUuid, time.Now())
+	}
+	if err == nil && deadLettered {
+		_, err = tx.Exec(query3, outboxUuid)
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+
+	return deadLettered, nil
+}
+
+// ListDeadLetters returns a page of dead letters, the oldest failures first
+func (p *Postgres) ListDeadLetters(ctx context.Context, limit int, offset int) ([]*domain.DeadLetter, error) {
+	const op = "postgres.ListDeadLetters"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`SELECT uuid, chat_uuid, seq, topic, message, schema_version, trace_context, attempts, last_error, failed_at FROM %s
+		ORDER BY failed_at, uuid LIMIT $1 OFFSET $2`, deadLettersTable)
+	rows, err := tx.Query(query, limit, offset)
+	if err != nil {
+		closeTx(err)
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	defer rows.Close()
+
+	var res []*domain.DeadLetter
+	for rows.Next() {
+		var next domain.DeadLetter
+		var chatUuid uuid.NullUUID
+		var traceContext []byte
+		if err = rows.Scan(&next.Uuid, &chatUuid, &next.Seq, &next.Topic, &next.Message, &next.SchemaVersion, &traceContext, &next.Attempts, &next.LastError, &next.Failed); err != nil {
+			break
+		}
+		next.ChatUuid = chatUuid.UUID
+		next.TraceContext = storage.UnmarshalTraceContext(traceContext)
+		res = append(res, &next)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return res, nil
+}
+
+func (p *Postgres) GetDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error) {
+	const op = "postgres.GetDeadLetter"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	deadLetter := domain.DeadLetter{Uuid: deadLetterUuid}
+	var chatUuid uuid.NullUUID
+	var traceContext []byte
+
+	query := fmt.Sprintf(`SELECT chat_uuid, seq, topic, message, schema_version, trace_context, attempts, last_error, failed_at
+		FROM %s WHERE uuid = $1`, deadLettersTable)
+	err := tx.QueryRow(query, deadLetterUuid).Scan(&chatUuid, &deadLetter.Seq, &deadLetter.Topic, &deadLetter.Message, &deadLetter.SchemaVersion, &traceContext, &deadLetter.Attempts, &deadLetter.LastError, &deadLetter.Failed)
+	closeTx(err)
+	deadLetter.ChatUuid = chatUuid.UUID
+	deadLetter.TraceContext = storage.UnmarshalTraceContext(traceContext)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return &deadLetter, nil
+}
+
+// ReplayDeadLetter puts the dead letter back to the outbox with its attempts reset
+func (p *Postgres) ReplayDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	const op = "postgres.ReplayDeadLetter"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query1 := fmt.Sprintf(`INSERT INTO %s (uuid, chat_uuid, seq, topic, message, schema_version, trace_context)
+		SELECT uuid, chat_uuid, seq, topic, message, schema_version, trace_context FROM %s WHERE uuid = $1`,
+		outboxTable, deadLettersTable)
+	query2 := fmt.Sprintf("DELETE FROM %s WHERE uuid = $1", deadLettersTable)
+
+	res, err := tx.Exec(query1, deadLetterUuid)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	if err == nil && affected > 0 {
+		_, err = tx.Exec(query2, deadLetterUuid)
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if affected == 0 {
+		return storage.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	const op = "postgres.DeleteDeadLetter"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE uuid = $1", deadLettersTable)
+	res, err := tx.Exec(query, deadLetterUuid)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if affected == 0 {
+		return storage.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeadLetters deletes all dead letters and returns how many were deleted
+func (p *Postgres) PurgeDeadLetters(ctx context.Context) (int, error) {
+	const op = "postgres.PurgeDeadLetters"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("DELETE FROM %s", deadLettersTable)
+	res, err := tx.Exec(query)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return 0, storage.ErrInternal
+	}
+
+	return int(affected), nil
+}
+
+func (p *Postgres) AddMember(ctx context.Context, member domain.Member) (*domain.Member, error) {
+	const op = "postgres.AddMember"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`INSERT INTO %s (chat_uuid, user_uuid, role, invited_by, joined) VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (chat_uuid, user_uuid) DO UPDATE SET role = $3, invited_by = $4
+		RETURNING joined`, chatMembersTable)
+	err := tx.QueryRow(query, member.ChatUuid, member.UserUuid, member.Role, member.InvitedBy, member.Joined).Scan(&member.Joined)
+	closeTx(err)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return nil, storage.ErrUserNotFound
+	}
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return &member, nil
+}
+
+func (p *Postgres) GetMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error) {
+	const op = "postgres.GetMember"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	member := domain.Member{ChatUuid: chatUuid, UserUuid: userUuid}
+	var invitedBy uuid.NullUUID
+
+	query := fmt.Sprintf("SELECT role, invited_by, joined FROM %s WHERE chat_uuid = $1 AND user_uuid = $2", chatMembersTable)
+	err := tx.QueryRow(query, chatUuid, userUuid).Scan(&member.Role, &invitedBy, &member.Joined)
+	closeTx(err)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrMemberNotFound
+	}
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	member.InvitedBy = invitedBy.UUID
+
+	return &member, nil
+}
+
+func (p *Postgres) RemoveMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) error {
+	const op = "postgres.RemoveMember"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE chat_uuid = $1 AND user_uuid = $2", chatMembersTable)
+	res, err := tx.Exec(query, chatUuid, userUuid)
+	var affected int64
+	if err == nil {
+		affected, err = res.RowsAffected()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if affected == 0 {
+		return storage.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) ListMembers(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Member, error) {
+	const op = "postgres.ListMembers"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf("SELECT user_uuid, role, invited_by, joined FROM %s WHERE chat_uuid = $1 ORDER BY joined", chatMembersTable)
+	rows, err := tx.Query(query, chatUuid)
+	if err != nil {
+		closeTx(err)
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	defer rows.Close()
+
+	var res []*domain.Member
+	for rows.Next() {
+		member := domain.Member{ChatUuid: chatUuid}
+		var invitedBy uuid.NullUUID
+		if err = rows.Scan(&member.UserUuid, &member.Role, &invitedBy, &member.Joined); err != nil {
+			break
+		}
+		member.InvitedBy = invitedBy.UUID
+		res = append(res, &member)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return res, nil
+}
+
+// GetUserQuota returns the quota overrides of the user
+func (p *Postgres) GetUserQuota(ctx context.Context, userUuid uuid.UUID) (*domain.Quota, error) {
+	const op = "postgres.GetUserQuota"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	var quota domain.Quota
+	query := fmt.Sprintf("SELECT chats_owned, messages_per_minute, message_bytes FROM %s WHERE user_uuid = $1", userQuotasTable)
+	err := tx.QueryRow(query, userUuid).Scan(&quota.ChatsOwned, &quota.MessagesPerMinute, &quota.MessageBytes)
+	closeTx(err)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrQuotaNotFound
+	}
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return &quota, nil
+}
+
+// SetUserQuota replaces the quota overrides of the user
+func (p *Postgres) SetUserQuota(ctx context.Context, userUuid uuid.UUID, quota domain.Quota) error {
+	const op = "postgres.SetUserQuota"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_uuid, chats_owned, messages_per_minute, message_bytes) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (user_uuid) DO UPDATE SET chats_owned = $2, messages_per_minute = $3, message_bytes = $4`, userQuotasTable)
+	_, err := tx.Exec(query, userUuid, quota.ChatsOwned, quota.MessagesPerMinute, quota.MessageBytes)
+	closeTx(err)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return storage.ErrUserNotFound
+	}
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return storage.ErrInternal
+	}
+
+	return nil
+}
+
+// QuotaUsage counts unexpired chats owned by the user, their messages posted after since by the log of publication times
+// and the size of all their messages kept in storage
+func (p *Postgres) QuotaUsage(ctx context.Context, userUuid uuid.UUID, since time.Time) (*domain.QuotaUsage, error) {
+	const op = "postgres.QuotaUsage"
+	log := p.log.With(slog.String("op", op))
+
+	tx, closeTx := p.extractTx(ctx)
+
+	var usage domain.QuotaUsage
+	query := fmt.Sprintf(`SELECT
+		(SELECT count(*) FROM %[1]s WHERE owner = $1 AND dead_line > $2),
+		(SELECT count(*) FROM %[3]s WHERE user_uuid = $1 AND published > $3),
+		(SELECT coalesce(sum(octet_length(body)), 0) FROM %[2]s WHERE author_uuid = $1)`, chatsTable, messagesTable, messageTimesTable)
+	err := tx.QueryRow(query, userUuid, time.Now(), since).Scan(&usage.ChatsOwned, &usage.MessagesLastMinute, &usage.MessageBytes)
+	closeTx(err)
+
+	if err != nil {
+		log.Error("error: ", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	return &usage, nil
 }
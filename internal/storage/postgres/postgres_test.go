@@ -9,10 +9,12 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/alexandernizov/grpcmessanger/internal/storage/postgres"
 )
 
@@ -89,7 +91,7 @@ func TestGetUserByUuid(t *testing.T) {
 	assert.NotNil(t, user)
 }
 
-func TestUpsertRefreshToken(t *testing.T) {
+func TestCreateSession(t *testing.T) {
 	t.Parallel()
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -99,20 +101,31 @@ func TestUpsertRefreshToken(t *testing.T) {
 
 	pg := postgres.New(log, db)
 
-	userUuid := uuid.New()
-	refreshToken := "some_refresh_token"
+	now := time.Now()
+	session := domain.Session{
+		Uuid:       uuid.New(),
+		UserUuid:   uuid.New(),
+		RefreshJti: uuid.New(),
+		DeviceName: "laptop",
+		UserAgent:  "curl/8.0",
+		Created:    now,
+		LastUsed:   now,
+		Expires:    now.Add(time.Hour),
+	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT INTO refresh_tokens").WithArgs(userUuid, refreshToken).
+	mock.ExpectExec("INSERT INTO sessions").WithArgs(session.Uuid, session.UserUuid, session.RefreshJti, session.DeviceName,
+		session.UserAgent, session.Created, session.LastUsed, session.Expires).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	ctx := context.Background()
-	err = pg.UpsertRefreshToken(ctx, userUuid, refreshToken)
+	err = pg.CreateSession(ctx, session)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestGetRefreshToken(t *testing.T) {
+func TestRotateSession(t *testing.T) {
 	t.Parallel()
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -122,18 +135,27 @@ func TestGetRefreshToken(t *testing.T) {
 
 	pg := postgres.New(log, db)
 
-	userUuid := uuid.New()
-	expectedToken := "refresh_token"
+	now := time.Now()
+	previousJti := uuid.New()
+	session := domain.Session{Uuid: uuid.New(), RefreshJti: uuid.New(), LastUsed: now, Expires: now.Add(time.Hour)}
 
 	mock.ExpectBegin()
-	mock.ExpectQuery("SELECT token FROM refresh_tokens WHERE user_uuid = ?").WithArgs(userUuid).
-		WillReturnRows(sqlmock.NewRows([]string{"token"}).AddRow(expectedToken))
+	mock.ExpectExec("UPDATE sessions SET refresh_jti").WithArgs(session.Uuid, previousJti, session.RefreshJti, session.LastUsed, session.Expires).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE sessions SET refresh_jti").WithArgs(session.Uuid, previousJti, session.RefreshJti, session.LastUsed, session.Expires).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectCommit()
 
 	ctx := context.Background()
-	token, err := pg.GetRefreshToken(ctx, userUuid)
+	err = pg.RotateSession(ctx, session, previousJti)
 	assert.NoError(t, err)
-	assert.Equal(t, expectedToken, token)
+
+	// The previous token was used already
+	err = pg.RotateSession(ctx, session, previousJti)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestCreateChat(t *testing.T) {
@@ -156,7 +178,12 @@ func TestCreateChat(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO chats").WithArgs(chat.Uuid, chat.Owner.Uuid, chat.Readonly, chat.Deadline).
 		WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO chat_members").WithArgs(chat.Uuid, chat.Owner.Uuid, domain.RoleOwner, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO outbox_chat_seqs").WithArgs(chat.Uuid).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO outbox \\(").WithArgs(sqlmock.AnyArg(), chat.Uuid, 1, domain.ChatTopic, sqlmock.AnyArg(), storage.EventSchemaVersion, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	ctx := context.Background()
@@ -179,20 +206,57 @@ func TestGetChat(t *testing.T) {
 	userUuid := uuid.New()
 
 	mock.ExpectBegin()
-	mock.ExpectQuery("SELECT uuid, owner, read_only, dead_line FROM chats WHERE uuid = ?").WithArgs(chatUuid).
-		WillReturnRows(sqlmock.NewRows([]string{"uuid", "owner", "read_only", "dead_line"}).
-			AddRow(chatUuid, userUuid, false, time.Now()))
+	mock.ExpectQuery("SELECT c.uuid, c.owner, c.read_only, c.dead_line, u.login, u.password FROM chats c JOIN users u").
+		WithArgs(chatUuid, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "owner", "read_only", "dead_line", "login", "password"}).
+			AddRow(chatUuid, userUuid, false, time.Now().Add(time.Hour), "testuser", []byte("hash")))
 	mock.ExpectCommit()
+
+	ctx := context.Background()
+	chat, err := pg.GetChat(ctx, chatUuid)
+	assert.NoError(t, err)
+	require.NotNil(t, chat)
+	assert.Equal(t, "testuser", chat.Owner.Login)
+}
+
+func TestDeleteExpiredChats(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	now := time.Now()
+	firstUuid := uuid.New()
+	secondUuid := uuid.New()
+
 	mock.ExpectBegin()
-	mock.ExpectQuery("SELECT uuid, login, password FROM users WHERE users.uuid = ?").WithArgs(userUuid).
-		WillReturnRows(sqlmock.NewRows([]string{"uuid", "login", "password"}).
-			AddRow(userUuid, "testuser", []byte("hash")))
+	mock.ExpectQuery("DELETE FROM chats WHERE uuid IN").WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "owner", "read_only", "dead_line"}).
+			AddRow(firstUuid, uuid.New(), false, now.Add(-time.Hour)).
+			AddRow(secondUuid, uuid.New(), true, now.Add(-time.Minute)))
+	mock.ExpectQuery("INSERT INTO outbox_chat_seqs").WithArgs(firstUuid).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(4))
+	mock.ExpectExec("INSERT INTO outbox \\(").WithArgs(sqlmock.AnyArg(), firstUuid, 4, domain.ChatTopic, sqlmock.AnyArg(), storage.EventSchemaVersion, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM outbox_chat_seqs").WithArgs(firstUuid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO outbox_chat_seqs").WithArgs(secondUuid).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO outbox \\(").WithArgs(sqlmock.AnyArg(), secondUuid, 2, domain.ChatTopic, sqlmock.AnyArg(), storage.EventSchemaVersion, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM outbox_chat_seqs").WithArgs(secondUuid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
 	ctx := context.Background()
-	chat, err := pg.GetChat(ctx, chatUuid)
+	expired, err := pg.DeleteExpiredChats(ctx, now, 10)
 	assert.NoError(t, err)
-	assert.NotNil(t, chat)
+	assert.Equal(t, []uuid.UUID{firstUuid, secondUuid}, expired)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 // func TestChatsCount(t *testing.T) {
@@ -282,6 +346,71 @@ func TestTrimMessages(t *testing.T) {
 	assert.True(t, result)
 }
 
+func TestClaimOutboxBatch(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	first, second, chatUuid := uuid.New(), uuid.New(), uuid.New()
+
+	// Chats are claimed by their heads, the rest of a chat is taken without skipping
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.uuid, o.chat_uuid FROM outbox o .+ NOT EXISTS .+ ORDER BY o.created_at LIMIT \\$1 FOR UPDATE SKIP LOCKED").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "chat_uuid"}).AddRow(first, chatUuid).AddRow(second, nil))
+	mock.ExpectQuery("SELECT o.uuid, o.chat_uuid, o.seq, o.topic, o.message, o.schema_version, o.trace_context, o.attempts, o.last_error FROM outbox o .+ ORDER BY o.created_at, o.seq LIMIT \\$3 FOR UPDATE$").
+		WithArgs(pq.Array([]string{first.String(), second.String()}), pq.Array([]string{chatUuid.String()}), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "chat_uuid", "seq", "topic", "message", "schema_version", "trace_context", "attempts", "last_error"}).
+			AddRow(first, chatUuid, 1, domain.ChatTopic, []byte("chat"), 1, []byte(`{"traceparent":"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}`), 0, "").
+			AddRow(second, nil, 0, domain.MessageTopic, []byte("message"), 0, nil, 2, "kafka is down"))
+	mock.ExpectExec("UPDATE outbox SET claimed_until").WithArgs(pq.Array([]string{first.String(), second.String()}), int64(30000)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	batch, err := pg.ClaimOutboxBatch(ctx, 2, 30*time.Second)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+	assert.Equal(t, first, batch[0].Uuid)
+	assert.Equal(t, chatUuid, batch[0].ChatUuid)
+	assert.Equal(t, int64(1), batch[0].Seq)
+	assert.Equal(t, storage.EventSchemaVersion, batch[0].SchemaVersion)
+	assert.Equal(t, map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, batch[0].TraceContext)
+	assert.Nil(t, batch[1].TraceContext)
+	// Outboxes created before the chat sequences have no chat
+	assert.Equal(t, uuid.Nil, batch[1].ChatUuid)
+	assert.Equal(t, []byte("message"), batch[1].Message)
+	assert.Equal(t, 2, batch[1].Attempts)
+	assert.Equal(t, "kafka is down", batch[1].LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimOutboxBatchEmpty(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	// Nothing is claimed when the outbox is empty or locked by other publishers
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.uuid, o.chat_uuid FROM outbox").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "chat_uuid"}))
+	mock.ExpectCommit()
+
+	batch, err := pg.ClaimOutboxBatch(context.Background(), 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestConfirmOutboxSended(t *testing.T) {
 	t.Parallel()
 	db, mock, err := sqlmock.New()
@@ -303,3 +432,379 @@ func TestConfirmOutboxSended(t *testing.T) {
 	err = pg.ConfirmOutboxSended(ctx, outboxUuid)
 	assert.NoError(t, err)
 }
+
+func TestDeleteSentOutbox(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM outbox WHERE uuid IN").WithArgs(int64(3_600_000), 100).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	deleted, err := pg.DeleteSentOutbox(ctx, time.Hour, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 7, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxBacklog(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT count(.+) FROM outbox WHERE sent_at IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "oldest_age"}).AddRow(3, "90.5"))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	backlog, err := pg.OutboxBacklog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.OutboxBacklog{Unsent: 3, OldestAge: 90500 * time.Millisecond}, backlog)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFailOutbox(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		attempts         int
+		notFound         bool
+		wantDeadLettered bool
+		wantErr          error
+	}{
+		{name: "attempt recorded", attempts: 1},
+		{name: "dead lettered", attempts: 3, wantDeadLettered: true},
+		{name: "not found", notFound: true, wantErr: storage.ErrOutboxNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			pg := postgres.New(log, db)
+
+			outboxUuid := uuid.New()
+
+			mock.ExpectBegin()
+			rows := sqlmock.NewRows([]string{"attempts"})
+			if !tt.notFound {
+				rows.AddRow(tt.attempts)
+			}
+			mock.ExpectQuery("UPDATE outbox SET attempts = attempts \\+ 1").WithArgs(outboxUuid, "kafka is down").WillReturnRows(rows)
+			if tt.wantDeadLettered {
+				mock.ExpectExec("INSERT INTO outbox_dead_letters").WithArgs(outboxUuid, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM outbox").WithArgs(outboxUuid).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tt.notFound {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			deadLettered, err := pg.FailOutbox(context.Background(), outboxUuid, "kafka is down", 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDeadLettered, deadLettered)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	replayed, missing := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO outbox \\(uuid, chat_uuid, seq, topic, message, schema_version, trace_context\\)").WithArgs(replayed).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM outbox_dead_letters").WithArgs(replayed).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO outbox \\(uuid, chat_uuid, seq, topic, message, schema_version, trace_context\\)").WithArgs(missing).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	assert.NoError(t, pg.ReplayDeadLetter(ctx, replayed))
+	assert.ErrorIs(t, pg.ReplayDeadLetter(ctx, missing), storage.ErrDeadLetterNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChatHistoryPage(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	chatUuid := uuid.New()
+	authorUuid := uuid.New()
+	published := time.Now()
+
+	deleted := time.Now()
+	columns := []string{"id", "author_uuid", "body", "published", "deleted_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, author_uuid, body, published, deleted_at FROM messages (.+) ORDER BY id DESC LIMIT").WithArgs(chatUuid, 5, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(4, authorUuid, "fourth", published, nil).
+			AddRow(3, authorUuid, "", published, deleted))
+	mock.ExpectQuery("SELECT message_id, body, edited FROM message_edits").
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "body", "edited"}).
+			AddRow(4, "4th", published))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, author_uuid, body, published, deleted_at FROM messages (.+) ORDER BY id ASC LIMIT").WithArgs(chatUuid, 1, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, authorUuid, "second", published, nil).
+			AddRow(3, authorUuid, "", published, deleted))
+	mock.ExpectQuery("SELECT message_id, body, edited FROM message_edits").
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "body", "edited"}))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	newest, err := pg.GetChatHistoryPage(ctx, chatUuid, domain.HistoryQuery{Limit: 2, BeforeId: 5})
+	assert.NoError(t, err)
+	if assert.Len(t, newest, 2) {
+		assert.Equal(t, 3, newest[0].Id)
+		assert.True(t, newest[0].Deleted())
+		assert.Equal(t, 4, newest[1].Id)
+		assert.Equal(t, []domain.MessageEdit{{Body: "4th", Edited: published}}, newest[1].Edits)
+	}
+
+	forward, err := pg.GetChatHistoryPage(ctx, chatUuid, domain.HistoryQuery{Limit: 2, AfterId: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, forward, 2) {
+		assert.Equal(t, 2, forward[0].Id)
+		assert.Equal(t, 3, forward[1].Id)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMember(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	member := domain.Member{
+		ChatUuid:  uuid.New(),
+		UserUuid:  uuid.New(),
+		Role:      domain.RoleWriter,
+		InvitedBy: uuid.New(),
+		Joined:    time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO chat_members").WithArgs(member.ChatUuid, member.UserUuid, member.Role, member.InvitedBy, member.Joined).
+		WillReturnRows(sqlmock.NewRows([]string{"joined"}).AddRow(member.Joined))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	added, err := pg.AddMember(ctx, member)
+	assert.NoError(t, err)
+	assert.Equal(t, &member, added)
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	chatUuid := uuid.New()
+	userUuid := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM chat_members").WithArgs(chatUuid, userUuid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	err = pg.RemoveMember(ctx, chatUuid, userUuid)
+	assert.ErrorIs(t, err, storage.ErrMemberNotFound)
+}
+
+func TestEditMessage(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	chatUuid := uuid.New()
+	authorUuid := uuid.New()
+	published := time.Now()
+	edited := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT body FROM messages (.+) FOR UPDATE").WithArgs(chatUuid, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"body"}).AddRow("old"))
+	mock.ExpectExec("INSERT INTO message_edits").WithArgs(7, "old", edited).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE messages SET body").WithArgs("new", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, author_uuid, body, published, deleted_at FROM messages").WithArgs(chatUuid, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_uuid", "body", "published", "deleted_at"}).
+			AddRow(7, authorUuid, "new", published, nil))
+	mock.ExpectQuery("SELECT message_id, body, edited FROM message_edits").
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "body", "edited"}).AddRow(7, "old", edited))
+	mock.ExpectQuery("INSERT INTO outbox_chat_seqs").WithArgs(chatUuid).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO outbox \\(").WithArgs(sqlmock.AnyArg(), chatUuid, 3, domain.MessageTopic, sqlmock.AnyArg(), storage.EventSchemaVersion, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	message, err := pg.EditMessage(context.Background(), chatUuid, authorUuid, 7, "new", edited)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", message.Body)
+	assert.Equal(t, []domain.MessageEdit{{Body: "old", Edited: edited}}, message.Edits)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT body FROM messages (.+) FOR UPDATE").WithArgs(chatUuid, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"body"}))
+	mock.ExpectRollback()
+
+	_, err = pg.EditMessage(context.Background(), chatUuid, authorUuid, 8, "new", edited)
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessage(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	chatUuid := uuid.New()
+	authorUuid := uuid.New()
+	published := time.Now()
+	deleted := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE messages SET body = '', deleted_at").WithArgs(chatUuid, 7, deleted).
+		WillReturnRows(sqlmock.NewRows([]string{"author_uuid", "published"}).AddRow(authorUuid, published))
+	mock.ExpectExec("DELETE FROM message_edits").WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("INSERT INTO outbox_chat_seqs").WithArgs(chatUuid).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO outbox \\(").WithArgs(sqlmock.AnyArg(), chatUuid, 3, domain.MessageTopic, sqlmock.AnyArg(), storage.EventSchemaVersion, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	message, err := pg.DeleteMessage(context.Background(), chatUuid, authorUuid, 7, deleted)
+	assert.NoError(t, err)
+	assert.True(t, message.Deleted())
+	assert.Empty(t, message.Body)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE messages SET body = '', deleted_at").WithArgs(chatUuid, 7, deleted).
+		WillReturnRows(sqlmock.NewRows([]string{"author_uuid", "published"}))
+	mock.ExpectRollback()
+
+	_, err = pg.DeleteMessage(context.Background(), chatUuid, authorUuid, 7, deleted)
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserQuota(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	userUuid := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT chats_owned, messages_per_minute, message_bytes FROM user_quotas").WithArgs(userUuid).
+		WillReturnRows(sqlmock.NewRows([]string{"chats_owned", "messages_per_minute", "message_bytes"}).AddRow(10, 0, -1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT chats_owned, messages_per_minute, message_bytes FROM user_quotas").WithArgs(userUuid).
+		WillReturnRows(sqlmock.NewRows([]string{"chats_owned", "messages_per_minute", "message_bytes"}))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	quota, err := pg.GetUserQuota(ctx, userUuid)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Quota{ChatsOwned: 10, MessageBytes: -1}, quota)
+
+	_, err = pg.GetUserQuota(ctx, userUuid)
+	assert.ErrorIs(t, err, storage.ErrQuotaNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuotaUsage(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pg := postgres.New(log, db)
+
+	userUuid := uuid.New()
+	since := time.Now().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs(userUuid, sqlmock.AnyArg(), since).
+		WillReturnRows(sqlmock.NewRows([]string{"chats", "messages", "bytes"}).AddRow(2, 3, 42))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	usage, err := pg.QuotaUsage(ctx, userUuid, since)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.QuotaUsage{ChatsOwned: 2, MessagesLastMinute: 3, MessageBytes: 42}, usage)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,28 @@
+package redis_test
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexandernizov/grpcmessanger/internal/storage/redis"
+	"github.com/alexandernizov/grpcmessanger/internal/storage/storagetest"
+)
+
+// TestConformance needs a redis which isn't used by anything else, e.g. REDIS_TEST_ADDR=localhost:6379
+func TestConformance(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return db
+	})
+}
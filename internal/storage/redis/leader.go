@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+)
+
+// leaderKey holds the id of the leader replica until its lease is over
+const (
+	leaderKey          = "leader"
+	defaultLeaderLease = 15 * time.Second
+)
+
+// renewLeaderScript extends the lease of KEYS[1] for ARGV[2] milliseconds if it's still held by ARGV[1]
+var renewLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaderScript deletes KEYS[1] if it's still held by ARGV[1]
+var releaseLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// LeaderLock is a key with a lease, the leader has to renew it before the lease is over.
+// A dead leader is replaced when its lease is over.
+type LeaderLock struct {
+	log   *slog.Logger
+	db    *redis.Client
+	id    string
+	lease time.Duration
+}
+
+// LeaderLock returns the lock replicas compete for to become the leader,
+// the lease should be several times longer than the lock is renewed
+func (r *Redis) LeaderLock(id string, lease time.Duration) *LeaderLock {
+	if lease <= 0 {
+		lease = defaultLeaderLease
+	}
+	return &LeaderLock{log: r.log, db: r.db, id: id, lease: lease}
+}
+
+func (l *LeaderLock) Acquire(ctx context.Context) (bool, error) {
+	op := "redis.AcquireLeaderLock"
+	log := l.log.With(slog.String("op", op))
+
+	acquired, err := l.db.SetNX(ctx, leaderKey, l.id, l.lease).Result()
+	if err != nil {
+		log.Error("SETNX leader error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	if !acquired {
+		// The lease of this replica may still be going on after a restart of the elector
+		return l.Renew(ctx)
+	}
+	return true, nil
+}
+
+func (l *LeaderLock) Renew(ctx context.Context) (bool, error) {
+	op := "redis.RenewLeaderLock"
+	log := l.log.With(slog.String("op", op))
+
+	renewed, err := renewLeaderScript.Run(ctx, l.db, []string{leaderKey}, l.id, l.lease.Milliseconds()).Int()
+	if err != nil {
+		log.Error("PEXPIRE leader error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	return renewed == 1, nil
+}
+
+func (l *LeaderLock) Release(ctx context.Context) error {
+	op := "redis.ReleaseLeaderLock"
+	log := l.log.With(slog.String("op", op))
+
+	if err := releaseLeaderScript.Run(ctx, l.db, []string{leaderKey}, l.id).Err(); err != nil {
+		log.Error("DEL leader error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
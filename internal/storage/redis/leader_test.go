@@ -0,0 +1,63 @@
+package redis_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexandernizov/grpcmessanger/internal/storage/redis"
+)
+
+// TestLeaderLock needs a redis which isn't used by anything else, e.g. REDIS_TEST_ADDR=localhost:6379
+func TestLeaderLock(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	first := db.LeaderLock("first", 100*time.Millisecond)
+	second := db.LeaderLock("second", 100*time.Millisecond)
+	require.NoError(t, first.Release(ctx))
+	require.NoError(t, second.Release(ctx))
+
+	acquired, err := first.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	acquired, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Only the holder renews and releases the lock
+	held, err := second.Renew(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+	require.NoError(t, second.Release(ctx))
+	held, err = first.Renew(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// The lock is free after its holder stops renewing it
+	time.Sleep(150 * time.Millisecond)
+	acquired, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	held, err = first.Renew(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, second.Release(ctx))
+	acquired, err = first.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, first.Release(ctx))
+}
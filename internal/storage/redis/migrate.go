@@ -0,0 +1,281 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/alexandernizov/grpcmessanger/internal/pkg/logger/sl"
+)
+
+// legacyMessagesKey is the list of the messages of a chat, newest first.
+// Messages were kept there without ids before they were moved to the sorted sets of messagesKey.
+const legacyMessagesKey = "messages:"
+
+// legacyUserMessageBytes was the size of all the messages of a user,
+// it wasn't decreased when chats expired, so it's replaced by the sizes by chat of userChatBytes
+const legacyUserMessageBytes = "userMessageBytes:"
+
+// maxMigrateAttempts is how many times a chat is migrated while it's changed concurrently
+const maxMigrateAttempts = 10
+
+// migrateMessageLists moves the messages of the legacy lists to the sorted sets and numbers them oldest first,
+// after the messages which are in the sorted set already.
+// Lists of the chats which don't exist anymore are dropped. Every list is moved in one transaction,
+// so replicas which start together don't migrate a chat twice.
+func (r *Redis) migrateMessageLists(ctx context.Context) error {
+	op := "redis.migrateMessageLists"
+	log := r.log.With(slog.String("op", op))
+
+	iter := r.db.Scan(ctx, 0, legacyMessagesKey+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		chatUuid := strings.TrimPrefix(iter.Val(), legacyMessagesKey)
+		if err := r.migrateMessageList(ctx, chatUuid); err != nil {
+			log.Error("can't migrate messages of the chat", slog.String("chat_uuid", chatUuid), sl.Err(err))
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (r *Redis) migrateMessageList(ctx context.Context, chatUuid string) error {
+	op := "redis.migrateMessageList"
+	log := r.log.With(slog.String("op", op), slog.String("chat_uuid", chatUuid))
+
+	legacyKey := legacyMessagesKey + chatUuid
+	idKey := messageIdKey + chatUuid
+	for attempt := 0; attempt < maxMigrateAttempts; attempt++ {
+		migrated := 0
+		err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+			messagesJson, err := tx.LRange(ctx, legacyKey, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			ttl, err := tx.PTTL(ctx, chatKey+chatUuid).Result()
+			if err != nil {
+				return err
+			}
+			if len(messagesJson) == 0 || ttl <= 0 {
+				_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Del(ctx, legacyKey)
+					return nil
+				})
+				return err
+			}
+			lastId, err := tx.Get(ctx, idKey).Int()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+
+			members := make([]redis.Z, 0, len(messagesJson))
+			authorBytes := make(map[string]int64)
+			for i := len(messagesJson) - 1; i >= 0; i-- {
+				var message Message
+				if err := json.Unmarshal([]byte(messagesJson[i]), &message); err != nil {
+					return fmt.Errorf("unmarshall error: %w", err)
+				}
+				lastId++
+				message.Id = lastId
+				jsonMessage, err := json.Marshal(message)
+				if err != nil {
+					return fmt.Errorf("marshalling error: %w", err)
+				}
+				members = append(members, redis.Z{Score: float64(message.Id), Member: jsonMessage})
+				authorBytes[message.AuthorUuid.String()] += int64(len(message.Body))
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.ZAdd(ctx, messagesKey+chatUuid, members...)
+				pipe.PExpire(ctx, messagesKey+chatUuid, ttl)
+				pipe.Set(ctx, idKey, lastId, ttl)
+				for author, bytes := range authorBytes {
+					pipe.HIncrBy(ctx, userChatBytes+author, chatUuid, bytes)
+				}
+				pipe.Del(ctx, legacyKey)
+				return nil
+			})
+			migrated = len(members)
+			return err
+		}, legacyKey, idKey)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if migrated == 0 {
+			log.Info("legacy messages of a removed chat are dropped")
+			return nil
+		}
+		log.Info("legacy messages are migrated", slog.Int("messages", migrated))
+		return nil
+	}
+	return fmt.Errorf("chat is changed concurrently, gave up after %d attempts", maxMigrateAttempts)
+}
+
+// migrateMessageBytes replaces the legacy sizes of messages by user with the sizes by chat.
+// They are counted again from the messages kept in chats, messages of expired chats are dropped
+// and the rest get the ttl of their chat.
+func (r *Redis) migrateMessageBytes(ctx context.Context) error {
+	op := "redis.migrateMessageBytes"
+	log := r.log.With(slog.String("op", op))
+
+	legacyKeys, err := r.scanKeys(ctx, legacyUserMessageBytes+"*")
+	if err != nil {
+		return err
+	}
+	if len(legacyKeys) == 0 {
+		return nil
+	}
+
+	idKeys, err := r.scanKeys(ctx, messageIdKey+"*")
+	if err != nil {
+		return err
+	}
+	for _, idKey := range idKeys {
+		chatUuid := strings.TrimPrefix(idKey, messageIdKey)
+		if err := r.migrateChatBytes(ctx, chatUuid); err != nil {
+			log.Error("can't count message bytes of the chat", slog.String("chat_uuid", chatUuid), sl.Err(err))
+			return err
+		}
+	}
+
+	if err := r.db.Del(ctx, legacyKeys...).Err(); err != nil {
+		return err
+	}
+	log.Info("message bytes are counted by chat", slog.Int("chats", len(idKeys)))
+	return nil
+}
+
+func (r *Redis) migrateChatBytes(ctx context.Context, chatUuid string) error {
+	idKey := messageIdKey + chatUuid
+	for attempt := 0; attempt < maxMigrateAttempts; attempt++ {
+		// Every change of the sizes goes together with a change of the messages
+		err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+			ttl, err := tx.PTTL(ctx, chatKey+chatUuid).Result()
+			if err != nil {
+				return err
+			}
+			if ttl <= 0 {
+				_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.Del(ctx, messagesKey+chatUuid, idKey)
+					return nil
+				})
+				return err
+			}
+
+			messagesJson, err := tx.ZRange(ctx, messagesKey+chatUuid, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			authorBytes := make(map[string]int64)
+			for _, v := range messagesJson {
+				var message Message
+				if err := json.Unmarshal([]byte(v), &message); err != nil {
+					return fmt.Errorf("unmarshall error: %w", err)
+				}
+				authorBytes[message.AuthorUuid.String()] += int64(len(message.Body))
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				for author, bytes := range authorBytes {
+					pipe.HSet(ctx, userChatBytes+author, chatUuid, bytes)
+				}
+				pipe.PExpire(ctx, messagesKey+chatUuid, ttl)
+				pipe.PExpire(ctx, idKey, ttl)
+				return nil
+			})
+			return err
+		}, messagesKey+chatUuid)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("chat is changed concurrently, gave up after %d attempts", maxMigrateAttempts)
+}
+
+func (r *Redis) scanKeys(ctx context.Context, match string) ([]string, error) {
+	var keys []string
+	iter := r.db.Scan(ctx, 0, match, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// migrateOutboxChats records the chats of the outboxes which were added before the chats were kept by outbox,
+// so their chats are claimed by one publisher at a time as well
+func (r *Redis) migrateOutboxChats(ctx context.Context) error {
+	op := "redis.migrateOutboxChats"
+	log := r.log.With(slog.String("op", op))
+
+	for attempt := 0; attempt < maxMigrateAttempts; attempt++ {
+		migrated := 0
+		err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+			exists, err := tx.Exists(ctx, outboxChats, outboxBusyChats).Result()
+			if err != nil || exists > 0 {
+				return err
+			}
+			waiting, err := tx.LRange(ctx, outboxList, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			processing, err := tx.LRange(ctx, outboxProcessing, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			if len(waiting)+len(processing) == 0 {
+				return nil
+			}
+
+			chats := make(map[string]string, len(waiting)+len(processing))
+			busy := make(map[string]int64)
+			for i, outboxUuid := range append(waiting, processing...) {
+				chatUuid, err := tx.HGet(ctx, outboxMessage+outboxUuid, "chat_uuid").Result()
+				if errors.Is(err, redis.Nil) || chatUuid == "" {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				chats[outboxUuid] = chatUuid
+				if i >= len(waiting) {
+					busy[chatUuid]++
+				}
+			}
+			if len(chats) == 0 {
+				return nil
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, outboxChats, chats)
+				for chatUuid, claimed := range busy {
+					pipe.HSet(ctx, outboxBusyChats, chatUuid, claimed)
+				}
+				return nil
+			})
+			migrated = len(chats)
+			return err
+		}, outboxList, outboxProcessing, outboxChats, outboxBusyChats)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if migrated > 0 {
+			log.Info("chats of the outboxes are recorded", slog.Int("outboxes", migrated))
+		}
+		return nil
+	}
+	return fmt.Errorf("outbox is changed concurrently, gave up after %d attempts", maxMigrateAttempts)
+}
@@ -0,0 +1,152 @@
+package redis_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/storage/redis"
+)
+
+// TestMigrateMessageLists needs a redis which isn't used by anything else, e.g. REDIS_TEST_ADDR=localhost:6379
+func TestMigrateMessageLists(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+	client := goredis.NewClient(&goredis.Options{Addr: addr})
+	defer client.Close()
+	ctx := context.Background()
+
+	author, err := db.CreateUser(ctx, domain.User{Uuid: uuid.New(), Login: uuid.NewString(), PasswordHash: []byte("hash")})
+	require.NoError(t, err)
+	chat, err := db.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *author, Deadline: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	// Legacy lists were pushed to the head and had no message ids
+	legacy := func(body string) string {
+		message, err := json.Marshal(map[string]any{"uuid": uuid.New(), "authorUuid": author.Uuid, "body": body, "published": time.Now()})
+		require.NoError(t, err)
+		return string(message)
+	}
+	require.NoError(t, client.LPush(ctx, "messages:"+chat.Uuid.String(), legacy("first"), legacy("second")).Err())
+	expiredChat := uuid.NewString()
+	require.NoError(t, client.LPush(ctx, "messages:"+expiredChat, legacy("expired")).Err())
+
+	_, err = redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+
+	history, err := db.GetChatHistoryPage(ctx, chat.Uuid, domain.HistoryQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, []int{1, 2}, []int{history[0].Id, history[1].Id})
+	assert.Equal(t, []string{"first", "second"}, []string{history[0].Body, history[1].Body})
+
+	posted, err := db.PostMessage(ctx, chat.Uuid, domain.Message{AuthorUuid: author.Uuid, Body: "third", Published: time.Now()})
+	require.NoError(t, err)
+	assert.Equal(t, 3, posted.Id)
+
+	usage, err := db.QuotaUsage(ctx, author.Uuid, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("first")+len("second")+len("third")), usage.MessageBytes)
+
+	legacyLists, err := client.Exists(ctx, "messages:"+chat.Uuid.String(), "messages:"+expiredChat).Result()
+	require.NoError(t, err)
+	assert.Zero(t, legacyLists)
+}
+
+// TestMigrateMessageBytes needs a redis which isn't used by anything else, e.g. REDIS_TEST_ADDR=localhost:6379
+func TestMigrateMessageBytes(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+	client := goredis.NewClient(&goredis.Options{Addr: addr})
+	defer client.Close()
+	ctx := context.Background()
+
+	author, err := db.CreateUser(ctx, domain.User{Uuid: uuid.New(), Login: uuid.NewString(), PasswordHash: []byte("hash")})
+	require.NoError(t, err)
+	chat, err := db.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *author, Deadline: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = db.PostMessage(ctx, chat.Uuid, domain.Message{AuthorUuid: author.Uuid, Body: "hello", Published: time.Now()})
+	require.NoError(t, err)
+
+	// Legacy sizes counted the messages of expired chats too, and their messages never expired
+	require.NoError(t, client.Del(ctx, "userChatBytes:"+author.Uuid.String()).Err())
+	require.NoError(t, client.Persist(ctx, "chatMessages:"+chat.Uuid.String()).Err())
+	require.NoError(t, client.Set(ctx, "userMessageBytes:"+author.Uuid.String(), 100, 0).Err())
+	expiredChat := uuid.NewString()
+	require.NoError(t, client.ZAdd(ctx, "chatMessages:"+expiredChat, goredis.Z{Score: 1, Member: "expired"}).Err())
+	require.NoError(t, client.Set(ctx, "messageId:"+expiredChat, 1, 0).Err())
+
+	_, err = redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+
+	usage, err := db.QuotaUsage(ctx, author.Uuid, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("hello")), usage.MessageBytes)
+
+	ttl, err := client.PTTL(ctx, "chatMessages:"+chat.Uuid.String()).Result()
+	require.NoError(t, err)
+	assert.Positive(t, ttl)
+
+	left, err := client.Exists(ctx, "userMessageBytes:"+author.Uuid.String(), "chatMessages:"+expiredChat, "messageId:"+expiredChat).Result()
+	require.NoError(t, err)
+	assert.Zero(t, left)
+}
+
+// TestMigrateOutboxChats needs a redis which isn't used by anything else, e.g. REDIS_TEST_ADDR=localhost:6379
+func TestMigrateOutboxChats(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+	client := goredis.NewClient(&goredis.Options{Addr: addr})
+	defer client.Close()
+	ctx := context.Background()
+
+	author, err := db.CreateUser(ctx, domain.User{Uuid: uuid.New(), Login: uuid.NewString(), PasswordHash: []byte("hash")})
+	require.NoError(t, err)
+	chat, err := db.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *author, Deadline: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = db.PostMessage(ctx, chat.Uuid, domain.Message{AuthorUuid: author.Uuid, Body: "hello", Published: time.Now()})
+	require.NoError(t, err)
+
+	// Legacy outboxes didn't record their chats
+	require.NoError(t, client.Del(ctx, "outboxChats:", "outboxBusyChats:").Err())
+
+	_, err = redis.New(log, redis.ConnectOptions{Addr: addr})
+	require.NoError(t, err)
+
+	chats, err := client.HVals(ctx, "outboxChats:").Result()
+	require.NoError(t, err)
+	recorded := 0
+	for _, v := range chats {
+		if v == chat.Uuid.String() {
+			recorded++
+		}
+	}
+	assert.Equal(t, 2, recorded)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/alexandernizov/grpcmessanger/api/gen/outbox"
@@ -14,7 +16,6 @@ import (
 	"github.com/alexandernizov/grpcmessanger/internal/storage"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
-	"google.golang.org/protobuf/proto"
 )
 
 type Redis struct {
@@ -30,14 +31,136 @@ type ConnectOptions struct {
 
 const (
 	chatKey        = "chat:"
-	messagesKey    = "messages:"
+	messagesKey    = "chatMessages:"
+	messageIdKey   = "messageId:"
 	usersKey       = "users:"
 	userLoginIndex = "userLoginIndex:"
-	refreshTokens  = "refreshToken:"
+	sessionKey     = "session:"
+	userSessions   = "userSessions:"
 	outboxList     = "outboxList:"
 	outboxMessage  = "outboxMessage:"
+	// The last outbox sequence number of a chat
+	outboxSeqKey = "outboxSeq:"
+	// Claimed outboxes are moved to the processing list, their lease deadlines are scored by unix milli
+	outboxProcessing = "outboxProcessing:"
+	outboxClaims     = "outboxClaims:"
+	// The chats of unsent outboxes and how many outboxes of a chat are claimed
+	outboxChats     = "outboxChats:"
+	outboxBusyChats = "outboxBusyChats:"
+	// Dead letters are scored by the unix nano time they failed
+	deadLetterList = "deadLetters:"
+	deadLetterKey  = "deadLetter:"
+	chatMembersKey = "chatMembers:"
+	revokedToken   = "revokedToken:"
+	userQuotaKey   = "userQuota:"
+	userChats      = "userChats:"
+	// Publication times of the recent messages of a user, scored by unix nano
+	userMessageTimes = "userMessageTimes:"
+	// Sizes of the messages of a user by chat, chats which have expired don't count and are dropped lazily
+	userChatBytes = "userChatBytes:"
 )
 
+// unclaimLua drops a claim of an outbox from the count of its chat
+const unclaimLua = `
+local function unclaim(outboxUuid)
+	local chatUuid = redis.call('HGET', KEYS[4], outboxUuid)
+	if chatUuid and chatUuid ~= '' and redis.call('HINCRBY', KEYS[5], chatUuid, -1) <= 0 then
+		redis.call('HDEL', KEYS[5], chatUuid)
+	end
+end
+`
+
+// claimScript returns outboxes with expired leases to the head of the outbox
+// and moves up to ARGV[3] oldest outboxes to the processing list until ARGV[2].
+// Outboxes of chats with claimed outboxes are skipped, so a chat is published by one publisher at a time,
+// only the first ARGV[3] * 10 outboxes are looked through.
+var claimScript = redis.NewScript(unclaimLua + `
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1])
+for i = #expired, 1, -1 do
+	if redis.call('LREM', KEYS[2], 1, expired[i]) > 0 then
+		redis.call('LPUSH', KEYS[1], expired[i])
+		unclaim(expired[i])
+	end
+	redis.call('ZREM', KEYS[3], expired[i])
+end
+local limit = tonumber(ARGV[3])
+local candidates = redis.call('LRANGE', KEYS[1], 0, limit * 10 - 1)
+if #candidates == 0 then
+	return {}
+end
+local chats = redis.call('HMGET', KEYS[4], unpack(candidates))
+local busy = {}
+local claimed = {}
+for i, outboxUuid in ipairs(candidates) do
+	if #claimed >= limit then
+		break
+	end
+	local chatUuid = chats[i]
+	if chatUuid and chatUuid ~= '' then
+		if busy[chatUuid] == nil then
+			busy[chatUuid] = redis.call('HEXISTS', KEYS[5], chatUuid) == 1
+		end
+		if not busy[chatUuid] then
+			table.insert(claimed, outboxUuid)
+			redis.call('HINCRBY', KEYS[5], chatUuid, 1)
+		end
+	else
+		table.insert(claimed, outboxUuid)
+	end
+end
+for _, outboxUuid in ipairs(claimed) do
+	redis.call('LREM', KEYS[1], 1, outboxUuid)
+	redis.call('RPUSH', KEYS[2], outboxUuid)
+	redis.call('ZADD', KEYS[3], ARGV[2], outboxUuid)
+end
+return claimed
+`)
+
+// releaseScript returns the claimed outboxes in ARGV to the head of the outbox keeping their order.
+// Outboxes which aren't claimed anymore are left where they are.
+var releaseScript = redis.NewScript(unclaimLua + `
+for i = #ARGV, 1, -1 do
+	if redis.call('LREM', KEYS[2], 1, ARGV[i]) > 0 then
+		redis.call('LPUSH', KEYS[1], ARGV[i])
+		unclaim(ARGV[i])
+	end
+	redis.call('ZREM', KEYS[3], ARGV[i])
+end
+return #ARGV
+`)
+
+// removeScript takes the outbox in ARGV[1] out of the outbox, whether it's claimed or not
+var removeScript = redis.NewScript(unclaimLua + `
+if redis.call('LREM', KEYS[2], 1, ARGV[1]) > 0 then
+	unclaim(ARGV[1])
+end
+redis.call('LREM', KEYS[1], 1, ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+return 1
+`)
+
+var outboxKeys = []string{outboxList, outboxProcessing, outboxClaims, outboxChats, outboxBusyChats}
+
+// pushScript numbers the outbox message with the next sequence number of its chat and adds it to the end of the outbox.
+// It's evaluated in the transaction of the change, so events of a chat are numbered in the order they are added.
+var pushScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[2])
+redis.call('HSET', KEYS[3], 'topic', ARGV[3], 'message', ARGV[4], 'chat_uuid', ARGV[2], 'seq', seq, 'schema_version', ARGV[5], 'created', ARGV[6], 'trace_context', ARGV[7])
+redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('HSET', KEYS[4], ARGV[1], ARGV[2])
+return seq
+`)
+
+// pushOutbox adds the outbox message of the chat to the outbox as a part of the pipeline
+func pushOutbox(ctx context.Context, pipe redis.Pipeliner, chatUuid string, outboxUuid string, forSending OutboxMessage) {
+	keys := []string{outboxList, outboxSeqKey + chatUuid, outboxMessage + outboxUuid, outboxChats}
+	pushScript.Eval(ctx, pipe, keys, outboxUuid, chatUuid, forSending.Topic, forSending.Message, storage.EventSchemaVersion, time.Now().UnixMilli(), storage.OutboxTraceContext(ctx))
+}
+
+// messageTimesWindow is how long publication times are kept for the messages per minute quota
+const messageTimesWindow = time.Hour
+
 func New(log *slog.Logger, opt ConnectOptions) (*Redis, error) {
 	db := redis.NewClient(&redis.Options{Addr: opt.Addr, Password: opt.Password, DB: opt.DB})
 
@@ -45,7 +168,19 @@ func New(log *slog.Logger, opt ConnectOptions) (*Redis, error) {
 	if err != nil {
 		return nil, fmt.Errorf("can't ping Redis DB: %w", storage.ErrNoConnection)
 	}
-	return &Redis{log: log, db: db}, nil
+
+	r := &Redis{log: log, db: db}
+	// Chat history used to be kept in lists, it's moved before the messages are served
+	if err := r.migrateMessageLists(context.Background()); err != nil {
+		return nil, fmt.Errorf("can't migrate legacy messages: %w", storage.ErrInternal)
+	}
+	if err := r.migrateMessageBytes(context.Background()); err != nil {
+		return nil, fmt.Errorf("can't migrate message bytes: %w", storage.ErrInternal)
+	}
+	if err := r.migrateOutboxChats(context.Background()); err != nil {
+		return nil, fmt.Errorf("can't migrate outbox chats: %w", storage.ErrInternal)
+	}
+	return r, nil
 }
 
 type User struct {
@@ -62,15 +197,87 @@ type Chat struct {
 }
 
 type Message struct {
+	Id         int       `json:"id"`
 	Uuid       uuid.UUID `json:"uuid"`
 	AuthorUuid uuid.UUID `json:"authorUuid"`
 	Body       string    `json:"body"`
 	Published  time.Time `json:"published"`
+	Edits      []Edit    `json:"edits,omitempty"`
+	DeletedAt  time.Time `json:"deletedAt"`
+}
+
+type Edit struct {
+	Body   string    `json:"body"`
+	Edited time.Time `json:"edited"`
+}
+
+type Member struct {
+	UserUuid  uuid.UUID   `json:"userUuid"`
+	Role      domain.Role `json:"role"`
+	InvitedBy uuid.UUID   `json:"invitedBy"`
+	Joined    time.Time   `json:"joined"`
+}
+
+type Session struct {
+	Uuid       uuid.UUID `json:"uuid"`
+	UserUuid   uuid.UUID `json:"userUuid"`
+	RefreshJti uuid.UUID `json:"refreshJti"`
+	DeviceName string    `json:"deviceName"`
+	UserAgent  string    `json:"userAgent"`
+	Created    time.Time `json:"created"`
+	LastUsed   time.Time `json:"lastUsed"`
+	Expires    time.Time `json:"expires"`
 }
 
 type OutboxMessage struct {
-	Topic   string `redis:"topic"`
-	Message []byte `redis:"message"`
+	Topic    string `redis:"topic"`
+	Message  []byte `redis:"message"`
+	ChatUuid string `redis:"chat_uuid"`
+	Seq      int64  `redis:"seq"`
+	// SchemaVersion is missing for events older than the versioned envelope
+	SchemaVersion int    `redis:"schema_version"`
+	Attempts      int    `redis:"attempts"`
+	LastError     string `redis:"last_error"`
+	// Created is in unix milli, it's missing for outboxes added before the backlog was measured
+	Created int64 `redis:"created"`
+	// TraceContext is marshalled by storage.MarshalTraceContext, it's empty without a trace
+	TraceContext []byte `redis:"trace_context"`
+}
+
+type DeadLetter struct {
+	Topic         string `redis:"topic"`
+	Message       []byte `redis:"message"`
+	ChatUuid      string `redis:"chat_uuid"`
+	Seq           int64  `redis:"seq"`
+	SchemaVersion int    `redis:"schema_version"`
+	TraceContext  []byte `redis:"trace_context"`
+	Attempts      int    `redis:"attempts"`
+	LastError     string `redis:"last_error"`
+	Failed        int64  `redis:"failed"`
+}
+
+// parseChatUuid returns uuid.Nil for outboxes added before they had chats
+func parseChatUuid(chatUuid string) uuid.UUID {
+	res, err := uuid.Parse(chatUuid)
+	if err != nil {
+		return uuid.Nil
+	}
+	return res
+}
+
+func (d *DeadLetter) toDomain(deadLetterUuid uuid.UUID) *domain.DeadLetter {
+	return &domain.DeadLetter{
+		Uuid:          deadLetterUuid,
+		Topic:         d.Topic,
+		ChatUuid:      parseChatUuid(d.ChatUuid),
+		Seq:           d.Seq,
+		SchemaVersion: d.SchemaVersion,
+		TraceContext:  storage.UnmarshalTraceContext(d.TraceContext),
+		Message:       d.Message,
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		Failed:        time.Unix(0, d.Failed),
+	}
 }
 
 func (r *Redis) ChatsCount(ctx context.Context) (int, error) {
@@ -96,14 +303,8 @@ func (r *Redis) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat,
 		Ttl:      time.Duration(time.Until(chat.Deadline)),
 	}
 
-	outboxChat := outbox.OutboxChat{
-		Uuid:      chat.Uuid.String(),
-		OwnerUuid: chat.Owner.Uuid.String(),
-		Readonly:  chat.Readonly,
-		Deadline:  chat.Deadline.String(),
-	}
-
-	marshalledMessage, err := proto.Marshal(&outboxChat)
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalChatEvent(eventUuid, outbox.EventType_CHAT_CREATED, &chat, time.Now())
 	if err != nil {
 		return &domain.Chat{}, storage.ErrInternal
 	}
@@ -113,11 +314,20 @@ func (r *Redis) CreateChat(ctx context.Context, chat domain.Chat) (*domain.Chat,
 		Message: marshalledMessage,
 	}
 
+	owner, err := json.Marshal(Member{UserUuid: chat.Owner.Uuid, Role: domain.RoleOwner, Joined: time.Now()})
+	if err != nil {
+		log.Error("marshalling error", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
 	pipe := r.db.TxPipeline()
 	pipe.HSet(ctx, chatKey+redisChat.Uuid, redisChat)
 	pipe.Expire(ctx, chatKey+redisChat.Uuid, redisChat.Ttl)
-	pipe.RPush(ctx, outboxList, redisChat.Uuid)
-	pipe.HSet(ctx, outboxMessage+redisChat.Uuid, forSending)
+	pipe.HSet(ctx, chatMembersKey+redisChat.Uuid, redisChat.Owner, owner)
+	pipe.Expire(ctx, chatMembersKey+redisChat.Uuid, redisChat.Ttl)
+	pipe.SAdd(ctx, userChats+redisChat.Owner, redisChat.Uuid)
+	pushOutbox(ctx, pipe, redisChat.Uuid, eventUuid.String(), forSending)
+	pipe.Expire(ctx, outboxSeqKey+redisChat.Uuid, redisChat.Ttl)
 	_, err = pipe.Exec(ctx)
 
 	if err != nil {
@@ -133,9 +343,15 @@ func (r *Redis) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat,
 	log := r.log.With(slog.String("op", op))
 
 	var chat Chat
-	err := r.db.HGetAll(ctx, chatKey+chatUuid.String()).Scan(&chat)
+	pipe := r.db.Pipeline()
+	fields := pipe.HGetAll(ctx, chatKey+chatUuid.String())
+	ttl := pipe.PTTL(ctx, chatKey+chatUuid.String())
+	_, err := pipe.Exec(ctx)
+	if err == nil {
+		err = fields.Scan(&chat)
+	}
 	if err != nil {
-		log.Error("HGET readonly error", sl.Err(err))
+		log.Error("HGETALL chat error", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
 
@@ -143,11 +359,17 @@ func (r *Redis) GetChat(ctx context.Context, chatUuid uuid.UUID) (*domain.Chat,
 	if err != nil {
 		return nil, storage.ErrChatNotFound
 	}
+	owner, err := r.GetUserByUuid(ctx, ownerUuid)
+	if err != nil {
+		return nil, storage.ErrChatNotFound
+	}
 
+	// Chats expire by ttl, the deadline is what is left of it
 	result := domain.Chat{
 		Uuid:     chatUuid,
-		Owner:    domain.User{Uuid: ownerUuid},
+		Owner:    *owner,
 		Readonly: chat.Readonly,
+		Deadline: time.Now().Add(ttl.Val()),
 	}
 	return &result, nil
 }
@@ -158,21 +380,36 @@ func (r *Redis) PostMessage(ctx context.Context, chat uuid.UUID, message domain.
 
 	mUuid := uuid.New()
 
-	redisMessage := Message{Uuid: mUuid, AuthorUuid: message.AuthorUuid, Body: message.Body, Published: message.Published}
-	jsonMessage, err := json.Marshal(redisMessage)
+	// Messages live as long as the chat itself
+	ttl, err := r.db.PTTL(ctx, chatKey+chat.String()).Result()
 	if err != nil {
-		log.Error("marshalling error", sl.Err(err))
+		log.Error("PTTL chat error in redis", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
+	if ttl <= 0 {
+		return nil, storage.ErrChatNotFound
+	}
 
-	outboxMsg := outbox.OutboxMessage{
-		Id:         1,
-		AuthorUuid: message.AuthorUuid.String(),
-		Body:       message.Body,
-		Published:  message.Published.String(),
+	// Ids are sequential within a chat, clients resume subscriptions by them
+	idPipe := r.db.TxPipeline()
+	mId := idPipe.Incr(ctx, messageIdKey+chat.String())
+	idPipe.PExpire(ctx, messageIdKey+chat.String(), ttl)
+	_, err = idPipe.Exec(ctx)
+	if err != nil {
+		log.Error("INCR message id error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	message.Id = int(mId.Val())
+
+	redisMessage := Message{Id: message.Id, Uuid: mUuid, AuthorUuid: message.AuthorUuid, Body: message.Body, Published: message.Published}
+	jsonMessage, err := json.Marshal(redisMessage)
+	if err != nil {
+		log.Error("marshalling error", sl.Err(err))
+		return nil, storage.ErrInternal
 	}
 
-	marshalledMessage, err := proto.Marshal(&outboxMsg)
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalMessageEvent(eventUuid, outbox.EventType_MESSAGE_POSTED, chat, message.AuthorUuid, &message)
 	if err != nil {
 		return &domain.Message{}, storage.ErrInternal
 	}
@@ -182,10 +419,17 @@ func (r *Redis) PostMessage(ctx context.Context, chat uuid.UUID, message domain.
 		Message: marshalledMessage,
 	}
 
+	author := message.AuthorUuid.String()
+	published := message.Published.UnixNano()
+
 	pipe := r.db.TxPipeline()
-	pipe.LPush(ctx, messagesKey+chat.String(), jsonMessage)
-	pipe.RPush(ctx, outboxList, redisMessage.Uuid.String())
-	pipe.HSet(ctx, outboxMessage+redisMessage.Uuid.String(), forSending)
+	pipe.ZAdd(ctx, messagesKey+chat.String(), redis.Z{Score: float64(message.Id), Member: jsonMessage})
+	pipe.PExpire(ctx, messagesKey+chat.String(), ttl)
+	pipe.ZAdd(ctx, userMessageTimes+author, redis.Z{Score: float64(published), Member: redisMessage.Uuid.String()})
+	pipe.ZRemRangeByScore(ctx, userMessageTimes+author, "-inf", fmt.Sprint(published-int64(messageTimesWindow)))
+	pipe.Expire(ctx, userMessageTimes+author, messageTimesWindow)
+	pipe.HIncrBy(ctx, userChatBytes+author, chat.String(), int64(len(message.Body)))
+	pushOutbox(ctx, pipe, chat.String(), eventUuid.String(), forSending)
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		log.Error("HSET error POST MESSAGE in redis", sl.Err(err))
@@ -199,35 +443,228 @@ func (r *Redis) TrimMessages(ctx context.Context, chat uuid.UUID, maximumMessage
 	op := "redis.TrimMessages"
 	log := r.log.With(slog.String("op", op))
 
-	_, err := r.db.LTrim(ctx, messagesKey+chat.String(), 0, int64(maximumMessages)-1).Result()
+	// Messages are scored by id, so the oldest ones have the lowest ranks
+	removed, err := r.db.ZRange(ctx, messagesKey+chat.String(), 0, -int64(maximumMessages)-1).Result()
 	if err != nil {
-		log.Error("LTRIM error in redis", sl.Err(err))
+		log.Error("ZRANGE error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	if len(removed) == 0 {
+		return false, nil
+	}
+
+	// Members are removed by value, ranks shift when new messages are posted meanwhile
+	pipe := r.db.TxPipeline()
+	for _, v := range removed {
+		var message Message
+		if err := json.Unmarshal([]byte(v), &message); err != nil {
+			log.Error("unmarshall error", sl.Err(err))
+			return false, storage.ErrInternal
+		}
+		pipe.ZRem(ctx, messagesKey+chat.String(), v)
+		pipe.HIncrBy(ctx, userChatBytes+message.AuthorUuid.String(), chat.String(), -int64(len(message.Body)))
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("ZREM error in redis", sl.Err(err))
 		return false, storage.ErrInternal
 	}
 	return true, nil
 }
 
-func (r *Redis) GetChatHistory(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Message, error) {
-	op := "redis.GetChatHistory"
+func (r *Redis) GetChatHistoryPage(ctx context.Context, chatUuid uuid.UUID, query domain.HistoryQuery) ([]*domain.Message, error) {
+	op := "redis.GetChatHistoryPage"
 	log := r.log.With(slog.String("op", op))
 
-	fmt.Println(messagesKey + chatUuid.String())
-	messagesJson, err := r.db.LRange(ctx, messagesKey+chatUuid.String(), 0, -1).Result()
+	args := redis.ZRangeArgs{
+		Key:     messagesKey + chatUuid.String(),
+		ByScore: true,
+		Count:   int64(query.Limit),
+	}
+	forward := query.AfterId > 0
+	if forward {
+		args.Start = fmt.Sprintf("(%d", query.AfterId)
+		args.Stop = "+inf"
+	} else {
+		// go-redis swaps the bounds itself for reversed ranges
+		args.Rev = true
+		args.Start = "-inf"
+		args.Stop = "+inf"
+		if query.BeforeId > 0 {
+			args.Stop = fmt.Sprintf("(%d", query.BeforeId)
+		}
+	}
+
+	messagesJson, err := r.db.ZRangeArgs(ctx, args).Result()
 	if err != nil {
-		log.Error("LRANGE error in redis", sl.Err(err))
+		log.Error("ZRANGE error in redis", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
 
-	var result []*domain.Message
-	for _, v := range messagesJson {
+	result := make([]*domain.Message, len(messagesJson))
+	for i, v := range messagesJson {
 		var message Message
 		err := json.Unmarshal([]byte(v), &message)
 		if err != nil {
 			log.Error("unmarshall error", sl.Err(err))
 			return nil, storage.ErrInternal
 		}
-		result = append(result, &domain.Message{AuthorUuid: message.AuthorUuid, Body: message.Body, Published: message.Published})
+		// Newest page is selected in reverse, history is always returned oldest first
+		pos := i
+		if !forward {
+			pos = len(messagesJson) - 1 - i
+		}
+		result[pos] = message.toDomain()
+	}
+	return result, nil
+}
+
+func (m *Message) toDomain() *domain.Message {
+	res := &domain.Message{Id: m.Id, AuthorUuid: m.AuthorUuid, Body: m.Body, Published: m.Published, DeletedAt: m.DeletedAt}
+	for _, edit := range m.Edits {
+		res.Edits = append(res.Edits, domain.MessageEdit{Body: edit.Body, Edited: edit.Edited})
+	}
+	return res
+}
+
+// getMessage reads the message with db, which is the client or the connection of a watch
+func getMessage(ctx context.Context, db redis.Cmdable, chatUuid uuid.UUID, messageId int) (*Message, error) {
+	score := fmt.Sprint(messageId)
+	messagesJson, err := db.ZRangeByScore(ctx, messagesKey+chatUuid.String(), &redis.ZRangeBy{Min: score, Max: score}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(messagesJson) == 0 {
+		return nil, storage.ErrMessageNotFound
+	}
+
+	var message Message
+	err = json.Unmarshal([]byte(messagesJson[0]), &message)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *Redis) GetMessage(ctx context.Context, chatUuid uuid.UUID, messageId int) (*domain.Message, error) {
+	op := "redis.GetMessage"
+	log := r.log.With(slog.String("op", op))
+
+	message, err := getMessage(ctx, r.db, chatUuid, messageId)
+	if errors.Is(err, storage.ErrMessageNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("ZRANGEBYSCORE error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	return message.toDomain(), nil
+}
+
+// EditMessage replaces the body of the message and keeps the previous one in its edit history.
+// Deleted messages can't be edited.
+func (r *Redis) EditMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, body string, edited time.Time) (*domain.Message, error) {
+	return r.updateMessage(ctx, chatUuid, messageId, outbox.EventType_MESSAGE_EDITED, actorUuid, func(message *Message) int {
+		bytesDelta := len(body) - len(message.Body)
+		message.Edits = append(message.Edits, Edit{Body: message.Body, Edited: edited})
+		message.Body = body
+		return bytesDelta
+	})
+}
+
+// DeleteMessage leaves a tombstone instead of the message, its body and edit history are dropped.
+func (r *Redis) DeleteMessage(ctx context.Context, chatUuid uuid.UUID, actorUuid uuid.UUID, messageId int, deleted time.Time) (*domain.Message, error) {
+	return r.updateMessage(ctx, chatUuid, messageId, outbox.EventType_MESSAGE_DELETED, actorUuid, func(message *Message) int {
+		bytesDelta := -len(message.Body)
+		message.Body = ""
+		message.Edits = nil
+		message.DeletedAt = deleted
+		return bytesDelta
+	})
+}
+
+// maxMessageUpdateAttempts is how many times an edit or a delete is tried while the chat is changed concurrently
+const maxMessageUpdateAttempts = 20
+
+// updateMessage applies change to the message which isn't deleted and stores it with its outbox event.
+// change returns how much the body has grown, it's counted against the author's quota.
+// The messages of the chat are watched, so concurrent edits and deletes don't overwrite each other.
+func (r *Redis) updateMessage(ctx context.Context, chatUuid uuid.UUID, messageId int, event outbox.EventType, actorUuid uuid.UUID, change func(message *Message) int) (*domain.Message, error) {
+	op := "redis.updateMessage"
+	log := r.log.With(slog.String("op", op))
+
+	var result *domain.Message
+	for attempt := 0; attempt < maxMessageUpdateAttempts; attempt++ {
+		err := r.db.Watch(ctx, func(tx *redis.Tx) error {
+			// Reading with another connection of the pool may exhaust it under concurrent updates
+			message, err := getMessage(ctx, tx, chatUuid, messageId)
+			if err != nil {
+				return err
+			}
+			if !message.DeletedAt.IsZero() {
+				return storage.ErrMessageNotFound
+			}
+
+			bytesDelta := change(message)
+			result, err = r.replaceMessage(ctx, tx, chatUuid, message, event, actorUuid, bytesDelta)
+			return err
+		}, messagesKey+chatUuid.String())
+
+		switch {
+		case errors.Is(err, redis.TxFailedErr):
+			// The chat was changed after the message was read
+			continue
+		case errors.Is(err, storage.ErrMessageNotFound), errors.Is(err, storage.ErrInternal):
+			return nil, err
+		case err != nil:
+			log.Error("error UPDATE MESSAGE in redis", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		return result, nil
+	}
+
+	log.Error("message is changed concurrently, giving up", slog.Int("attempts", maxMessageUpdateAttempts))
+	return nil, storage.ErrInternal
+}
+
+// replaceMessage stores the new version of the message together with its outbox event in the transaction of tx.
+// bytesDelta is how much the body has grown, it's counted against the author's quota.
+func (r *Redis) replaceMessage(ctx context.Context, tx *redis.Tx, chatUuid uuid.UUID, message *Message, event outbox.EventType, actorUuid uuid.UUID, bytesDelta int) (*domain.Message, error) {
+	op := "redis.replaceMessage"
+	log := r.log.With(slog.String("op", op))
+
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Error("marshalling error", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	result := message.toDomain()
+	eventUuid := uuid.New()
+	marshalledMessage, err := storage.MarshalMessageEvent(eventUuid, event, chatUuid, actorUuid, result)
+	if err != nil {
+		return nil, storage.ErrInternal
+	}
+
+	forSending := OutboxMessage{
+		Topic:   domain.MessageTopic,
+		Message: marshalledMessage,
+	}
+	score := fmt.Sprint(message.Id)
+
+	_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, messagesKey+chatUuid.String(), score, score)
+		pipe.ZAdd(ctx, messagesKey+chatUuid.String(), redis.Z{Score: float64(message.Id), Member: jsonMessage})
+		if bytesDelta != 0 {
+			pipe.HIncrBy(ctx, userChatBytes+message.AuthorUuid.String(), chatUuid.String(), int64(bytesDelta))
+		}
+		pushOutbox(ctx, pipe, chatUuid.String(), eventUuid.String(), forSending)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
 	return result, nil
 }
 
@@ -299,68 +736,796 @@ func (r *Redis) GetUserByUuid(ctx context.Context, userUuid uuid.UUID) (*domain.
 	return &domain.User{Uuid: parsedUuid, Login: user.Login, PasswordHash: user.PasswordHash}, nil
 }
 
-func (r *Redis) UpsertRefreshToken(ctx context.Context, userUuid uuid.UUID, refreshToken string) error {
-	op := "redis.UpsertRefresToken"
+// RevokeToken puts the token into the denylist until it expires
+func (r *Redis) RevokeToken(ctx context.Context, jti uuid.UUID, expired time.Time) error {
+	op := "redis.RevokeToken"
 	log := r.log.With(slog.String("op", op))
 
+	ttl := time.Until(expired)
+	if ttl <= 0 {
+		return nil
+	}
+
+	err := r.db.Set(ctx, revokedToken+jti.String(), 1, ttl).Err()
+	if err != nil {
+		log.Error("SET error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token is in the denylist or its session is gone
+func (r *Redis) IsTokenRevoked(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID, jti uuid.UUID) (bool, error) {
+	op := "redis.IsTokenRevoked"
+	log := r.log.With(slog.String("op", op))
+
+	denied, err := r.db.Exists(ctx, revokedToken+jti.String()).Result()
+	if err != nil {
+		log.Error("EXISTS error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	if denied > 0 {
+		return true, nil
+	}
+
+	session, err := r.getSession(ctx, sessionUuid)
+	if errors.Is(err, storage.ErrSessionNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		log.Error("GET session error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	return session.UserUuid != userUuid, nil
+}
+
+func (r *Redis) CreateSession(ctx context.Context, session domain.Session) error {
+	op := "redis.CreateSession"
+	log := r.log.With(slog.String("op", op))
+
+	jsonSession, err := json.Marshal(Session(session))
+	if err != nil {
+		log.Error("marshalling error", sl.Err(err))
+		return storage.ErrInternal
+	}
+
 	pipe := r.db.TxPipeline()
-	pipe.Set(ctx, refreshTokens+userUuid.String(), refreshToken, -1)
-	_, err := pipe.Exec(ctx)
+	pipe.Set(ctx, sessionKey+session.Uuid.String(), jsonSession, 0)
+	pipe.ExpireAt(ctx, sessionKey+session.Uuid.String(), session.Expires)
+	pipe.SAdd(ctx, userSessions+session.UserUuid.String(), session.Uuid.String())
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("SET error CREATE SESSION in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
 
+func (r *Redis) getSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error) {
+	jsonSession, err := r.db.Get(ctx, sessionKey+sessionUuid.String()).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, storage.ErrSessionNotFound
+	}
 	if err != nil {
-		log.Error("HSET error in redis", sl.Err(err))
+		return nil, err
+	}
+
+	var session Session
+	err = json.Unmarshal(jsonSession, &session)
+	if err != nil {
+		return nil, err
+	}
+	res := domain.Session(session)
+	return &res, nil
+}
+
+func (r *Redis) GetSession(ctx context.Context, sessionUuid uuid.UUID) (*domain.Session, error) {
+	op := "redis.GetSession"
+	log := r.log.With(slog.String("op", op))
+
+	session, err := r.getSession(ctx, sessionUuid)
+	if errors.Is(err, storage.ErrSessionNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("GET session error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	return session, nil
+}
+
+// RotateSession stores the new refresh token of the session if previousJti is still the current one.
+// Otherwise the previous token was used already and storage.ErrSessionNotFound is returned.
+func (r *Redis) RotateSession(ctx context.Context, session domain.Session, previousJti uuid.UUID) error {
+	op := "redis.RotateSession"
+	log := r.log.With(slog.String("op", op))
+
+	key := sessionKey + session.Uuid.String()
+	jsonSession, err := json.Marshal(Session(session))
+	if err != nil {
+		log.Error("marshalling error", sl.Err(err))
+		return storage.ErrInternal
+	}
+
+	// Concurrent refreshes with the same token race here, only one of them may win
+	err = r.db.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := r.getSession(ctx, session.Uuid)
+		if err != nil {
+			return err
+		}
+		if current.RefreshJti != previousJti {
+			return storage.ErrSessionNotFound
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, jsonSession, 0)
+			pipe.ExpireAt(ctx, key, session.Expires)
+			return nil
+		})
+		return err
+	}, key)
+
+	if errors.Is(err, storage.ErrSessionNotFound) || errors.Is(err, redis.TxFailedErr) {
+		return storage.ErrSessionNotFound
+	}
+	if err != nil {
+		log.Error("SET error ROTATE SESSION in redis", sl.Err(err))
 		return storage.ErrInternal
 	}
 	return nil
 }
 
-func (r *Redis) GetRefreshToken(ctx context.Context, userUuid uuid.UUID) (string, error) {
-	op := "redis.GetRefreshToken"
+func (r *Redis) ListSessions(ctx context.Context, userUuid uuid.UUID) ([]*domain.Session, error) {
+	op := "redis.ListSessions"
 	log := r.log.With(slog.String("op", op))
 
-	//1. Get UserUuid by Login
-	token, err := r.db.Get(ctx, refreshTokens+userUuid.String()).Result()
+	sessionUuids, err := r.db.SMembers(ctx, userSessions+userUuid.String()).Result()
+	if err != nil {
+		log.Error("SMEMBERS error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	var res []*domain.Session
+	var expired []any
+	for _, v := range sessionUuids {
+		sessionUuid, err := uuid.Parse(v)
+		if err != nil {
+			expired = append(expired, v)
+			continue
+		}
+		session, err := r.getSession(ctx, sessionUuid)
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			expired = append(expired, v)
+			continue
+		}
+		if err != nil {
+			log.Error("GET session error in redis", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		res = append(res, session)
+	}
+
+	// Session keys expire on their own, the index has to be cleaned up by hand
+	if len(expired) > 0 {
+		r.db.SRem(ctx, userSessions+userUuid.String(), expired...)
+	}
+
+	sort.Slice(res, func(a, b int) bool { return res[a].Created.Before(res[b].Created) })
+	return res, nil
+}
+
+func (r *Redis) DeleteSession(ctx context.Context, userUuid uuid.UUID, sessionUuid uuid.UUID) error {
+	op := "redis.DeleteSession"
+	log := r.log.With(slog.String("op", op))
+
+	session, err := r.getSession(ctx, sessionUuid)
+	if errors.Is(err, storage.ErrSessionNotFound) {
+		return err
+	}
+	if err != nil {
+		log.Error("GET session error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if session.UserUuid != userUuid {
+		return storage.ErrSessionNotFound
+	}
+
+	pipe := r.db.TxPipeline()
+	pipe.Del(ctx, sessionKey+sessionUuid.String())
+	pipe.SRem(ctx, userSessions+userUuid.String(), sessionUuid.String())
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("DEL error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+func (r *Redis) DeleteUserSessions(ctx context.Context, userUuid uuid.UUID) error {
+	op := "redis.DeleteUserSessions"
+	log := r.log.With(slog.String("op", op))
+
+	sessionUuids, err := r.db.SMembers(ctx, userSessions+userUuid.String()).Result()
+	if err != nil {
+		log.Error("SMEMBERS error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+
+	keys := []string{userSessions + userUuid.String()}
+	for _, v := range sessionUuids {
+		keys = append(keys, sessionKey+v)
+	}
+
+	err = r.db.Del(ctx, keys...).Err()
 	if err != nil {
+		log.Error("DEL error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// ClaimOutboxBatch moves up to limit oldest outboxes to the processing list for the lease.
+// Claimed ones aren't returned again until the lease is over, neither are the other outboxes of their chats.
+func (r *Redis) ClaimOutboxBatch(ctx context.Context, limit int, lease time.Duration) ([]*domain.Outbox, error) {
+	op := "redis.ClaimOutboxBatch"
+	log := r.log.With(slog.String("op", op))
+
+	now := time.Now()
+	outboxUuids, err := claimScript.Run(ctx, r.db, outboxKeys, now.UnixMilli(), now.Add(lease).UnixMilli(), limit).StringSlice()
+	if err != nil {
+		log.Error("claim outbox error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	if len(outboxUuids) == 0 {
+		return nil, nil
+	}
+
+	pipe := r.db.Pipeline()
+	messages := make([]*redis.MapStringStringCmd, len(outboxUuids))
+	for i, v := range outboxUuids {
+		messages[i] = pipe.HGetAll(ctx, outboxMessage+v)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("HGETALL outbox error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	res := make([]*domain.Outbox, 0, len(outboxUuids))
+	for i, v := range outboxUuids {
+		// The outbox was confirmed after its lease had been over
+		if len(messages[i].Val()) == 0 {
+			continue
+		}
+		outboxUuid, err := uuid.Parse(v)
+		if err != nil {
+			log.Error("failed to parse uuid", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		var forSending OutboxMessage
+		if err := messages[i].Scan(&forSending); err != nil {
+			log.Error("error unmarshalling message", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		res = append(res, &domain.Outbox{
+			Uuid:          outboxUuid,
+			ChatUuid:      parseChatUuid(forSending.ChatUuid),
+			Seq:           forSending.Seq,
+			SchemaVersion: forSending.SchemaVersion,
+			TraceContext:  storage.UnmarshalTraceContext(forSending.TraceContext),
+			Topic:         forSending.Topic,
+			Message:       forSending.Message,
+			Attempts:      forSending.Attempts,
+			LastError:     forSending.LastError,
+		})
+	}
+	return res, nil
+}
+
+// OutboxBacklog counts unsent outboxes and measures how long the oldest one has been waiting.
+// Sent outboxes are deleted when they are confirmed, so the oldest is at the head of the outbox or of the processing list.
+func (r *Redis) OutboxBacklog(ctx context.Context) (*domain.OutboxBacklog, error) {
+	op := "redis.OutboxBacklog"
+	log := r.log.With(slog.String("op", op))
+
+	pipe := r.db.Pipeline()
+	lengths := []*redis.IntCmd{pipe.LLen(ctx, outboxList), pipe.LLen(ctx, outboxProcessing)}
+	heads := []*redis.StringCmd{pipe.LIndex(ctx, outboxList, 0), pipe.LIndex(ctx, outboxProcessing, 0)}
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("LLEN outbox error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	var res domain.OutboxBacklog
+	var oldest int64
+	for i := range lengths {
+		res.Unsent += int(lengths[i].Val())
+		if heads[i].Val() == "" {
+			continue
+		}
+		created, err := r.db.HGet(ctx, outboxMessage+heads[i].Val(), "created").Int64()
 		if errors.Is(err, redis.Nil) {
-			return "", storage.ErrTokenNotFound
+			continue
 		}
-		log.Error("GET UserUuid by Login error", sl.Err(err))
-		return "", storage.ErrInternal
+		if err != nil {
+			log.Error("HGET outbox error in redis", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		if oldest == 0 || created < oldest {
+			oldest = created
+		}
+	}
+	if oldest > 0 {
+		res.OldestAge = time.Since(time.UnixMilli(oldest))
+	}
+	return &res, nil
+}
+
+// ReleaseOutbox returns claimed outboxes to the head of the outbox before their lease is over
+func (r *Redis) ReleaseOutbox(ctx context.Context, outboxUuids []uuid.UUID) error {
+	op := "redis.ReleaseOutbox"
+	log := r.log.With(slog.String("op", op))
+
+	if len(outboxUuids) == 0 {
+		return nil
+	}
+	released := make([]any, len(outboxUuids))
+	for i, v := range outboxUuids {
+		released[i] = v.String()
+	}
+
+	err := releaseScript.Run(ctx, r.db, outboxKeys, released...).Err()
+	if err != nil {
+		log.Error("release outbox error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+func (r *Redis) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
+	op := "redis.ConfirmOutboxSended"
+	log := r.log.With(slog.String("op", op))
+
+	// The outbox is back in the list if it was confirmed after its lease had been over
+	pipe := r.db.TxPipeline()
+	removeScript.Eval(ctx, pipe, outboxKeys, outboxUuid.String())
+	pipe.Del(ctx, outboxMessage+outboxUuid.String())
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Error("LREM outbox error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
+}
+
+// FailOutbox records a failed attempt to publish the outbox.
+// After maxAttempts the outbox is moved to the dead letters and true is returned.
+func (r *Redis) FailOutbox(ctx context.Context, outboxUuid uuid.UUID, lastError string, maxAttempts int) (bool, error) {
+	op := "redis.FailOutbox"
+	log := r.log.With(slog.String("op", op))
+
+	key := outboxMessage + outboxUuid.String()
+	stored := r.db.HGetAll(ctx, key)
+	if err := stored.Err(); err != nil {
+		log.Error("HGETALL outbox error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	if len(stored.Val()) == 0 {
+		return false, storage.ErrOutboxNotFound
+	}
+	var forSending OutboxMessage
+	if err := stored.Scan(&forSending); err != nil {
+		log.Error("error unmarshalling message", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	forSending.Attempts++
+	forSending.LastError = lastError
+	deadLettered := forSending.Attempts >= maxAttempts
+
+	pipe := r.db.TxPipeline()
+	if !deadLettered {
+		// The outbox is released to be retried before the newer ones
+		pipe.HSet(ctx, key, forSending)
+		releaseScript.Eval(ctx, pipe, outboxKeys, outboxUuid.String())
+	} else {
+		failed := time.Now().UnixNano()
+		deadLetter := DeadLetter{
+			Topic:         forSending.Topic,
+			Message:       forSending.Message,
+			ChatUuid:      forSending.ChatUuid,
+			Seq:           forSending.Seq,
+			SchemaVersion: forSending.SchemaVersion,
+			TraceContext:  forSending.TraceContext,
+			Attempts:      forSending.Attempts,
+			LastError:     forSending.LastError,
+			Failed:        failed,
+		}
+		removeScript.Eval(ctx, pipe, outboxKeys, outboxUuid.String())
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, deadLetterKey+outboxUuid.String(), deadLetter)
+		pipe.ZAdd(ctx, deadLetterList, redis.Z{Score: float64(failed), Member: outboxUuid.String()})
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Error("HSET outbox error in redis", sl.Err(err))
+		return false, storage.ErrInternal
+	}
+	return deadLettered, nil
+}
+
+// ListDeadLetters returns a page of dead letters, the oldest failures first
+func (r *Redis) ListDeadLetters(ctx context.Context, limit int, offset int) ([]*domain.DeadLetter, error) {
+	op := "redis.ListDeadLetters"
+	log := r.log.With(slog.String("op", op))
+
+	deadLetterUuids, err := r.db.ZRange(ctx, deadLetterList, int64(offset), int64(offset+limit)-1).Result()
+	if err != nil {
+		log.Error("ZRANGE dead letters error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	if len(deadLetterUuids) == 0 {
+		return nil, nil
+	}
+
+	pipe := r.db.Pipeline()
+	stored := make([]*redis.MapStringStringCmd, len(deadLetterUuids))
+	for i, v := range deadLetterUuids {
+		stored[i] = pipe.HGetAll(ctx, deadLetterKey+v)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("HGETALL dead letter error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	res := make([]*domain.DeadLetter, 0, len(deadLetterUuids))
+	for i, v := range deadLetterUuids {
+		deadLetterUuid, err := uuid.Parse(v)
+		if err != nil {
+			log.Error("failed to parse uuid", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		var deadLetter DeadLetter
+		if err := stored[i].Scan(&deadLetter); err != nil {
+			log.Error("error unmarshalling dead letter", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		res = append(res, deadLetter.toDomain(deadLetterUuid))
+	}
+	return res, nil
+}
+
+func (r *Redis) GetDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) (*domain.DeadLetter, error) {
+	op := "redis.GetDeadLetter"
+	log := r.log.With(slog.String("op", op))
+
+	stored := r.db.HGetAll(ctx, deadLetterKey+deadLetterUuid.String())
+	if err := stored.Err(); err != nil {
+		log.Error("HGETALL dead letter error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	if len(stored.Val()) == 0 {
+		return nil, storage.ErrDeadLetterNotFound
+	}
+	var deadLetter DeadLetter
+	if err := stored.Scan(&deadLetter); err != nil {
+		log.Error("error unmarshalling dead letter", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	return deadLetter.toDomain(deadLetterUuid), nil
+}
+
+// ReplayDeadLetter puts the dead letter back to the end of the outbox with its attempts reset
+func (r *Redis) ReplayDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	op := "redis.ReplayDeadLetter"
+	log := r.log.With(slog.String("op", op))
+
+	deadLetter, err := r.GetDeadLetter(ctx, deadLetterUuid)
+	if err != nil {
+		return err
+	}
+	forSending := OutboxMessage{
+		Topic:         deadLetter.Topic,
+		Message:       deadLetter.Message,
+		Seq:           deadLetter.Seq,
+		SchemaVersion: deadLetter.SchemaVersion,
+		Created:       time.Now().UnixMilli(),
+		TraceContext:  storage.MarshalTraceContext(deadLetter.TraceContext),
+	}
+	if deadLetter.ChatUuid != uuid.Nil {
+		forSending.ChatUuid = deadLetter.ChatUuid.String()
 	}
-	return token, nil
+
+	pipe := r.db.TxPipeline()
+	pipe.RPush(ctx, outboxList, deadLetterUuid.String())
+	pipe.HSet(ctx, outboxMessage+deadLetterUuid.String(), forSending)
+	pipe.HSet(ctx, outboxChats, deadLetterUuid.String(), forSending.ChatUuid)
+	pipe.ZRem(ctx, deadLetterList, deadLetterUuid.String())
+	pipe.Del(ctx, deadLetterKey+deadLetterUuid.String())
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("RPUSH outbox error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	return nil
 }
 
-func (r *Redis) GetNextOutbox(ctx context.Context) (*domain.Outbox, error) {
-	op := "redis.GetNextOutbox"
+func (r *Redis) DeleteDeadLetter(ctx context.Context, deadLetterUuid uuid.UUID) error {
+	op := "redis.DeleteDeadLetter"
 	log := r.log.With(slog.String("op", op))
 
-	outboxUuid, err := r.db.LIndex(ctx, outboxList, 0).Result()
+	pipe := r.db.TxPipeline()
+	removed := pipe.ZRem(ctx, deadLetterList, deadLetterUuid.String())
+	pipe.Del(ctx, deadLetterKey+deadLetterUuid.String())
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Error("ZREM dead letter error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if removed.Val() == 0 {
+		return storage.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// PurgeDeadLetters deletes all dead letters and returns how many were deleted
+func (r *Redis) PurgeDeadLetters(ctx context.Context) (int, error) {
+	op := "redis.PurgeDeadLetters"
+	log := r.log.With(slog.String("op", op))
+
+	deadLetterUuids, err := r.db.ZRange(ctx, deadLetterList, 0, -1).Result()
+	if err != nil {
+		log.Error("ZRANGE dead letters error in redis", sl.Err(err))
+		return 0, storage.ErrInternal
+	}
+	if len(deadLetterUuids) == 0 {
+		return 0, nil
+	}
+
+	pipe := r.db.TxPipeline()
+	for _, v := range deadLetterUuids {
+		pipe.ZRem(ctx, deadLetterList, v)
+		pipe.Del(ctx, deadLetterKey+v)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("DEL dead letters error in redis", sl.Err(err))
+		return 0, storage.ErrInternal
+	}
+	return len(deadLetterUuids), nil
+}
+
+func (r *Redis) AddMember(ctx context.Context, member domain.Member) (*domain.Member, error) {
+	op := "redis.AddMember"
+	log := r.log.With(slog.String("op", op))
+
+	exists, err := r.db.Exists(ctx, usersKey+member.UserUuid.String()).Result()
+	if err != nil {
+		log.Error("EXISTS user error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	if exists == 0 {
+		return nil, storage.ErrUserNotFound
+	}
+
+	// Members live as long as the chat itself
+	ttl, err := r.db.PTTL(ctx, chatKey+member.ChatUuid.String()).Result()
+	if err != nil {
+		log.Error("PTTL chat error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	redisMember := Member{UserUuid: member.UserUuid, Role: member.Role, InvitedBy: member.InvitedBy, Joined: member.Joined}
+	current, err := r.getMember(ctx, member.ChatUuid, member.UserUuid)
+	if err == nil {
+		redisMember.Joined = current.Joined
+	}
+	jsonMember, err := json.Marshal(redisMember)
+	if err != nil {
+		log.Error("marshalling error", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	pipe := r.db.TxPipeline()
+	pipe.HSet(ctx, chatMembersKey+member.ChatUuid.String(), member.UserUuid.String(), jsonMember)
+	if ttl > 0 {
+		pipe.PExpire(ctx, chatMembersKey+member.ChatUuid.String(), ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("HSET member error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	member.Joined = redisMember.Joined
+	return &member, nil
+}
+
+func (r *Redis) GetMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error) {
+	op := "redis.GetMember"
+	log := r.log.With(slog.String("op", op))
+
+	member, err := r.getMember(ctx, chatUuid, userUuid)
+	if err != nil {
+		if !errors.Is(err, storage.ErrMemberNotFound) {
+			log.Error("HGET member error in redis", sl.Err(err))
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *Redis) getMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) (*domain.Member, error) {
+	jsonMember, err := r.db.HGet(ctx, chatMembersKey+chatUuid.String(), userUuid.String()).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, storage.ErrNoOutbox
+			return nil, storage.ErrMemberNotFound
 		}
-		log.Error("error in getting next outbox", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
 
-	forSending := OutboxMessage{}
+	var member Member
+	if err := json.Unmarshal([]byte(jsonMember), &member); err != nil {
+		return nil, storage.ErrInternal
+	}
+	return &domain.Member{ChatUuid: chatUuid, UserUuid: member.UserUuid, Role: member.Role, InvitedBy: member.InvitedBy, Joined: member.Joined}, nil
+}
 
-	err = r.db.HGetAll(ctx, outboxMessage+outboxUuid).Scan(&forSending)
+func (r *Redis) RemoveMember(ctx context.Context, chatUuid uuid.UUID, userUuid uuid.UUID) error {
+	op := "redis.RemoveMember"
+	log := r.log.With(slog.String("op", op))
 
+	removed, err := r.db.HDel(ctx, chatMembersKey+chatUuid.String(), userUuid.String()).Result()
 	if err != nil {
-		log.Error("error unmarshalling message", sl.Err(err))
+		log.Error("HDEL member error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if removed == 0 {
+		return storage.ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *Redis) ListMembers(ctx context.Context, chatUuid uuid.UUID) ([]*domain.Member, error) {
+	op := "redis.ListMembers"
+	log := r.log.With(slog.String("op", op))
+
+	membersJson, err := r.db.HVals(ctx, chatMembersKey+chatUuid.String()).Result()
+	if err != nil {
+		log.Error("HVALS members error in redis", sl.Err(err))
 		return nil, storage.ErrInternal
 	}
 
-	return &domain.Outbox{Uuid: uuid.MustParse(outboxUuid), Topic: forSending.Topic, Message: forSending.Message}, nil
+	result := make([]*domain.Member, 0, len(membersJson))
+	for _, v := range membersJson {
+		var member Member
+		if err := json.Unmarshal([]byte(v), &member); err != nil {
+			log.Error("unmarshall error", sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		result = append(result, &domain.Member{ChatUuid: chatUuid, UserUuid: member.UserUuid, Role: member.Role, InvitedBy: member.InvitedBy, Joined: member.Joined})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Joined.Before(result[j].Joined) })
+	return result, nil
 }
 
-func (r *Redis) ConfirmOutboxSended(ctx context.Context, outboxUuid uuid.UUID) error {
-	// op := "redis.ConfirmOutboxSended"
-	// log := r.log.With(slog.String("op", op))
+type Quota struct {
+	ChatsOwned        int   `redis:"chatsOwned"`
+	MessagesPerMinute int   `redis:"messagesPerMinute"`
+	MessageBytes      int64 `redis:"messageBytes"`
+}
+
+func (r *Redis) GetUserQuota(ctx context.Context, userUuid uuid.UUID) (*domain.Quota, error) {
+	op := "redis.GetUserQuota"
+	log := r.log.With(slog.String("op", op))
+
+	res := r.db.HGetAll(ctx, userQuotaKey+userUuid.String())
+	if err := res.Err(); err != nil {
+		log.Error("HGETALL error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	if len(res.Val()) == 0 {
+		return nil, storage.ErrQuotaNotFound
+	}
+
+	var quota Quota
+	if err := res.Scan(&quota); err != nil {
+		log.Error("scan error", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	return &domain.Quota{ChatsOwned: quota.ChatsOwned, MessagesPerMinute: quota.MessagesPerMinute, MessageBytes: quota.MessageBytes}, nil
+}
 
-	r.db.LRem(ctx, outboxList, 1, outboxUuid.String())
-	r.db.HDel(ctx, outboxMessage+outboxUuid.String())
+func (r *Redis) SetUserQuota(ctx context.Context, userUuid uuid.UUID, quota domain.Quota) error {
+	op := "redis.SetUserQuota"
+	log := r.log.With(slog.String("op", op))
 
+	exists, err := r.db.Exists(ctx, usersKey+userUuid.String()).Result()
+	if err != nil {
+		log.Error("EXISTS error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
+	if exists == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	redisQuota := Quota{ChatsOwned: quota.ChatsOwned, MessagesPerMinute: quota.MessagesPerMinute, MessageBytes: quota.MessageBytes}
+	err = r.db.HSet(ctx, userQuotaKey+userUuid.String(), redisQuota).Err()
+	if err != nil {
+		log.Error("HSET error in redis", sl.Err(err))
+		return storage.ErrInternal
+	}
 	return nil
 }
+
+// QuotaUsage counts chats of the user that haven't expired yet, their messages posted after since
+// and the size of all their messages kept in chats that haven't expired yet
+func (r *Redis) QuotaUsage(ctx context.Context, userUuid uuid.UUID, since time.Time) (*domain.QuotaUsage, error) {
+	op := "redis.QuotaUsage"
+	log := r.log.With(slog.String("op", op))
+
+	pipe := r.db.Pipeline()
+	ownedCmd := pipe.SMembers(ctx, userChats+userUuid.String())
+	bytesCmd := pipe.HGetAll(ctx, userChatBytes+userUuid.String())
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Error("pipeline error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+	chats := ownedCmd.Val()
+	chatBytes := bytesCmd.Val()
+
+	pipe = r.db.Pipeline()
+	exists := make([]*redis.IntCmd, len(chats))
+	for i, chat := range chats {
+		exists[i] = pipe.Exists(ctx, chatKey+chat)
+	}
+	bytesExist := make(map[string]*redis.IntCmd, len(chatBytes))
+	for chat := range chatBytes {
+		bytesExist[chat] = pipe.Exists(ctx, chatKey+chat)
+	}
+	messages := pipe.ZCount(ctx, userMessageTimes+userUuid.String(), fmt.Sprintf("(%d", since.UnixNano()), "+inf")
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		log.Error("pipeline error in redis", sl.Err(err))
+		return nil, storage.ErrInternal
+	}
+
+	var usage domain.QuotaUsage
+	var expired []any
+	for i, chat := range chats {
+		if exists[i].Val() > 0 {
+			usage.ChatsOwned++
+		} else {
+			expired = append(expired, chat)
+		}
+	}
+	var expiredBytes []string
+	for chat, bytes := range chatBytes {
+		if bytesExist[chat].Val() == 0 {
+			expiredBytes = append(expiredBytes, chat)
+			continue
+		}
+		size, err := strconv.ParseInt(bytes, 10, 64)
+		if err != nil {
+			log.Error("can't parse message bytes", slog.String("chat_uuid", chat), sl.Err(err))
+			return nil, storage.ErrInternal
+		}
+		usage.MessageBytes += size
+	}
+	usage.MessagesLastMinute = int(messages.Val())
+
+	// Chats expire by ttl, so the set and the sizes are cleaned up lazily
+	if len(expired) > 0 {
+		if err := r.db.SRem(ctx, userChats+userUuid.String(), expired...).Err(); err != nil {
+			log.Warn("SREM error in redis", sl.Err(err))
+		}
+	}
+	if len(expiredBytes) > 0 {
+		if err := r.db.HDel(ctx, userChatBytes+userUuid.String(), expiredBytes...).Err(); err != nil {
+			log.Warn("HDEL error in redis", sl.Err(err))
+		}
+	}
+	return &usage, nil
+}
@@ -0,0 +1,931 @@
+// Package storagetest is the conformance suite every storage backend has to pass.
+// Backends plug in by calling Run from their own tests.
+package storagetest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	outboxpb "github.com/alexandernizov/grpcmessanger/api/gen/outbox"
+
+	"github.com/alexandernizov/grpcmessanger/internal/domain"
+	"github.com/alexandernizov/grpcmessanger/internal/outbox"
+	"github.com/alexandernizov/grpcmessanger/internal/services/admin"
+	"github.com/alexandernizov/grpcmessanger/internal/services/auth"
+	"github.com/alexandernizov/grpcmessanger/internal/services/chat"
+	"github.com/alexandernizov/grpcmessanger/internal/storage"
+)
+
+// Storage is everything the services expect from a backend
+type Storage interface {
+	auth.AuthStorage
+	chat.ChatStorage
+	outbox.OutboxProvider
+	outbox.BacklogProvider
+	admin.DeadLetterStorage
+	admin.QuotaStorage
+}
+
+// timeDelta is how much stored times may differ from the written ones, databases round them
+const timeDelta = time.Millisecond
+
+// Run runs the suite against the storage returned by newStorage.
+// The storage may be shared with other tests, so everything is created with fresh uuids,
+// but it must not be used by anything else while the outbox and dead letters are checked.
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s Storage)
+	}{
+		{"Users", testUsers},
+		{"Sessions", testSessions},
+		{"Tokens", testTokens},
+		{"Chats", testChats},
+		{"ExpiredChats", testExpiredChats},
+		{"Messages", testMessages},
+		{"History", testHistory},
+		{"Trim", testTrim},
+		{"EditDelete", testEditDelete},
+		{"ConcurrentEditDelete", testConcurrentEditDelete},
+		{"Members", testMembers},
+		{"Quotas", testQuotas},
+		{"QuotaPostingLog", testQuotaPostingLog},
+		{"Outbox", testOutbox},
+		{"OutboxBacklog", testOutboxBacklog},
+		{"OutboxRetention", testOutboxRetention},
+		{"DeadLetters", testDeadLetters},
+		{"OutboxTraceContext", testOutboxTraceContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func newUser(t *testing.T, s Storage) *domain.User {
+	user, err := s.CreateUser(context.Background(), domain.User{Uuid: uuid.New(), Login: uuid.NewString(), PasswordHash: []byte("hash")})
+	require.NoError(t, err)
+	return user
+}
+
+func newChat(t *testing.T, s Storage, owner *domain.User) *domain.Chat {
+	chat, err := s.CreateChat(context.Background(), domain.Chat{Uuid: uuid.New(), Owner: *owner, Deadline: now().Add(time.Hour)})
+	require.NoError(t, err)
+	return chat
+}
+
+func postMessages(t *testing.T, s Storage, chatUuid uuid.UUID, author uuid.UUID, bodies ...string) []*domain.Message {
+	var res []*domain.Message
+	for _, body := range bodies {
+		message, err := s.PostMessage(context.Background(), chatUuid, domain.Message{AuthorUuid: author, Body: body, Published: now()})
+		require.NoError(t, err)
+		res = append(res, message)
+	}
+	return res
+}
+
+func bodies(messages []*domain.Message) []string {
+	res := make([]string, 0, len(messages))
+	for _, v := range messages {
+		res = append(res, v.Body)
+	}
+	return res
+}
+
+func testUsers(t *testing.T, s Storage) {
+	ctx := context.Background()
+	user := newUser(t, s)
+
+	byLogin, err := s.GetUserByLogin(ctx, user.Login)
+	require.NoError(t, err)
+	assert.Equal(t, user, byLogin)
+
+	byUuid, err := s.GetUserByUuid(ctx, user.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, user, byUuid)
+
+	_, err = s.GetUserByLogin(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.GetUserByUuid(ctx, uuid.New())
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func newSession(t *testing.T, s Storage, userUuid uuid.UUID, created time.Time) domain.Session {
+	session := domain.Session{
+		Uuid:       uuid.New(),
+		UserUuid:   userUuid,
+		RefreshJti: uuid.New(),
+		DeviceName: "phone",
+		UserAgent:  "test",
+		Created:    created,
+		LastUsed:   created,
+		Expires:    created.Add(time.Hour),
+	}
+	require.NoError(t, s.CreateSession(context.Background(), session))
+	return session
+}
+
+func testSessions(t *testing.T, s Storage) {
+	ctx := context.Background()
+	user := newUser(t, s)
+
+	first := newSession(t, s, user.Uuid, now().Add(-time.Minute))
+	second := newSession(t, s, user.Uuid, now())
+	// Expired sessions are gone for everyone
+	expired := newSession(t, s, user.Uuid, now().Add(-2*time.Hour))
+
+	session, err := s.GetSession(ctx, first.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, first.RefreshJti, session.RefreshJti)
+	assert.Equal(t, first.DeviceName, session.DeviceName)
+	assert.WithinDuration(t, first.Expires, session.Expires, timeDelta)
+
+	_, err = s.GetSession(ctx, expired.Uuid)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	sessions, err := s.ListSessions(ctx, user.Uuid)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, first.Uuid, sessions[0].Uuid)
+	assert.Equal(t, second.Uuid, sessions[1].Uuid)
+
+	// Only the current refresh token can be rotated
+	rotated := first
+	rotated.RefreshJti = uuid.New()
+	rotated.LastUsed = now()
+	assert.ErrorIs(t, s.RotateSession(ctx, rotated, uuid.New()), storage.ErrSessionNotFound)
+	require.NoError(t, s.RotateSession(ctx, rotated, first.RefreshJti))
+	assert.ErrorIs(t, s.RotateSession(ctx, rotated, first.RefreshJti), storage.ErrSessionNotFound)
+
+	session, err = s.GetSession(ctx, first.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.RefreshJti, session.RefreshJti)
+
+	// Sessions of other users can't be deleted
+	assert.ErrorIs(t, s.DeleteSession(ctx, uuid.New(), first.Uuid), storage.ErrSessionNotFound)
+	require.NoError(t, s.DeleteSession(ctx, user.Uuid, first.Uuid))
+	assert.ErrorIs(t, s.DeleteSession(ctx, user.Uuid, first.Uuid), storage.ErrSessionNotFound)
+
+	require.NoError(t, s.DeleteUserSessions(ctx, user.Uuid))
+	sessions, err = s.ListSessions(ctx, user.Uuid)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func testTokens(t *testing.T, s Storage) {
+	ctx := context.Background()
+	user := newUser(t, s)
+	session := newSession(t, s, user.Uuid, now())
+	jti := uuid.New()
+
+	revoked, err := s.IsTokenRevoked(ctx, user.Uuid, session.Uuid, jti)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Tokens of someone else's or unknown sessions are never valid
+	revoked, err = s.IsTokenRevoked(ctx, uuid.New(), session.Uuid, jti)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = s.IsTokenRevoked(ctx, user.Uuid, uuid.New(), jti)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	require.NoError(t, s.RevokeToken(ctx, jti, now().Add(time.Hour)))
+	revoked, err = s.IsTokenRevoked(ctx, user.Uuid, session.Uuid, jti)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = s.IsTokenRevoked(ctx, user.Uuid, session.Uuid, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func testChats(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := newUser(t, s)
+
+	deadline := now().Add(time.Hour)
+	created, err := s.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *owner, Readonly: true, Deadline: deadline})
+	require.NoError(t, err)
+
+	chat, err := s.GetChat(ctx, created.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, created.Uuid, chat.Uuid)
+	assert.Equal(t, *owner, chat.Owner)
+	assert.True(t, chat.Readonly)
+	// Storages with ttls only know the deadline roughly
+	assert.WithinDuration(t, deadline, chat.Deadline, time.Second)
+
+	// The owner is the first member of the chat
+	member, err := s.GetMember(ctx, created.Uuid, owner.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleOwner, member.Role)
+
+	_, err = s.GetChat(ctx, uuid.New())
+	assert.ErrorIs(t, err, storage.ErrChatNotFound)
+
+	expired, err := s.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *owner, Deadline: now().Add(-time.Second)})
+	require.NoError(t, err)
+	_, err = s.GetChat(ctx, expired.Uuid)
+	assert.ErrorIs(t, err, storage.ErrChatNotFound)
+}
+
+func testExpiredChats(t *testing.T, s Storage) {
+	expiredChats, ok := s.(chat.ExpiredChatsStorage)
+	if !ok {
+		t.Skip("storage expires chats by itself")
+	}
+	ctx := context.Background()
+	owner := newUser(t, s)
+
+	alive := newChat(t, s, owner)
+	expired, err := s.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *owner, Deadline: now().Add(-time.Second)})
+	require.NoError(t, err)
+
+	deleted, err := expiredChats.DeleteExpiredChats(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Contains(t, deleted, expired.Uuid)
+	assert.NotContains(t, deleted, alive.Uuid)
+
+	_, err = s.GetChat(ctx, alive.Uuid)
+	assert.NoError(t, err)
+
+	// Deleted chats are not returned again
+	deleted, err = expiredChats.DeleteExpiredChats(ctx, time.Now(), 100)
+	require.NoError(t, err)
+	assert.NotContains(t, deleted, expired.Uuid)
+
+	// Messages of deleted chats don't count against the quota of their authors
+	author := newUser(t, s)
+	shortLived, err := s.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *author, Deadline: now().Add(100 * time.Millisecond)})
+	require.NoError(t, err)
+	postMessages(t, s, shortLived.Uuid, author.Uuid, "hello")
+	time.Sleep(200 * time.Millisecond)
+	for {
+		deleted, err = expiredChats.DeleteExpiredChats(ctx, time.Now(), 100)
+		require.NoError(t, err)
+		if len(deleted) == 0 {
+			break
+		}
+	}
+	usage, err := s.QuotaUsage(ctx, author.Uuid, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, usage.ChatsOwned)
+	assert.Zero(t, usage.MessageBytes)
+}
+
+func testMessages(t *testing.T, s Storage) {
+	ctx := context.Background()
+	author := newUser(t, s)
+	chat := newChat(t, s, author)
+
+	published := now()
+	posted, err := s.PostMessage(ctx, chat.Uuid, domain.Message{AuthorUuid: author.Uuid, Body: "hello", Published: published})
+	require.NoError(t, err)
+	assert.Positive(t, posted.Id)
+
+	message, err := s.GetMessage(ctx, chat.Uuid, posted.Id)
+	require.NoError(t, err)
+	assert.Equal(t, posted.Id, message.Id)
+	assert.Equal(t, author.Uuid, message.AuthorUuid)
+	assert.Equal(t, "hello", message.Body)
+	assert.WithinDuration(t, published, message.Published, timeDelta)
+	assert.False(t, message.Deleted())
+	assert.Empty(t, message.Edits)
+
+	// Ids grow within a chat, clients resume subscriptions by them
+	next := postMessages(t, s, chat.Uuid, author.Uuid, "one", "two")
+	assert.Greater(t, next[0].Id, posted.Id)
+	assert.Greater(t, next[1].Id, next[0].Id)
+
+	_, err = s.GetMessage(ctx, chat.Uuid, next[1].Id+1)
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+	// Messages belong to their chat only
+	_, err = s.GetMessage(ctx, newChat(t, s, author).Uuid, posted.Id)
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+}
+
+func testHistory(t *testing.T, s Storage) {
+	ctx := context.Background()
+	author := newUser(t, s)
+	chat := newChat(t, s, author)
+	other := newChat(t, s, author)
+
+	posted := postMessages(t, s, chat.Uuid, author.Uuid, "1", "2", "3", "4", "5")
+	postMessages(t, s, other.Uuid, author.Uuid, "other")
+
+	tests := []struct {
+		name  string
+		query domain.HistoryQuery
+		want  []string
+	}{
+		{"all", domain.HistoryQuery{Limit: 10}, []string{"1", "2", "3", "4", "5"}},
+		{"newest", domain.HistoryQuery{Limit: 2}, []string{"4", "5"}},
+		{"before", domain.HistoryQuery{Limit: 2, BeforeId: posted[3].Id}, []string{"2", "3"}},
+		{"before first", domain.HistoryQuery{Limit: 2, BeforeId: posted[0].Id}, []string{}},
+		{"after", domain.HistoryQuery{Limit: 2, AfterId: posted[1].Id}, []string{"3", "4"}},
+		{"after last", domain.HistoryQuery{Limit: 2, AfterId: posted[4].Id}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// History is always returned oldest first
+			page, err := s.GetChatHistoryPage(ctx, chat.Uuid, tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, bodies(page))
+		})
+	}
+
+	page, err := s.GetChatHistoryPage(ctx, uuid.New(), domain.HistoryQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func testTrim(t *testing.T, s Storage) {
+	ctx := context.Background()
+	author := newUser(t, s)
+	chat := newChat(t, s, author)
+	other := newChat(t, s, author)
+
+	postMessages(t, s, chat.Uuid, author.Uuid, "1", "2", "3", "4")
+	postMessages(t, s, other.Uuid, author.Uuid, "a", "b", "c")
+
+	trimmed, err := s.TrimMessages(ctx, chat.Uuid, 2)
+	require.NoError(t, err)
+	assert.True(t, trimmed)
+
+	// The oldest messages of the given chat are dropped, other chats keep theirs
+	page, err := s.GetChatHistoryPage(ctx, chat.Uuid, domain.HistoryQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, bodies(page))
+	page, err = s.GetChatHistoryPage(ctx, other.Uuid, domain.HistoryQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, bodies(page))
+
+	trimmed, err = s.TrimMessages(ctx, chat.Uuid, 2)
+	require.NoError(t, err)
+	assert.False(t, trimmed)
+}
+
+func testEditDelete(t *testing.T, s Storage) {
+	ctx := context.Background()
+	author := newUser(t, s)
+	chat := newChat(t, s, author)
+	posted := postMessages(t, s, chat.Uuid, author.Uuid, "first")[0]
+
+	edited := now()
+	message, err := s.EditMessage(ctx, chat.Uuid, author.Uuid, posted.Id, "second", edited)
+	require.NoError(t, err)
+	assert.Equal(t, "second", message.Body)
+	require.Len(t, message.Edits, 1)
+	assert.Equal(t, "first", message.Edits[0].Body)
+	assert.WithinDuration(t, edited, message.Edits[0].Edited, timeDelta)
+
+	_, err = s.EditMessage(ctx, chat.Uuid, author.Uuid, posted.Id, "third", now())
+	require.NoError(t, err)
+	message, err = s.GetMessage(ctx, chat.Uuid, posted.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "third", message.Body)
+	assert.Equal(t, []string{"first", "second"}, []string{message.Edits[0].Body, message.Edits[1].Body})
+
+	deleted := now()
+	message, err = s.DeleteMessage(ctx, chat.Uuid, author.Uuid, posted.Id, deleted)
+	require.NoError(t, err)
+	assert.True(t, message.Deleted())
+
+	// A deleted message stays in history as a tombstone
+	message, err = s.GetMessage(ctx, chat.Uuid, posted.Id)
+	require.NoError(t, err)
+	assert.Empty(t, message.Body)
+	assert.Empty(t, message.Edits)
+	assert.WithinDuration(t, deleted, message.DeletedAt, timeDelta)
+
+	_, err = s.EditMessage(ctx, chat.Uuid, author.Uuid, posted.Id, "again", now())
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+	_, err = s.DeleteMessage(ctx, chat.Uuid, author.Uuid, posted.Id, now())
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+	_, err = s.EditMessage(ctx, chat.Uuid, author.Uuid, posted.Id+1, "missing", now())
+	assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+}
+
+func testConcurrentEditDelete(t *testing.T, s Storage) {
+	ctx := context.Background()
+	author := newUser(t, s)
+	chat := newChat(t, s, author)
+	posted := postMessages(t, s, chat.Uuid, author.Uuid, "first", "second")
+
+	// Concurrent edits don't lose each other
+	const edits = 8
+	var wg sync.WaitGroup
+	for i := 0; i < edits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.EditMessage(ctx, chat.Uuid, author.Uuid, posted[0].Id, strings.Repeat("x", i+1), now())
+			assert.NoError(t, err)
+		}(i)
+	}
+	// An edit racing with the delete never brings the message back
+	for i := 0; i < edits; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.EditMessage(ctx, chat.Uuid, author.Uuid, posted[1].Id, "edited", now())
+			if err != nil {
+				assert.ErrorIs(t, err, storage.ErrMessageNotFound)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := s.DeleteMessage(ctx, chat.Uuid, author.Uuid, posted[1].Id, now())
+		assert.NoError(t, err)
+	}()
+	wg.Wait()
+
+	edited, err := s.GetMessage(ctx, chat.Uuid, posted[0].Id)
+	require.NoError(t, err)
+	require.Len(t, edited.Edits, edits)
+	assert.Equal(t, "first", edited.Edits[0].Body)
+
+	deleted, err := s.GetMessage(ctx, chat.Uuid, posted[1].Id)
+	require.NoError(t, err)
+	assert.True(t, deleted.Deleted())
+	assert.Empty(t, deleted.Body)
+	assert.Empty(t, deleted.Edits)
+
+	usage, err := s.QuotaUsage(ctx, author.Uuid, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(edited.Body)), usage.MessageBytes)
+}
+
+func testMembers(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := newUser(t, s)
+	user := newUser(t, s)
+	chat := newChat(t, s, owner)
+
+	joined := now().Add(time.Second)
+	member, err := s.AddMember(ctx, domain.Member{ChatUuid: chat.Uuid, UserUuid: user.Uuid, Role: domain.RoleWriter, InvitedBy: owner.Uuid, Joined: joined})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleWriter, member.Role)
+
+	// Changing the role keeps the member where they were
+	member, err = s.AddMember(ctx, domain.Member{ChatUuid: chat.Uuid, UserUuid: user.Uuid, Role: domain.RoleAdmin, InvitedBy: owner.Uuid, Joined: joined.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.WithinDuration(t, joined, member.Joined, timeDelta)
+
+	member, err = s.GetMember(ctx, chat.Uuid, user.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, member.Role)
+	assert.Equal(t, owner.Uuid, member.InvitedBy)
+	assert.WithinDuration(t, joined, member.Joined, timeDelta)
+
+	members, err := s.ListMembers(ctx, chat.Uuid)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, owner.Uuid, members[0].UserUuid)
+	assert.Equal(t, user.Uuid, members[1].UserUuid)
+
+	_, err = s.AddMember(ctx, domain.Member{ChatUuid: chat.Uuid, UserUuid: uuid.New(), Role: domain.RoleReader, Joined: now()})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	require.NoError(t, s.RemoveMember(ctx, chat.Uuid, user.Uuid))
+	assert.ErrorIs(t, s.RemoveMember(ctx, chat.Uuid, user.Uuid), storage.ErrMemberNotFound)
+	_, err = s.GetMember(ctx, chat.Uuid, user.Uuid)
+	assert.ErrorIs(t, err, storage.ErrMemberNotFound)
+}
+
+func testQuotas(t *testing.T, s Storage) {
+	ctx := context.Background()
+	user := newUser(t, s)
+
+	_, err := s.GetUserQuota(ctx, user.Uuid)
+	assert.ErrorIs(t, err, storage.ErrQuotaNotFound)
+
+	want := domain.Quota{ChatsOwned: 3, MessagesPerMinute: -1, MessageBytes: 100}
+	require.NoError(t, s.SetUserQuota(ctx, user.Uuid, want))
+	quota, err := s.GetUserQuota(ctx, user.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, want, *quota)
+
+	assert.ErrorIs(t, s.SetUserQuota(ctx, uuid.New(), want), storage.ErrUserNotFound)
+
+	chat := newChat(t, s, user)
+	_, err = s.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *user, Deadline: now().Add(-time.Second)})
+	require.NoError(t, err)
+	postMessages(t, s, chat.Uuid, user.Uuid, "hello", "world!")
+
+	// Expired chats don't count
+	usage, err := s.QuotaUsage(ctx, user.Uuid, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotaUsage{ChatsOwned: 1, MessagesLastMinute: 2, MessageBytes: 11}, *usage)
+
+	usage, err = s.QuotaUsage(ctx, user.Uuid, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, usage.MessagesLastMinute)
+}
+
+// Messages are counted against the rate limit when they are posted, trimming or deleting them doesn't free it
+func testQuotaPostingLog(t *testing.T, s Storage) {
+	ctx := context.Background()
+	user := newUser(t, s)
+	chat := newChat(t, s, user)
+	messages := postMessages(t, s, chat.Uuid, user.Uuid, "one", "two", "three")
+
+	trimmed, err := s.TrimMessages(ctx, chat.Uuid, 1)
+	require.NoError(t, err)
+	require.True(t, trimmed)
+	_, err = s.DeleteMessage(ctx, chat.Uuid, user.Uuid, messages[2].Id, now())
+	require.NoError(t, err)
+
+	usage, err := s.QuotaUsage(ctx, user.Uuid, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 3, usage.MessagesLastMinute)
+	assert.Zero(t, usage.MessageBytes)
+}
+
+// drainOutbox confirms everything left in the outbox by previous tests
+func drainOutbox(t *testing.T, s Storage) {
+	ctx := context.Background()
+	for {
+		batch, err := s.ClaimOutboxBatch(ctx, 100, time.Minute)
+		require.NoError(t, err)
+		if len(batch) == 0 {
+			return
+		}
+		for _, v := range batch {
+			require.NoError(t, s.ConfirmOutboxSended(ctx, v.Uuid))
+		}
+	}
+}
+
+func outboxUuids(batch []*domain.Outbox) []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(batch))
+	for _, v := range batch {
+		res = append(res, v.Uuid)
+	}
+	return res
+}
+
+func testOutbox(t *testing.T, s Storage) {
+	ctx := context.Background()
+	drainOutbox(t, s)
+
+	batch, err := s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+
+	author := newUser(t, s)
+	chat := newChat(t, s, author)
+	posted := postMessages(t, s, chat.Uuid, author.Uuid, "hello")[0]
+	_, err = s.EditMessage(ctx, chat.Uuid, author.Uuid, posted.Id, "edited", now())
+	require.NoError(t, err)
+
+	// Outboxes are claimed in the order they were created and only once,
+	// the rest of a chat waits while some of its outboxes are claimed
+	first, err := s.ClaimOutboxBatch(ctx, 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, domain.ChatTopic, first[0].Topic)
+	assert.Equal(t, domain.MessageTopic, first[1].Topic)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+
+	// Released outboxes are claimed again before the newer ones
+	require.NoError(t, s.ReleaseOutbox(ctx, outboxUuids(first)))
+	all, err := s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, outboxUuids(first), outboxUuids(all[:2]))
+	assert.Equal(t, domain.MessageTopic, all[2].Topic)
+	// Events of a chat are numbered in the order they happened
+	for i, v := range all {
+		assert.Equal(t, chat.Uuid, v.ChatUuid)
+		assert.Equal(t, int64(i+1), v.Seq)
+	}
+
+	// Events are versioned envelopes identified by their outbox uuid
+	wantTypes := []outboxpb.EventType{outboxpb.EventType_CHAT_CREATED, outboxpb.EventType_MESSAGE_POSTED, outboxpb.EventType_MESSAGE_EDITED}
+	for i, v := range all {
+		assert.Equal(t, storage.EventSchemaVersion, v.SchemaVersion)
+		var event outboxpb.Event
+		require.NoError(t, proto.Unmarshal(v.Message, &event))
+		assert.Equal(t, v.Uuid.String(), event.Id)
+		assert.Equal(t, wantTypes[i], event.Type)
+		assert.Equal(t, chat.Uuid.String(), event.ChatUuid)
+		assert.Equal(t, author.Uuid.String(), event.ActorUuid)
+		assert.NotNil(t, event.OccurredAt)
+	}
+	var edited outboxpb.Event
+	require.NoError(t, proto.Unmarshal(all[2].Message, &edited))
+	assert.Equal(t, "edited", edited.GetMessageEdited().GetMessage().GetBody())
+
+	// Confirmed outboxes are gone
+	require.NoError(t, s.ConfirmOutboxSended(ctx, all[0].Uuid))
+	require.NoError(t, s.ReleaseOutbox(ctx, outboxUuids(all[1:])))
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, outboxUuids(all[1:]), outboxUuids(batch))
+	for _, v := range batch {
+		assert.NotEmpty(t, v.Message)
+		require.NoError(t, s.ConfirmOutboxSended(ctx, v.Uuid))
+	}
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+
+	// Other chats are claimed while one waits
+	busy := newChat(t, s, author)
+	postMessages(t, s, busy.Uuid, author.Uuid, "hello")
+	claimedChat, err := s.ClaimOutboxBatch(ctx, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimedChat, 1)
+	assert.Equal(t, busy.Uuid, claimedChat[0].ChatUuid)
+	other := newChat(t, s, author)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, other.Uuid, batch[0].ChatUuid)
+	require.NoError(t, s.ConfirmOutboxSended(ctx, batch[0].Uuid))
+	require.NoError(t, s.ConfirmOutboxSended(ctx, claimedChat[0].Uuid))
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, busy.Uuid, batch[0].ChatUuid)
+	assert.Equal(t, int64(2), batch[0].Seq)
+	require.NoError(t, s.ConfirmOutboxSended(ctx, batch[0].Uuid))
+
+	// Outboxes of a publisher which has gone are claimed again after the lease
+	newChat(t, s, author)
+	lost, err := s.ClaimOutboxBatch(ctx, 10, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, lost, 1)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+	time.Sleep(100 * time.Millisecond)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, outboxUuids(lost), outboxUuids(batch))
+
+	require.NoError(t, s.ConfirmOutboxSended(ctx, lost[0].Uuid))
+
+	// Concurrent publishers never claim the same outbox
+	const outboxes = 10
+	for i := 0; i < outboxes; i++ {
+		newChat(t, s, author)
+	}
+	claimed := make(chan uuid.UUID, outboxes)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				batch, err := s.ClaimOutboxBatch(ctx, 1, time.Minute)
+				if !assert.NoError(t, err) || len(batch) == 0 {
+					return
+				}
+				claimed <- batch[0].Uuid
+			}
+		}()
+	}
+	wg.Wait()
+	close(claimed)
+	seen := make(map[uuid.UUID]bool)
+	for v := range claimed {
+		assert.False(t, seen[v], "outbox is claimed twice")
+		seen[v] = true
+		require.NoError(t, s.ConfirmOutboxSended(ctx, v))
+	}
+	assert.Len(t, seen, outboxes)
+
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+}
+
+func testOutboxBacklog(t *testing.T, s Storage) {
+	ctx := context.Background()
+	drainOutbox(t, s)
+
+	backlog, err := s.OutboxBacklog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.OutboxBacklog{}, backlog)
+
+	author := newUser(t, s)
+	newChat(t, s, author)
+	time.Sleep(50 * time.Millisecond)
+	newChat(t, s, author)
+
+	// Claimed outboxes are still waiting
+	claimed, err := s.ClaimOutboxBatch(ctx, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	backlog, err = s.OutboxBacklog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, backlog.Unsent)
+	assert.GreaterOrEqual(t, backlog.OldestAge, 50*time.Millisecond)
+	assert.Less(t, backlog.OldestAge, time.Minute)
+	oldestAge := backlog.OldestAge
+
+	// The next one is the oldest after the first is sent
+	require.NoError(t, s.ConfirmOutboxSended(ctx, claimed[0].Uuid))
+	backlog, err = s.OutboxBacklog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, backlog.Unsent)
+	assert.Less(t, backlog.OldestAge, oldestAge)
+
+	drainOutbox(t, s)
+	backlog, err = s.OutboxBacklog(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, backlog.Unsent)
+}
+
+func testOutboxRetention(t *testing.T, s Storage) {
+	sentOutbox, ok := s.(outbox.SentOutboxStorage)
+	if !ok {
+		t.Skip("storage deletes outboxes once they are sent")
+	}
+	ctx := context.Background()
+	drainOutbox(t, s)
+
+	author := newUser(t, s)
+	newChat(t, s, author)
+	drainOutbox(t, s)
+	unsent := newChat(t, s, author)
+	time.Sleep(50 * time.Millisecond)
+
+	// Old sent outboxes are deleted batch by batch, unsent ones stay
+	deleted, err := sentOutbox.DeleteSentOutbox(ctx, 10*time.Millisecond, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	for deleted > 0 {
+		deleted, err = sentOutbox.DeleteSentOutbox(ctx, 10*time.Millisecond, 100)
+		require.NoError(t, err)
+	}
+	batch, err := s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, unsent.Uuid, batch[0].ChatUuid)
+	require.NoError(t, s.ConfirmOutboxSended(ctx, batch[0].Uuid))
+
+	// Recently sent outboxes are kept
+	deleted, err = sentOutbox.DeleteSentOutbox(ctx, time.Hour, 100)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+func testDeadLetters(t *testing.T, s Storage) {
+	ctx := context.Background()
+	drainOutbox(t, s)
+	_, err := s.PurgeDeadLetters(ctx)
+	require.NoError(t, err)
+
+	_, err = s.FailOutbox(ctx, uuid.New(), "kafka is down", 2)
+	assert.ErrorIs(t, err, storage.ErrOutboxNotFound)
+
+	owner := newUser(t, s)
+	newChat(t, s, owner)
+	batch, err := s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	failed := batch[0]
+
+	// Attempts are counted until they are over
+	deadLettered, err := s.FailOutbox(ctx, failed.Uuid, "first error", 2)
+	require.NoError(t, err)
+	assert.False(t, deadLettered)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, 1, batch[0].Attempts)
+	assert.Equal(t, "first error", batch[0].LastError)
+
+	deadLettered, err = s.FailOutbox(ctx, failed.Uuid, "second error", 2)
+	require.NoError(t, err)
+	assert.True(t, deadLettered)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+
+	deadLetter, err := s.GetDeadLetter(ctx, failed.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, failed.Topic, deadLetter.Topic)
+	assert.Equal(t, failed.Message, deadLetter.Message)
+	assert.Equal(t, failed.ChatUuid, deadLetter.ChatUuid)
+	assert.Equal(t, failed.Seq, deadLetter.Seq)
+	assert.Equal(t, 2, deadLetter.Attempts)
+	assert.Equal(t, "second error", deadLetter.LastError)
+	assert.WithinDuration(t, time.Now(), deadLetter.Failed, time.Minute)
+
+	page, err := s.ListDeadLetters(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, failed.Uuid, page[0].Uuid)
+	page, err = s.ListDeadLetters(ctx, 10, 1)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	// A replayed dead letter is published again with fresh attempts
+	require.NoError(t, s.ReplayDeadLetter(ctx, failed.Uuid))
+	assert.ErrorIs(t, s.ReplayDeadLetter(ctx, failed.Uuid), storage.ErrDeadLetterNotFound)
+	_, err = s.GetDeadLetter(ctx, failed.Uuid)
+	assert.ErrorIs(t, err, storage.ErrDeadLetterNotFound)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, failed.Uuid, batch[0].Uuid)
+	assert.Equal(t, failed.Message, batch[0].Message)
+	assert.Equal(t, failed.Seq, batch[0].Seq)
+	assert.Zero(t, batch[0].Attempts)
+
+	deadLettered, err = s.FailOutbox(ctx, failed.Uuid, "kafka is down", 1)
+	require.NoError(t, err)
+	assert.True(t, deadLettered)
+	require.NoError(t, s.DeleteDeadLetter(ctx, failed.Uuid))
+	assert.ErrorIs(t, s.DeleteDeadLetter(ctx, failed.Uuid), storage.ErrDeadLetterNotFound)
+
+	// Dead letters are listed in the order they failed
+	newChat(t, s, owner)
+	newChat(t, s, owner)
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+	for _, v := range batch {
+		_, err = s.FailOutbox(ctx, v.Uuid, "kafka is down", 1)
+		require.NoError(t, err)
+		time.Sleep(timeDelta)
+	}
+	page, err = s.ListDeadLetters(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, batch[0].Uuid, page[0].Uuid)
+	page, err = s.ListDeadLetters(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, batch[1].Uuid, page[0].Uuid)
+
+	purged, err := s.PurgeDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	page, err = s.ListDeadLetters(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func testOutboxTraceContext(t *testing.T, s Storage) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	drainOutbox(t, s)
+	_, err := s.PurgeDeadLetters(context.Background())
+	require.NoError(t, err)
+
+	traceId := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	// The outbox keeps the trace of the change which added it
+	owner := newUser(t, s)
+	_, err = s.CreateChat(ctx, domain.Chat{Uuid: uuid.New(), Owner: *owner, Deadline: now().Add(time.Hour)})
+	require.NoError(t, err)
+	newChat(t, s, owner)
+	batch, err := s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+	assert.Contains(t, batch[0].TraceContext["traceparent"], traceId.String())
+	assert.Empty(t, batch[1].TraceContext)
+	require.NoError(t, s.ConfirmOutboxSended(ctx, batch[1].Uuid))
+
+	// and it's kept by dead letters until they are replayed
+	traced := batch[0]
+	deadLettered, err := s.FailOutbox(ctx, traced.Uuid, "kafka is down", 1)
+	require.NoError(t, err)
+	require.True(t, deadLettered)
+	deadLetter, err := s.GetDeadLetter(ctx, traced.Uuid)
+	require.NoError(t, err)
+	assert.Equal(t, traced.TraceContext, deadLetter.TraceContext)
+	require.NoError(t, s.ReplayDeadLetter(ctx, traced.Uuid))
+	batch, err = s.ClaimOutboxBatch(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, traced.TraceContext, batch[0].TraceContext)
+	require.NoError(t, s.ConfirmOutboxSended(ctx, batch[0].Uuid))
+}
@@ -0,0 +1 @@
+DROP TABLE chat_members;
\ No newline at end of file
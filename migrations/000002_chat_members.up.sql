@@ -0,0 +1,12 @@
+CREATE TABLE chat_members
+(
+    chat_uuid UUID NOT NULL REFERENCES chats (uuid) ON DELETE CASCADE,
+    user_uuid UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL,
+    invited_by UUID,
+    joined TIMESTAMP NOT NULL,
+    PRIMARY KEY (chat_uuid, user_uuid)
+);
+
+INSERT INTO chat_members (chat_uuid, user_uuid, role, joined)
+SELECT uuid, owner, 'owner', current_timestamp FROM chats;
@@ -0,0 +1,3 @@
+DROP TABLE message_edits;
+
+ALTER TABLE messages DROP COLUMN deleted_at;
@@ -0,0 +1,11 @@
+ALTER TABLE messages ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE TABLE message_edits
+(
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    body VARCHAR(255) NOT NULL,
+    edited TIMESTAMP NOT NULL
+);
+
+CREATE INDEX message_edits_message_id_idx ON message_edits (message_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN tokens_revoked_before;
+
+DROP TABLE revoked_tokens;
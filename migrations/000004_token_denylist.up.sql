@@ -0,0 +1,7 @@
+CREATE TABLE revoked_tokens
+(
+    jti UUID PRIMARY KEY,
+    expired TIMESTAMP NOT NULL
+);
+
+ALTER TABLE users ADD COLUMN tokens_revoked_before TIMESTAMP;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN tokens_revoked_before TIMESTAMP;
+
+DROP TABLE sessions;
+
+CREATE TABLE refresh_tokens
+(
+    user_uuid UUID PRIMARY KEY REFERENCES users (uuid) ON DELETE CASCADE,
+    token VARCHAR(255) NOT NULL
+);
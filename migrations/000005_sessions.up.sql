@@ -0,0 +1,18 @@
+DROP TABLE refresh_tokens;
+
+CREATE TABLE sessions
+(
+    uuid UUID PRIMARY KEY,
+    user_uuid UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    refresh_jti UUID NOT NULL,
+    device_name VARCHAR(255) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL,
+    created TIMESTAMP NOT NULL,
+    last_used TIMESTAMP NOT NULL,
+    expires TIMESTAMP NOT NULL
+);
+
+CREATE INDEX sessions_user_uuid_idx ON sessions (user_uuid);
+
+-- Logging out of every device deletes all sessions of the user now
+ALTER TABLE users DROP COLUMN tokens_revoked_before;
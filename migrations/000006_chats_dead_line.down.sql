@@ -0,0 +1 @@
+DROP INDEX chats_dead_line_idx;
@@ -0,0 +1 @@
+CREATE INDEX chats_dead_line_idx ON chats (dead_line);
@@ -0,0 +1,4 @@
+DROP INDEX messages_author_uuid_published_idx;
+DROP INDEX chats_owner_idx;
+
+DROP TABLE user_quotas;
@@ -0,0 +1,10 @@
+CREATE TABLE user_quotas
+(
+    user_uuid UUID PRIMARY KEY REFERENCES users (uuid) ON DELETE CASCADE,
+    chats_owned INTEGER NOT NULL DEFAULT 0,
+    messages_per_minute INTEGER NOT NULL DEFAULT 0,
+    message_bytes BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX chats_owner_idx ON chats (owner);
+CREATE INDEX messages_author_uuid_published_idx ON messages (author_uuid, published);
@@ -0,0 +1 @@
+ALTER TABLE outbox ALTER COLUMN message TYPE VARCHAR(255) USING convert_from(message, 'UTF8');
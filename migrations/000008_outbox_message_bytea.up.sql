@@ -0,0 +1,2 @@
+-- Outbox messages are marshalled protobuf, they are neither text nor limited to 255 bytes
+ALTER TABLE outbox ALTER COLUMN message TYPE BYTEA USING convert_to(message, 'UTF8');
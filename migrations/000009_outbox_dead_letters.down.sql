@@ -0,0 +1,4 @@
+DROP TABLE outbox_dead_letters;
+
+ALTER TABLE outbox DROP COLUMN last_error;
+ALTER TABLE outbox DROP COLUMN attempts;
@@ -0,0 +1,14 @@
+ALTER TABLE outbox ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE outbox ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE outbox_dead_letters
+(
+    uuid UUID PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    message BYTEA NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    failed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX outbox_dead_letters_failed_at_idx ON outbox_dead_letters (failed_at);
@@ -0,0 +1,4 @@
+DROP INDEX outbox_unsent_created_at_idx;
+
+ALTER TABLE outbox DROP COLUMN claimed_until;
+ALTER TABLE outbox DROP COLUMN created_at;
@@ -0,0 +1,5 @@
+-- Publishers claim the oldest unsent outboxes for a lease, so replicas don't publish the same ones
+ALTER TABLE outbox ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT clock_timestamp();
+ALTER TABLE outbox ADD COLUMN claimed_until TIMESTAMP;
+
+CREATE INDEX outbox_unsent_created_at_idx ON outbox (created_at) WHERE sent_at IS NULL;
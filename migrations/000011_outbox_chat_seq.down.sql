@@ -0,0 +1,8 @@
+ALTER TABLE outbox_dead_letters DROP COLUMN seq;
+ALTER TABLE outbox_dead_letters DROP COLUMN chat_uuid;
+
+DROP INDEX outbox_unsent_chat_uuid_idx;
+ALTER TABLE outbox DROP COLUMN seq;
+ALTER TABLE outbox DROP COLUMN chat_uuid;
+
+DROP TABLE outbox_chat_seqs;
@@ -0,0 +1,13 @@
+-- Events of a chat are numbered, so they can be delivered and checked in order
+CREATE TABLE outbox_chat_seqs
+(
+    chat_uuid UUID PRIMARY KEY,
+    seq BIGINT NOT NULL
+);
+
+ALTER TABLE outbox ADD COLUMN chat_uuid UUID;
+ALTER TABLE outbox ADD COLUMN seq BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX outbox_unsent_chat_uuid_idx ON outbox (chat_uuid) WHERE sent_at IS NULL;
+
+ALTER TABLE outbox_dead_letters ADD COLUMN chat_uuid UUID;
+ALTER TABLE outbox_dead_letters ADD COLUMN seq BIGINT NOT NULL DEFAULT 0;
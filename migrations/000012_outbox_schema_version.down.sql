@@ -0,0 +1,2 @@
+ALTER TABLE outbox_dead_letters DROP COLUMN schema_version;
+ALTER TABLE outbox DROP COLUMN schema_version;
@@ -0,0 +1,3 @@
+-- Events written before the versioned envelope keep version 0
+ALTER TABLE outbox ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE outbox_dead_letters ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Sent outboxes are deleted by the retention after they get old
+CREATE INDEX outbox_sent_at_idx ON outbox (sent_at) WHERE sent_at IS NOT NULL;
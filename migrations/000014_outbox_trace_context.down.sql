@@ -0,0 +1,2 @@
+ALTER TABLE outbox_dead_letters DROP COLUMN trace_context;
+ALTER TABLE outbox DROP COLUMN trace_context;
@@ -0,0 +1,3 @@
+-- Outboxes added before tracing have no trace context
+ALTER TABLE outbox ADD COLUMN trace_context JSONB;
+ALTER TABLE outbox_dead_letters ADD COLUMN trace_context JSONB;
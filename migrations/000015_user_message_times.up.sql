@@ -0,0 +1,9 @@
+-- Publication times of the recent messages of users, they count for the messages per minute quota
+-- even after the messages are trimmed or deleted
+CREATE TABLE user_message_times
+(
+    user_uuid UUID NOT NULL REFERENCES users (uuid) ON DELETE CASCADE,
+    published TIMESTAMP NOT NULL
+);
+
+CREATE INDEX user_message_times_user_uuid_published_idx ON user_message_times (user_uuid, published);
@@ -0,0 +1,9 @@
+// Package migrations embeds the postgres schema migrations into the binary.
+package migrations
+
+import "embed"
+
+// FS holds NNNNNN_name.up.sql and NNNNNN_name.down.sql files of every schema version
+//
+//go:embed *.sql
+var FS embed.FS